@@ -9,13 +9,23 @@ import (
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 
+	"github.com/yourusername/car-reselling-backend/internal/account"
+	"github.com/yourusername/car-reselling-backend/internal/appointment"
+	"github.com/yourusername/car-reselling-backend/internal/audit"
 	"github.com/yourusername/car-reselling-backend/internal/auth"
 	"github.com/yourusername/car-reselling-backend/internal/chat"
 	"github.com/yourusername/car-reselling-backend/internal/config"
 	"github.com/yourusername/car-reselling-backend/internal/database"
+	"github.com/yourusername/car-reselling-backend/internal/dealer"
+	"github.com/yourusername/car-reselling-backend/internal/email"
 	"github.com/yourusername/car-reselling-backend/internal/listing"
 	"github.com/yourusername/car-reselling-backend/internal/models"
+	"github.com/yourusername/car-reselling-backend/internal/moderation"
 	"github.com/yourusername/car-reselling-backend/internal/notification"
+	"github.com/yourusername/car-reselling-backend/internal/offer"
+	"github.com/yourusername/car-reselling-backend/internal/payments"
+	"github.com/yourusername/car-reselling-backend/internal/savedsearch"
+	"github.com/yourusername/car-reselling-backend/internal/subscription"
 
 	_ "github.com/yourusername/car-reselling-backend/docs" // Swagger docs
 )
@@ -101,25 +111,16 @@ func main() {
 	config.AllowAllOrigins = true
 	config.AllowMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
 	config.AllowHeaders = []string{"Origin", "Content-Type", "Accept", "Authorization", "X-Requested-With", "Upgrade", "Connection"}
-	config.ExposeHeaders = []string{"Content-Length"}
+	config.ExposeHeaders = []string{"Content-Length", "RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "RateLimit-Policy", "Retry-After"}
 	config.AllowCredentials = false
 	r.Use(cors.New(config))
 
-	// Rate limiting (exclude Swagger UI, health check, and WebSocket)
-	r.Use(func(c *gin.Context) {
-		path := c.Request.URL.Path
-		// Skip rate limiting for Swagger UI, health check, and WebSocket
-		if path == "/health" ||
-			path == "/swagger" ||
-			path == "/swagger/" ||
-			(len(path) > 9 && path[:9] == "/swagger/") ||
-			path == "/api/chat/ws" { // WebSocket endpoint excluded
-			c.Next()
-			return
-		}
-		// Apply rate limiting for other routes
-		auth.RateLimitMiddleware()(c)
-	})
+	// Rate limiting per user (or IP) and route; Swagger UI, health check and
+	// WebSocket are exempt (see auth.DefaultRateLimitPolicies)
+	r.Use(auth.RateLimitMiddleware(cfg, auth.DefaultRateLimitPolicies()))
+
+	// Client IP and user agent for audit events
+	r.Use(audit.Middleware())
 
 	// Swagger UI
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
@@ -145,9 +146,34 @@ func main() {
 		})
 	})
 
+	// Initialize the security audit log
+	auditService := audit.NewService(audit.NewRepository(database.DB))
+	auditHandler := audit.NewHandler(auditService)
+
 	// Initialize auth components
 	authRepo := auth.NewRepository()
 	authService := auth.NewService(authRepo, cfg)
+	authService.SetAuditLogger(auditService)
+
+	otpSender, err := auth.NewOTPSender(cfg)
+	if err != nil {
+		log.Fatalf("Failed to initialize OTP provider: %v", err)
+	}
+	authService.SetOTPSender(otpSender)
+
+	// Initialize email service for account and notification emails
+	emailSender, err := email.NewSender(cfg)
+	if err != nil {
+		log.Fatalf("Failed to initialize email sender: %v", err)
+	}
+	emailService, err := email.NewService(emailSender, email.NewRepository(database.DB))
+	if err != nil {
+		log.Fatalf("Failed to load email templates: %v", err)
+	}
+	if cfg.SMTPHost == "" {
+		log.Println("⚠ SMTP not configured, emails will only be logged")
+	}
+	authService.SetMailer(emailService)
 	authHandler := auth.NewHandler(authService)
 
 	// Auth routes
@@ -155,9 +181,14 @@ func main() {
 	{
 		authGroup.POST("/register", authHandler.Register)
 		authGroup.POST("/login", authHandler.Login)
+		authGroup.POST("/login/2fa", authHandler.VerifyTwoFactorLogin)
 		authGroup.POST("/send-otp", authHandler.SendOTP)
 		authGroup.POST("/verify-otp", authHandler.VerifyOTP)
 		authGroup.POST("/refresh", authHandler.RefreshToken)
+		authGroup.POST("/forgot-password", authHandler.ForgotPassword)
+		authGroup.POST("/forgot-password/verify", authHandler.VerifyResetOTP)
+		authGroup.POST("/reset-password", authHandler.ResetPassword)
+		authGroup.GET("/verify-email", authHandler.VerifyEmail)
 
 		// Protected routes
 		protected := authGroup.Group("")
@@ -167,6 +198,14 @@ func main() {
 			protected.GET("/me", authHandler.GetCurrentUser)
 			protected.PUT("/me", authHandler.UpdateProfile)
 			protected.POST("/change-password", authHandler.ChangePassword)
+			protected.POST("/verify-email/resend", authHandler.ResendVerificationEmail)
+			protected.POST("/2fa/setup", authHandler.SetupTwoFactor)
+			protected.POST("/2fa/enable", authHandler.EnableTwoFactor)
+			protected.POST("/2fa/disable", authHandler.DisableTwoFactor)
+			protected.POST("/2fa/recovery-codes", authHandler.RegenerateRecoveryCodes)
+			protected.GET("/sessions", authHandler.ListSessions)
+			protected.DELETE("/sessions", authHandler.RevokeOtherSessions)
+			protected.DELETE("/sessions/:id", authHandler.RevokeSession)
 		}
 	}
 
@@ -175,16 +214,56 @@ func main() {
 
 	// Initialize R2 storage service
 	var storageService listing.StorageService
+	var documentStorage dealer.DocumentStorage
 	r2Storage, err := listing.NewStorageService(cfg)
 	if err != nil {
 		log.Printf("⚠ R2 Storage initialization failed: %v", err)
 		log.Println("  Image uploads will not work until R2 is configured correctly")
 		storageService = &listing.NullStorageService{}
+		documentStorage = &listing.NullStorageService{}
 	} else {
 		storageService = r2Storage
+		documentStorage = r2Storage
+		if cfg.R2PrivateBucketName == "" {
+			log.Println("⚠ R2_PRIVATE_BUCKET_NAME is not set; dealer verification documents cannot be uploaded")
+		}
+	}
+
+	// Initialize payments; disabled until a provider is configured
+	paymentProvider, err := payments.NewProvider(cfg)
+	if err != nil {
+		log.Fatalf("Failed to initialize payment provider: %v", err)
 	}
+	if paymentProvider == nil {
+		log.Println("⚠ STRIPE_SECRET_KEY not set, payments are disabled")
+	}
+	paymentService := payments.NewService(payments.NewRepository(database.DB), paymentProvider)
+	paymentService.SetAuditLogger(auditService)
+	paymentHandler := payments.NewHandler(paymentService)
 
 	listingService := listing.NewService(listingRepo, storageService, database.RedisClient)
+	listingService.SetAuditLogger(auditService)
+
+	// Seller plans decide how much each user may post
+	subscriptionService := subscription.NewService(subscription.NewRepository(database.DB))
+	subscriptionService.SetAuditLogger(auditService)
+	subscriptionHandler := subscription.NewHandler(subscriptionService)
+	listingService.SetQuotaProvider(subscriptionService)
+
+	// Boosts and plans are paid through checkout; the webhook starts what was bought
+	if paymentProvider != nil {
+		listingService.SetPaymentService(paymentService, listing.BoostPricing{
+			DailyPrice: cfg.BoostDailyPrice,
+			Currency:   cfg.PaymentCurrency,
+		})
+		subscriptionService.SetPaymentService(paymentService, cfg.PaymentCurrency)
+	}
+	paymentService.RegisterFulfiller(payments.PurposeBoost, listingService)
+	paymentService.RegisterFulfiller(payments.PurposePlan, subscriptionService)
+
+	// Start retrying undelivered purchases in background
+	go paymentService.RunFulfillmentWorker()
+
 	listingHandler := listing.NewHandler(listingService)
 
 	// Listing routes
@@ -200,7 +279,9 @@ func main() {
 
 		// Public listing routes (with optional auth to detect logged-in user for isOwner/isFavorited)
 		cars.GET("", auth.OptionalAuthMiddleware(cfg), listingHandler.ListListings)
+		cars.GET("/map", listingHandler.GetMapClusters)
 		cars.GET("/:id", auth.OptionalAuthMiddleware(cfg), listingHandler.GetListing)
+		cars.GET("/:id/price-history", listingHandler.GetPriceHistory)
 		cars.POST("/:id/view", listingHandler.IncrementView)
 
 		// Protected listing routes
@@ -210,6 +291,9 @@ func main() {
 			protected.POST("", listingHandler.CreateListing)
 			protected.PUT("/:id", listingHandler.UpdateListing)
 			protected.DELETE("/:id", listingHandler.DeleteListing)
+			protected.POST("/:id/renew", listingHandler.RenewListing)
+			protected.POST("/:id/boost", listingHandler.BoostListing)
+			protected.GET("/:id/boosts", listingHandler.GetBoosts)
 
 			// Custom endpoints (careful with path conflicts, but these are distinct enough)
 			// :id matches UUIDs usually, so "favorites" and "my-listings" might conflict if :id is catch-all.
@@ -259,13 +343,75 @@ func main() {
 	// Initialize chat components
 	chatRepo := chat.NewRepository(database.DB)
 	chatService := chat.NewService(chatRepo, notificationService)
+	chatService.SetMailer(emailService)
+	chatService.SetAuditLogger(auditService)
 	chatHub := chat.NewHub(chatService)
 
+	// Share presence and fan out messages across API replicas via Redis
+	chatHub.EnableCluster(database.RedisClient, cfg.NodeID)
+
 	// Now set the WebSocket sender (chatHub) on notification service
 	notificationService.SetWebSocketSender(chatHub)
 
 	// Wire notification service to listing service for price change notifications
 	listingService.SetNotificationService(notificationService)
+	authService.SetNotificationService(notificationService)
+	listingService.SetMailer(emailService)
+
+	// Initialize saved search components and alert on new matching listings
+	savedSearchRepo := savedsearch.NewRepository(database.DB)
+	savedSearchService := savedsearch.NewService(savedSearchRepo, notificationService)
+	savedSearchHandler := savedsearch.NewHandler(savedSearchService)
+	listingService.SetListingMatcher(savedSearchService)
+
+	// Start daily saved search digests in background
+	go savedSearchService.RunDigestWorker()
+
+	// Start listing expiry worker in background
+	go listingService.RunLifecycleWorker()
+
+	// Start featured listing boosts worker in background
+	go listingService.RunBoostWorker()
+
+	// Start unread chat message emails in background
+	go chatService.RunEmailDigestWorker()
+
+	// Initialize moderation components
+	moderationRepo := moderation.NewRepository(database.DB)
+	moderationService := moderation.NewService(moderationRepo, database.RedisClient, notificationService, authService)
+	moderationHandler := moderation.NewHandler(moderationService)
+
+	// Initialize account deletion and data export
+	accountRepo := account.NewRepository(database.DB)
+	accountService := account.NewService(accountRepo, storageService, documentStorage, database.RedisClient, authService)
+	accountHandler := account.NewHandler(accountService)
+
+	// Initialize dealer storefronts and verification
+	dealerRepo := dealer.NewRepository(database.DB)
+	dealerService := dealer.NewService(dealerRepo, storageService, documentStorage, database.RedisClient, notificationService)
+	dealerService.SetAuditLogger(auditService)
+	dealerHandler := dealer.NewHandler(dealerService)
+
+	// Initialize offers; negotiation happens in the buyer and seller's chat
+	offerService := offer.NewService(offer.NewRepository(database.DB), database.RedisClient, notificationService)
+	offerService.SetChatPoster(chatHub)
+	offerService.SetCurrency(cfg.PaymentCurrency)
+	offerService.SetAuditLogger(auditService)
+	offerHandler := offer.NewHandler(offerService)
+
+	// Close offers nobody answered in time
+	go offerService.RunExpiryWorker()
+
+	// Initialize viewing and test drive appointments; events are posted to the listing's chat
+	appointmentService := appointment.NewService(appointment.NewRepository(database.DB), notificationService)
+	appointmentService.SetChatPoster(chatHub)
+	appointmentHandler := appointment.NewHandler(appointmentService)
+
+	// Send appointment reminders and expire unanswered requests
+	go appointmentService.RunWorker()
+
+	// Purge accounts whose deletion grace period is over
+	go accountService.RunPurgeWorker()
 
 	// Create handlers
 	chatHandler := chat.NewHandler(chatHub, chatService)
@@ -280,6 +426,48 @@ func main() {
 	// Register notification routes
 	notificationHandler.RegisterRoutes(api, auth.AuthMiddleware(cfg))
 
+	// Register saved search routes
+	savedSearchHandler.RegisterRoutes(api, auth.AuthMiddleware(cfg))
+
+	// Register listing report routes
+	moderationHandler.RegisterRoutes(api, auth.AuthMiddleware(cfg))
+
+	// Register account deletion and data export routes
+	accountHandler.RegisterRoutes(api, auth.AuthMiddleware(cfg))
+
+	// Register dealer storefront routes
+	dealerHandler.RegisterRoutes(api, auth.AuthMiddleware(cfg))
+
+	// Register seller plan routes
+	subscriptionHandler.RegisterRoutes(api, auth.AuthMiddleware(cfg))
+
+	// Register offer routes
+	offerHandler.RegisterRoutes(api, auth.AuthMiddleware(cfg))
+
+	// Register availability and appointment routes
+	appointmentHandler.RegisterRoutes(api, auth.AuthMiddleware(cfg))
+
+	// Register payment webhook and history routes
+	paymentHandler.RegisterRoutes(api, auth.AuthMiddleware(cfg))
+
+	// Register the user's own security events
+	auditHandler.RegisterRoutes(api, auth.AuthMiddleware(cfg))
+
+	// Admin routes: user management, broadcast notifications, audit log, dealer verification and plans
+	admin := api.Group("/admin")
+	admin.Use(auth.AuthMiddleware(cfg), auth.RequireRole(models.RoleAdmin))
+	authHandler.RegisterAdminRoutes(admin)
+	notificationHandler.RegisterAdminRoutes(admin)
+	auditHandler.RegisterAdminRoutes(admin)
+	dealerHandler.RegisterAdminRoutes(admin)
+	subscriptionHandler.RegisterAdminRoutes(admin)
+	paymentHandler.RegisterAdminRoutes(admin)
+
+	// Staff routes: listing moderation (moderators and admins)
+	staff := api.Group("/admin")
+	staff.Use(auth.AuthMiddleware(cfg), auth.RequireRole(models.RoleModerator, models.RoleAdmin))
+	moderationHandler.RegisterAdminRoutes(staff)
+
 	// Start server
 	serverAddr := ":" + cfg.ServerPort
 	log.Printf("Server starting on %s", serverAddr)
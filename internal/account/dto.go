@@ -0,0 +1,16 @@
+package account
+
+import "time"
+
+// DeleteAccountRequest represents the request to delete the current account
+// @Description Password confirmation for account deletion
+type DeleteAccountRequest struct {
+	Password string `json:"password" binding:"required" example:"SecurePass123"`
+}
+
+// DeletionResponse describes a pending account deletion
+// @Description When the account will be permanently deleted
+type DeletionResponse struct {
+	DeletionScheduledAt time.Time `json:"deletion_scheduled_at" example:"2024-02-01T00:00:00Z"`
+	Message             string    `json:"message" example:"Your account will be deleted on 2024-02-01. Log in and cancel before then to keep it."`
+}
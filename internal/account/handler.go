@@ -0,0 +1,132 @@
+package account
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	appErrors "github.com/yourusername/car-reselling-backend/pkg/errors"
+)
+
+// Handler handles HTTP requests for account deletion and data export
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a new account handler
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// RegisterRoutes registers the account routes next to the other /auth/me routes
+func (h *Handler) RegisterRoutes(router *gin.RouterGroup, authMiddleware gin.HandlerFunc) {
+	me := router.Group("/auth/me")
+	me.Use(authMiddleware)
+	{
+		me.DELETE("", h.RequestDeletion)
+		me.POST("/cancel-deletion", h.CancelDeletion)
+		me.GET("/export", h.Export)
+	}
+}
+
+// RequestDeletion schedules the current account for deletion
+// @Summary Delete account
+// @Description Schedule the current account for deletion after a 30 day grace period and log out all devices. Log in again and cancel to keep the account. Afterwards, listings are removed with their images, chat messages are anonymised, and favorites, devices, notifications, saved searches and the dealer storefront are deleted.
+// @Tags auth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body DeleteAccountRequest true "Password confirmation"
+// @Success 202 {object} DeletionResponse
+// @Failure 400 {object} appErrors.ErrorResponse
+// @Failure 401 {object} appErrors.ErrorResponse
+// @Router /api/auth/me [delete]
+func (h *Handler) RequestDeletion(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	var req DeleteAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		appErrors.HandleErrorWithMessage(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp, err := h.service.RequestDeletion(c.Request.Context(), userID, req.Password)
+	if err != nil {
+		appErrors.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, resp)
+}
+
+// CancelDeletion cancels a pending account deletion
+// @Summary Cancel account deletion
+// @Description Cancel a pending account deletion during the grace period
+// @Tags auth
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} map[string]string
+// @Failure 401 {object} appErrors.ErrorResponse
+// @Failure 409 {object} appErrors.ErrorResponse
+// @Router /api/auth/me/cancel-deletion [post]
+func (h *Handler) CancelDeletion(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.CancelDeletion(c.Request.Context(), userID); err != nil {
+		if errors.Is(err, ErrNoDeletionPending) {
+			appErrors.HandleErrorWithMessage(c, http.StatusConflict, err.Error())
+			return
+		}
+		appErrors.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Account deletion cancelled"})
+}
+
+// Export downloads a copy of the current user's data
+// @Summary Export account data
+// @Description Download a ZIP archive with the user's profile, listings, favorites, conversations and notifications as JSON (max 3 per hour)
+// @Tags auth
+// @Security BearerAuth
+// @Produce application/zip
+// @Success 200 {file} file
+// @Failure 401 {object} appErrors.ErrorResponse
+// @Failure 429 {object} appErrors.ErrorResponse
+// @Router /api/auth/me/export [get]
+func (h *Handler) Export(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	data, err := h.service.Export(c.Request.Context(), userID)
+	if err != nil {
+		appErrors.HandleError(c, err)
+		return
+	}
+
+	filename := fmt.Sprintf("account-export-%s.zip", time.Now().Format("2006-01-02"))
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Header("Cache-Control", "no-store")
+	c.Data(http.StatusOK, "application/zip", data)
+}
+
+func getUserID(c *gin.Context) (uuid.UUID, bool) {
+	userID, err := uuid.Parse(c.GetString("userID"))
+	if err != nil {
+		appErrors.HandleError(c, appErrors.ErrUnauthorized)
+		return uuid.Nil, false
+	}
+	return userID, true
+}
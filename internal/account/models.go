@@ -0,0 +1,69 @@
+package account
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// DeletionGracePeriod is how long a deletion request can be cancelled before
+	// the account is purged
+	DeletionGracePeriod = 30 * 24 * time.Hour
+
+	// purgeInterval is how often due account deletions are processed
+	purgeInterval = time.Hour
+
+	// deletedMessageContent replaces the content of a deleted user's chat messages
+	deletedMessageContent = "This message was deleted"
+
+	// deletedUserName replaces the name of a deleted user
+	deletedUserName = "Deleted user"
+
+	// maxExportsPerHour caps data exports per user
+	maxExportsPerHour = 3
+)
+
+// ErrNoDeletionPending is returned when cancelling a deletion that was never requested
+var ErrNoDeletionPending = errors.New("no account deletion is pending")
+
+// PurgeResult lists what has to be cleaned up outside the database after a purge
+type PurgeResult struct {
+	CarIDs       []uuid.UUID // Listings taken down (cache entries to drop)
+	FileURLs     []string    // Listing images, chat media, profile photo and dealer logo to delete from storage
+	DocumentKeys []string    // Dealer verification documents to delete from private storage
+}
+
+// --- Data export ---
+
+// ExportFavorite is a favorited listing in a data export
+type ExportFavorite struct {
+	CarID       uuid.UUID `json:"car_id"`
+	Title       string    `json:"title"`
+	Price       float64   `json:"price"`
+	Status      string    `json:"status"`
+	FavoritedAt time.Time `json:"favorited_at"`
+}
+
+// ExportConversation is a conversation with all its messages in a data export
+type ExportConversation struct {
+	ID             uuid.UUID       `json:"id"`
+	CarID          *uuid.UUID      `json:"car_id,omitempty"`
+	CarTitle       string          `json:"car_title,omitempty"`
+	ParticipantIDs []uuid.UUID     `json:"participant_ids" gorm:"-"`
+	CreatedAt      time.Time       `json:"created_at"`
+	Messages       []ExportMessage `json:"messages" gorm:"-"`
+}
+
+// ExportMessage is a chat message in a data export
+type ExportMessage struct {
+	ID             uuid.UUID  `json:"id"`
+	ConversationID uuid.UUID  `json:"-"`
+	SenderID       *uuid.UUID `json:"sender_id"`
+	Sent           bool       `json:"sent"` // Sent by the exporting user
+	Content        string     `json:"content"`
+	MessageType    string     `json:"message_type"`
+	MediaURL       *string    `json:"media_url,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
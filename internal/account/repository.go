@@ -0,0 +1,280 @@
+package account
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/yourusername/car-reselling-backend/internal/listing"
+	"github.com/yourusername/car-reselling-backend/internal/models"
+	"github.com/yourusername/car-reselling-backend/internal/notification"
+	appErrors "github.com/yourusername/car-reselling-backend/pkg/errors"
+)
+
+// Repository handles database operations for account deletion and export
+type Repository struct {
+	db *gorm.DB
+}
+
+// NewRepository creates a new account repository
+func NewRepository(db *gorm.DB) *Repository {
+	return &Repository{db: db}
+}
+
+// FindUser retrieves a user that has not been deleted
+func (r *Repository) FindUser(ctx context.Context, userID uuid.UUID) (*models.User, error) {
+	var user models.User
+	err := r.db.WithContext(ctx).Where("id = ? AND deleted_at IS NULL", userID).First(&user).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, appErrors.ErrNotFound
+	}
+	return &user, err
+}
+
+// --- Deletion ---
+
+// ScheduleDeletion sets the date an account will be purged
+func (r *Repository) ScheduleDeletion(ctx context.Context, userID uuid.UUID, at time.Time) error {
+	return r.db.WithContext(ctx).Exec(
+		"UPDATE users SET deletion_scheduled_at = ?, updated_at = NOW() WHERE id = ? AND deleted_at IS NULL",
+		at, userID,
+	).Error
+}
+
+// CancelDeletion clears a pending deletion; it reports false if none was pending
+func (r *Repository) CancelDeletion(ctx context.Context, userID uuid.UUID) (bool, error) {
+	result := r.db.WithContext(ctx).Exec(`
+		UPDATE users SET deletion_scheduled_at = NULL, updated_at = NOW()
+		WHERE id = ? AND deletion_scheduled_at IS NOT NULL AND deleted_at IS NULL`,
+		userID,
+	)
+	return result.RowsAffected > 0, result.Error
+}
+
+// FindDueDeletions returns users whose grace period is over
+func (r *Repository) FindDueDeletions(ctx context.Context, now time.Time) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT id FROM users
+		WHERE deletion_scheduled_at <= ? AND deleted_at IS NULL
+		ORDER BY deletion_scheduled_at`,
+		now,
+	).Scan(&ids).Error
+	return ids, err
+}
+
+// Purge deletes or anonymises everything belonging to a user whose deletion is
+// due. The user row is kept (anonymised) so conversations stay consistent for
+// the other participants. Returns nil if the user is not due (already purged
+// by another replica, or the deletion was cancelled).
+func (r *Repository) Purge(ctx context.Context, userID uuid.UUID) (*PurgeResult, error) {
+	var result *PurgeResult
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Lock the user so concurrent purges and cancellations serialize
+		var user struct {
+			ProfilePhotoURL *string
+		}
+		res := tx.Raw(`
+			SELECT profile_photo_url FROM users
+			WHERE id = ? AND deletion_scheduled_at <= NOW() AND deleted_at IS NULL
+			FOR UPDATE`,
+			userID,
+		).Scan(&user)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return nil
+		}
+
+		result = &PurgeResult{}
+		if user.ProfilePhotoURL != nil && *user.ProfilePhotoURL != "" {
+			result.FileURLs = append(result.FileURLs, *user.ProfilePhotoURL)
+		}
+
+		// Files to remove from storage once the transaction commits
+		var images []string
+		if err := tx.Raw(
+			"SELECT UNNEST(images) FROM cars WHERE seller_id = ?", userID,
+		).Scan(&images).Error; err != nil {
+			return err
+		}
+		var media []string
+		if err := tx.Raw(
+			"SELECT media_url FROM messages WHERE sender_id = ? AND media_url IS NOT NULL AND media_url <> ''", userID,
+		).Scan(&media).Error; err != nil {
+			return err
+		}
+		var logos []string
+		if err := tx.Raw(
+			"SELECT logo_url FROM dealer_profiles WHERE user_id = ? AND logo_url IS NOT NULL AND logo_url <> ''", userID,
+		).Scan(&logos).Error; err != nil {
+			return err
+		}
+		if err := tx.Raw(
+			"SELECT UNNEST(verification_documents) FROM dealer_profiles WHERE user_id = ?", userID,
+		).Scan(&result.DocumentKeys).Error; err != nil {
+			return err
+		}
+		result.FileURLs = append(result.FileURLs, images...)
+		result.FileURLs = append(result.FileURLs, media...)
+		result.FileURLs = append(result.FileURLs, logos...)
+
+		// Soft-delete listings and drop their images
+		if err := tx.Raw(`
+			UPDATE cars SET status = 'deleted', images = '{}', updated_at = NOW()
+			WHERE seller_id = ?
+			RETURNING id`,
+			userID,
+		).Scan(&result.CarIDs).Error; err != nil {
+			return err
+		}
+
+		// Anonymise chat messages
+		if err := tx.Exec(
+			"UPDATE messages SET content = ?, media_url = NULL WHERE sender_id = ?",
+			deletedMessageContent, userID,
+		).Error; err != nil {
+			return err
+		}
+
+		purges := []struct {
+			query string
+			args  []interface{}
+		}{
+			{"DELETE FROM favorites WHERE user_id = ? OR car_id IN (SELECT id FROM cars WHERE seller_id = ?)", []interface{}{userID, userID}},
+			{"DELETE FROM user_devices WHERE user_id = ?", []interface{}{userID}},
+			{"DELETE FROM notifications WHERE user_id = ?", []interface{}{userID}},
+			{"DELETE FROM saved_searches WHERE user_id = ?", []interface{}{userID}},
+			{"DELETE FROM user_recovery_codes WHERE user_id = ?", []interface{}{userID}},
+			{"DELETE FROM dealer_profiles WHERE user_id = ?", []interface{}{userID}},
+			{"DELETE FROM user_subscriptions WHERE user_id = ?", []interface{}{userID}},
+			{"DELETE FROM offers WHERE buyer_id = ? OR seller_id = ?", []interface{}{userID, userID}},
+			{"DELETE FROM appointments WHERE buyer_id = ? OR seller_id = ?", []interface{}{userID, userID}},
+			{"DELETE FROM seller_availability WHERE seller_id = ?", []interface{}{userID}},
+		}
+		for _, p := range purges {
+			if err := tx.Exec(p.query, p.args...).Error; err != nil {
+				return err
+			}
+		}
+
+		// Anonymise the user; unique placeholders keep the email/phone constraints happy
+		return tx.Exec(`
+			UPDATE users SET
+				email = ?, phone = ?, full_name = ?, password_hash = '',
+				gender = NULL, dob = NULL, profile_photo_url = NULL,
+				is_active = FALSE, is_verified = FALSE,
+				email_verified = FALSE, email_verified_at = NULL,
+				two_factor_enabled = FALSE, two_factor_enabled_at = NULL, totp_secret = NULL,
+				deletion_scheduled_at = NULL, deleted_at = NOW(), updated_at = NOW()
+			WHERE id = ?`,
+			fmt.Sprintf("deleted+%s@deleted.invalid", userID),
+			fmt.Sprintf("deleted:%s", userID),
+			deletedUserName,
+			userID,
+		).Error
+	})
+
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// --- Export ---
+
+// FindListings retrieves all of a seller's listings, including deleted ones
+func (r *Repository) FindListings(ctx context.Context, userID uuid.UUID) ([]listing.Car, error) {
+	cars := []listing.Car{}
+	err := r.db.WithContext(ctx).Where("seller_id = ?", userID).Order("created_at").Find(&cars).Error
+	return cars, err
+}
+
+// FindFavorites retrieves a user's favorited listings
+func (r *Repository) FindFavorites(ctx context.Context, userID uuid.UUID) ([]ExportFavorite, error) {
+	favorites := []ExportFavorite{}
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT f.car_id, c.title, c.price, c.status, f.created_at AS favorited_at
+		FROM favorites f
+		JOIN cars c ON c.id = f.car_id
+		WHERE f.user_id = ?
+		ORDER BY f.created_at`,
+		userID,
+	).Scan(&favorites).Error
+	return favorites, err
+}
+
+// FindConversations retrieves a user's conversations with all their messages
+func (r *Repository) FindConversations(ctx context.Context, userID uuid.UUID) ([]ExportConversation, error) {
+	db := r.db.WithContext(ctx)
+
+	conversations := []ExportConversation{}
+	if err := db.Raw(`
+		SELECT c.id, c.car_id, COALESCE(c.car_title, '') AS car_title, c.created_at
+		FROM conversations c
+		JOIN conversation_participants cp ON cp.conversation_id = c.id
+		WHERE cp.user_id = ?
+		ORDER BY c.created_at`,
+		userID,
+	).Scan(&conversations).Error; err != nil {
+		return nil, err
+	}
+	if len(conversations) == 0 {
+		return conversations, nil
+	}
+
+	ids := make([]uuid.UUID, len(conversations))
+	index := make(map[uuid.UUID]int, len(conversations))
+	for i, c := range conversations {
+		ids[i] = c.ID
+		index[c.ID] = i
+		conversations[i].ParticipantIDs = []uuid.UUID{}
+		conversations[i].Messages = []ExportMessage{}
+	}
+
+	var participants []struct {
+		ConversationID uuid.UUID
+		UserID         uuid.UUID
+	}
+	if err := db.Raw(
+		"SELECT conversation_id, user_id FROM conversation_participants WHERE conversation_id IN ?", ids,
+	).Scan(&participants).Error; err != nil {
+		return nil, err
+	}
+	for _, p := range participants {
+		i := index[p.ConversationID]
+		conversations[i].ParticipantIDs = append(conversations[i].ParticipantIDs, p.UserID)
+	}
+
+	var messages []ExportMessage
+	if err := db.Raw(`
+		SELECT id, conversation_id, sender_id, COALESCE(sender_id = ?, FALSE) AS sent,
+			COALESCE(content, '') AS content, COALESCE(message_type, 'text') AS message_type,
+			media_url, created_at
+		FROM messages
+		WHERE conversation_id IN ?
+		ORDER BY created_at`,
+		userID, ids,
+	).Scan(&messages).Error; err != nil {
+		return nil, err
+	}
+	for _, m := range messages {
+		i := index[m.ConversationID]
+		conversations[i].Messages = append(conversations[i].Messages, m)
+	}
+
+	return conversations, nil
+}
+
+// FindNotifications retrieves all of a user's notifications
+func (r *Repository) FindNotifications(ctx context.Context, userID uuid.UUID) ([]notification.Notification, error) {
+	notifications := []notification.Notification{}
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("created_at").Find(&notifications).Error
+	return notifications, err
+}
@@ -0,0 +1,225 @@
+package account
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/yourusername/car-reselling-backend/internal/listing"
+	appErrors "github.com/yourusername/car-reselling-backend/pkg/errors"
+	"github.com/yourusername/car-reselling-backend/pkg/utils"
+)
+
+// SessionRevoker logs a user out of every device
+type SessionRevoker interface {
+	RevokeAllSessions(ctx context.Context, userID string) error
+}
+
+// DocumentDeleter removes files from private storage
+type DocumentDeleter interface {
+	DeleteDocuments(ctx context.Context, keys []string) error
+}
+
+// Service handles account deletion and data export
+type Service struct {
+	repo      *Repository
+	storage   listing.StorageService
+	documents DocumentDeleter
+	cache     *redis.Client
+	sessions  SessionRevoker
+}
+
+// NewService creates a new account service
+func NewService(repo *Repository, storage listing.StorageService, documents DocumentDeleter, cache *redis.Client, sessions SessionRevoker) *Service {
+	return &Service{
+		repo:      repo,
+		storage:   storage,
+		documents: documents,
+		cache:     cache,
+		sessions:  sessions,
+	}
+}
+
+// --- Deletion ---
+
+// RequestDeletion schedules the account for deletion after DeletionGracePeriod
+// and logs the user out everywhere. Logging in again and cancelling keeps the
+// account. Requesting again keeps the original date.
+func (s *Service) RequestDeletion(ctx context.Context, userID uuid.UUID, password string) (*DeletionResponse, error) {
+	user, err := s.repo.FindUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !utils.CheckPassword(password, user.PasswordHash) {
+		return nil, appErrors.ErrCurrentPasswordIncorrect
+	}
+
+	scheduledAt := time.Now().Add(DeletionGracePeriod)
+	if user.DeletionScheduledAt != nil {
+		scheduledAt = *user.DeletionScheduledAt
+	} else if err := s.repo.ScheduleDeletion(ctx, userID, scheduledAt); err != nil {
+		return nil, err
+	}
+
+	if err := s.sessions.RevokeAllSessions(ctx, userID.String()); err != nil {
+		log.Printf("Failed to revoke sessions for %s after deletion request: %v", userID, err)
+	}
+
+	return &DeletionResponse{
+		DeletionScheduledAt: scheduledAt,
+		Message: fmt.Sprintf("Your account will be deleted on %s. Log in and cancel before then to keep it.",
+			scheduledAt.Format("2006-01-02")),
+	}, nil
+}
+
+// CancelDeletion cancels a pending deletion request
+func (s *Service) CancelDeletion(ctx context.Context, userID uuid.UUID) error {
+	cancelled, err := s.repo.CancelDeletion(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !cancelled {
+		return ErrNoDeletionPending
+	}
+	return nil
+}
+
+// RunPurgeWorker periodically purges accounts whose grace period is over
+func (s *Service) RunPurgeWorker() {
+	ticker := time.NewTicker(purgeInterval)
+	defer ticker.Stop()
+
+	for {
+		s.PurgeDueAccounts(context.Background())
+		<-ticker.C
+	}
+}
+
+// PurgeDueAccounts purges every account whose deletion date has passed
+func (s *Service) PurgeDueAccounts(ctx context.Context) {
+	ids, err := s.repo.FindDueDeletions(ctx, time.Now())
+	if err != nil {
+		log.Printf("Failed to find due account deletions: %v", err)
+		return
+	}
+
+	for _, id := range ids {
+		if err := s.purgeAccount(ctx, id); err != nil {
+			log.Printf("Failed to purge account %s: %v", id, err)
+		}
+	}
+}
+
+// purgeAccount anonymises one account, then cleans up caches, files and sessions
+func (s *Service) purgeAccount(ctx context.Context, userID uuid.UUID) error {
+	result, err := s.repo.Purge(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if result == nil {
+		return nil // Cancelled or purged by another replica
+	}
+
+	for _, carID := range result.CarIDs {
+		s.cache.Del(ctx, fmt.Sprintf("cache:car:%s", carID))
+	}
+
+	if len(result.FileURLs) > 0 {
+		if err := s.storage.DeleteMultipleImages(ctx, result.FileURLs); err != nil {
+			log.Printf("Failed to delete files of account %s: %v", userID, err)
+		}
+	}
+	if len(result.DocumentKeys) > 0 {
+		if err := s.documents.DeleteDocuments(ctx, result.DocumentKeys); err != nil {
+			log.Printf("Failed to delete documents of account %s: %v", userID, err)
+		}
+	}
+
+	if err := s.sessions.RevokeAllSessions(ctx, userID.String()); err != nil {
+		log.Printf("Failed to revoke sessions of account %s: %v", userID, err)
+	}
+
+	log.Printf("Purged account %s (%d listings, %d files)", userID, len(result.CarIDs), len(result.FileURLs))
+	return nil
+}
+
+// --- Export ---
+
+// Export builds a ZIP archive with the user's profile, listings, favorites,
+// conversations and notifications as JSON files
+func (s *Service) Export(ctx context.Context, userID uuid.UUID) ([]byte, error) {
+	// Rate limit (max 3 exports per hour)
+	rateLimitKey := fmt.Sprintf("account_export_rate_limit:%s", userID)
+	count, err := s.cache.Incr(ctx, rateLimitKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to check export rate limit: %w", err)
+	}
+	if count == 1 {
+		s.cache.Expire(ctx, rateLimitKey, time.Hour)
+	}
+	if count > maxExportsPerHour {
+		return nil, appErrors.ErrTooManyAttempts
+	}
+
+	user, err := s.repo.FindUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	listings, err := s.repo.FindListings(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	favorites, err := s.repo.FindFavorites(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	conversations, err := s.repo.FindConversations(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	notifications, err := s.repo.FindNotifications(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	files := []struct {
+		name string
+		data interface{}
+	}{
+		{"profile.json", user},
+		{"listings.json", listings},
+		{"favorites.json", favorites},
+		{"conversations.json", conversations},
+		{"notifications.json", notifications},
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, f := range files {
+		w, err := zw.CreateHeader(&zip.FileHeader{
+			Name:     f.name,
+			Method:   zip.Deflate,
+			Modified: time.Now(),
+		})
+		if err != nil {
+			return nil, err
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(f.data); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", f.name, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,48 @@
+package appointment
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CreateWindowRequest adds a time the seller is available
+type CreateWindowRequest struct {
+	StartsAt time.Time  `json:"starts_at" binding:"required"`
+	EndsAt   time.Time  `json:"ends_at" binding:"required"`
+	CarID    *uuid.UUID `json:"car_id"` // Optional, limits the window to one listing
+}
+
+// BookingRequest asks the seller for a viewing or test drive
+type BookingRequest struct {
+	Type            string    `json:"type" binding:"omitempty,oneof=viewing test_drive" example:"test_drive"`
+	StartsAt        time.Time `json:"starts_at" binding:"required"`
+	DurationMinutes int       `json:"duration_minutes" binding:"omitempty,min=15,max=120" example:"30"` // Defaults to DefaultDuration
+	Note            string    `json:"note" binding:"max=500"`
+}
+
+// RescheduleRequest proposes a new time
+type RescheduleRequest struct {
+	StartsAt        time.Time `json:"starts_at" binding:"required"`
+	DurationMinutes int       `json:"duration_minutes" binding:"omitempty,min=15,max=120"` // Defaults to the current duration
+}
+
+// DeclineRequest gives an optional reason for declining
+type DeclineRequest struct {
+	Reason string `json:"reason" binding:"max=500"`
+}
+
+// AvailabilityResponse is when a listing can be seen: the seller's windows and
+// the times already taken within them
+type AvailabilityResponse struct {
+	Windows []Window `json:"windows"`
+	Busy    []Slot   `json:"busy"`
+}
+
+// AppointmentListResponse is a page of appointments
+type AppointmentListResponse struct {
+	Items []Appointment `json:"items"`
+	Total int64         `json:"total"`
+	Page  int           `json:"page"`
+	Limit int           `json:"limit"`
+}
@@ -0,0 +1,474 @@
+package appointment
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	appErrors "github.com/yourusername/car-reselling-backend/pkg/errors"
+)
+
+// Handler handles HTTP requests for availability and appointments
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a new appointment handler
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// RegisterRoutes registers the availability and appointment routes; all of them require authentication
+func (h *Handler) RegisterRoutes(router *gin.RouterGroup, authMiddleware gin.HandlerFunc) {
+	router.GET("/cars/:id/availability", authMiddleware, h.GetAvailability)
+	router.POST("/cars/:id/appointments", authMiddleware, h.Book)
+
+	availability := router.Group("/availability")
+	availability.Use(authMiddleware)
+	{
+		availability.GET("", h.GetMyWindows)
+		availability.POST("", h.AddWindow)
+		availability.DELETE("/:id", h.DeleteWindow)
+	}
+
+	appointments := router.Group("/appointments")
+	appointments.Use(authMiddleware)
+	{
+		appointments.GET("", h.List)
+		appointments.GET("/:id", h.Get)
+		appointments.GET("/:id/calendar.ics", h.Calendar)
+		appointments.POST("/:id/confirm", h.Confirm)
+		appointments.POST("/:id/decline", h.Decline)
+		appointments.POST("/:id/cancel", h.Cancel)
+		appointments.POST("/:id/reschedule", h.Reschedule)
+	}
+}
+
+// GetMyWindows lists the seller's availability
+// @Summary List my availability
+// @Description The current user's availability windows that have not ended, in order
+// @Tags appointments
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} Window
+// @Failure 401 {object} map[string]string
+// @Router /api/availability [get]
+func (h *Handler) GetMyWindows(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	windows, err := h.service.GetMyWindows(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, windows)
+}
+
+// AddWindow adds an availability window
+// @Summary Add availability
+// @Description Add a time (up to 12 hours) the seller is available for viewings and test drives, for all their listings or the given one. Windows cannot overlap.
+// @Tags appointments
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body CreateWindowRequest true "Window"
+// @Success 201 {object} Window
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string "Listing not found"
+// @Failure 409 {object} map[string]string "Overlaps another window"
+// @Router /api/availability [post]
+func (h *Handler) AddWindow(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	var req CreateWindowRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	window, err := h.service.AddWindow(c.Request.Context(), userID, &req)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, window)
+}
+
+// DeleteWindow removes an availability window
+// @Summary Remove availability
+// @Description Remove one of the seller's windows. Appointments already booked in it are kept.
+// @Tags appointments
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Window ID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/availability/{id} [delete]
+func (h *Handler) DeleteWindow(c *gin.Context) {
+	userID, windowID, ok := idParams(c, "Invalid window ID")
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteWindow(c.Request.Context(), userID, windowID); err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Availability removed"})
+}
+
+// GetAvailability returns when a listing can be seen
+// @Summary Get listing availability
+// @Description The seller's upcoming windows for the listing and the times within them already taken by confirmed appointments. A reserved listing is only available to its seller and to the buyer whose offer was accepted.
+// @Tags appointments
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Car ID"
+// @Success 200 {object} AvailabilityResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string "Listing not available"
+// @Router /api/cars/{id}/availability [get]
+func (h *Handler) GetAvailability(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	carID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid car ID"})
+		return
+	}
+
+	availability, err := h.service.GetAvailability(c.Request.Context(), userID, carID)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, availability)
+}
+
+// Book requests an appointment
+// @Summary Book a viewing or test drive
+// @Description Request a viewing or test drive inside one of the seller's availability windows. The seller confirms, declines or proposes another time; the request is posted into the conversation with the seller.
+// @Tags appointments
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Car ID"
+// @Param request body BookingRequest true "Type, start time and duration"
+// @Success 201 {object} Appointment
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string "Outside availability or conflicting with another appointment"
+// @Router /api/cars/{id}/appointments [post]
+func (h *Handler) Book(c *gin.Context) {
+	userID, carID, ok := idParams(c, "Invalid car ID")
+	if !ok {
+		return
+	}
+
+	var req BookingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	appt, err := h.service.Book(c.Request.Context(), userID, carID, &req)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, appt)
+}
+
+// List lists the user's appointments
+// @Summary List my appointments
+// @Description Appointments the current user booked (role=buyer, default) or received on their listings (role=seller), soonest first
+// @Tags appointments
+// @Security BearerAuth
+// @Produce json
+// @Param role query string false "buyer or seller" Enums(buyer, seller) default(buyer)
+// @Param status query string false "Filter by status" Enums(requested, confirmed, declined, cancelled, expired)
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} AppointmentListResponse
+// @Failure 400 {object} map[string]string
+// @Router /api/appointments [get]
+func (h *Handler) List(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	role := c.DefaultQuery("role", RoleBuyer)
+	if role != RoleBuyer && role != RoleSeller {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "role must be buyer or seller"})
+		return
+	}
+	status := c.Query("status")
+	switch status {
+	case "", StatusRequested, StatusConfirmed, StatusDeclined, StatusCancelled, StatusExpired:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status"})
+		return
+	}
+
+	page, limit := pagination(c)
+	appts, err := h.service.List(c.Request.Context(), userID, role, status, page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, appts)
+}
+
+// Get returns an appointment
+// @Summary Get an appointment
+// @Tags appointments
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Appointment ID"
+// @Success 200 {object} Appointment
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/appointments/{id} [get]
+func (h *Handler) Get(c *gin.Context) {
+	userID, appointmentID, ok := idParams(c, "Invalid appointment ID")
+	if !ok {
+		return
+	}
+
+	appt, err := h.service.Get(c.Request.Context(), userID, appointmentID)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, appt)
+}
+
+// Calendar exports an appointment as iCalendar
+// @Summary Export an appointment to a calendar
+// @Description The appointment as an iCalendar (.ics) event with a reminder an hour before. Importing it again after a reschedule updates the event.
+// @Tags appointments
+// @Security BearerAuth
+// @Produce text/calendar
+// @Param id path string true "Appointment ID"
+// @Success 200 {string} string "iCalendar file"
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/appointments/{id}/calendar.ics [get]
+func (h *Handler) Calendar(c *gin.Context) {
+	userID, appointmentID, ok := idParams(c, "Invalid appointment ID")
+	if !ok {
+		return
+	}
+
+	ics, err := h.service.Calendar(c.Request.Context(), userID, appointmentID)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="appointment-%s.ics"`, appointmentID))
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", ics)
+}
+
+// Confirm confirms an appointment
+// @Summary Confirm an appointment
+// @Description Accept the proposed time. Only the side that did not propose it can confirm. Other requests at that time for either side are declined.
+// @Tags appointments
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Appointment ID"
+// @Success 200 {object} Appointment
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string "Not awaiting your answer, or conflicting with another appointment"
+// @Router /api/appointments/{id}/confirm [post]
+func (h *Handler) Confirm(c *gin.Context) {
+	userID, appointmentID, ok := idParams(c, "Invalid appointment ID")
+	if !ok {
+		return
+	}
+
+	appt, err := h.service.Confirm(c.Request.Context(), userID, appointmentID)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, appt)
+}
+
+// Decline declines an appointment
+// @Summary Decline an appointment
+// @Description Turn down the proposed time, with an optional reason. Only the side that did not propose it can decline.
+// @Tags appointments
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Appointment ID"
+// @Param request body DeclineRequest false "Reason"
+// @Success 200 {object} Appointment
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string "Not awaiting your answer"
+// @Router /api/appointments/{id}/decline [post]
+func (h *Handler) Decline(c *gin.Context) {
+	userID, appointmentID, ok := idParams(c, "Invalid appointment ID")
+	if !ok {
+		return
+	}
+
+	// The reason is optional, so is the body
+	var req DeclineRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	appt, err := h.service.Decline(c.Request.Context(), userID, appointmentID, req.Reason)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, appt)
+}
+
+// Cancel cancels an appointment
+// @Summary Cancel an appointment
+// @Description Either side calls off a requested or confirmed appointment
+// @Tags appointments
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Appointment ID"
+// @Success 200 {object} Appointment
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string "Already closed or started"
+// @Router /api/appointments/{id}/cancel [post]
+func (h *Handler) Cancel(c *gin.Context) {
+	userID, appointmentID, ok := idParams(c, "Invalid appointment ID")
+	if !ok {
+		return
+	}
+
+	appt, err := h.service.Cancel(c.Request.Context(), userID, appointmentID)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, appt)
+}
+
+// Reschedule proposes a new time
+// @Summary Reschedule an appointment
+// @Description Either side proposes a new time for a requested or confirmed appointment; the other side then confirms or declines it. Buyers must pick a time inside the seller's availability.
+// @Tags appointments
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Appointment ID"
+// @Param request body RescheduleRequest true "New start time and optional duration"
+// @Success 200 {object} Appointment
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string "Closed, outside availability or conflicting with another appointment"
+// @Router /api/appointments/{id}/reschedule [post]
+func (h *Handler) Reschedule(c *gin.Context) {
+	userID, appointmentID, ok := idParams(c, "Invalid appointment ID")
+	if !ok {
+		return
+	}
+
+	var req RescheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	appt, err := h.service.Reschedule(c.Request.Context(), userID, appointmentID, &req)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, appt)
+}
+
+func handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, appErrors.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Not found"})
+	case errors.Is(err, ErrOwnListing):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrInvalidTransition), errors.Is(err, ErrAppointmentStarted),
+		errors.Is(err, ErrWindowOverlap), errors.Is(err, ErrOutsideAvailability),
+		errors.Is(err, ErrTimeConflict), errors.Is(err, ErrListingUnavailable):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrInvalidTime), errors.Is(err, ErrInvalidWindow):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// idParams reads the current user and the ID from the path
+func idParams(c *gin.Context, invalidMessage string) (uuid.UUID, uuid.UUID, bool) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return uuid.Nil, uuid.Nil, false
+	}
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": invalidMessage})
+		return uuid.Nil, uuid.Nil, false
+	}
+	return userID, id, true
+}
+
+func pagination(c *gin.Context) (int, int) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	return page, limit
+}
+
+func getUserID(c *gin.Context) (uuid.UUID, bool) {
+	userID, err := uuid.Parse(c.GetString("userID"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return uuid.Nil, false
+	}
+	return userID, true
+}
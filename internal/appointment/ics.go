@@ -0,0 +1,113 @@
+package appointment
+
+import (
+	"fmt"
+	"strings"
+)
+
+// icsTimeFormat is the UTC date-time form of RFC 5545
+const icsTimeFormat = "20060102T150405Z"
+
+// icsLineLimit is the longest content line in octets before it is folded
+const icsLineLimit = 75
+
+// Calendar renders an appointment as an iCalendar (RFC 5545) file with a
+// reminder an hour before. Rescheduling bumps SEQUENCE so calendar apps update
+// the event they imported earlier.
+func Calendar(appt *Appointment, car *Car) []byte {
+	summary := "Viewing: " + car.Title
+	if appt.Type == TypeTestDrive {
+		summary = "Test drive: " + car.Title
+	}
+
+	description := fmt.Sprintf("%s of %s", typeLabel(appt.Type), car.Title)
+	if appt.Note != "" {
+		description += "\n\n" + appt.Note
+	}
+
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//Car Reselling//Appointments//EN",
+		"CALSCALE:GREGORIAN",
+		"METHOD:PUBLISH",
+		"BEGIN:VEVENT",
+		"UID:" + appt.ID.String() + "@appointments",
+		"DTSTAMP:" + appt.UpdatedAt.UTC().Format(icsTimeFormat),
+		"DTSTART:" + appt.StartsAt.UTC().Format(icsTimeFormat),
+		"DTEND:" + appt.EndsAt.UTC().Format(icsTimeFormat),
+		fmt.Sprintf("SEQUENCE:%d", appt.Sequence),
+		"STATUS:" + icsStatus(appt.Status),
+		"SUMMARY:" + icsEscape(summary),
+		"DESCRIPTION:" + icsEscape(description),
+	}
+	if location := car.Location(); location != "" {
+		lines = append(lines, "LOCATION:"+icsEscape(location))
+	}
+	if appt.Status == StatusConfirmed || appt.Status == StatusRequested {
+		lines = append(lines,
+			"BEGIN:VALARM",
+			"ACTION:DISPLAY",
+			"DESCRIPTION:"+icsEscape(summary),
+			"TRIGGER:-PT1H",
+			"END:VALARM",
+		)
+	}
+	lines = append(lines, "END:VEVENT", "END:VCALENDAR")
+
+	var b strings.Builder
+	for _, line := range lines {
+		b.WriteString(icsFold(line))
+		b.WriteString("\r\n")
+	}
+	return []byte(b.String())
+}
+
+func icsStatus(status string) string {
+	switch status {
+	case StatusConfirmed:
+		return "CONFIRMED"
+	case StatusRequested:
+		return "TENTATIVE"
+	}
+	return "CANCELLED"
+}
+
+// icsEscape escapes a TEXT value
+func icsEscape(s string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+	).Replace(s)
+}
+
+// icsFold splits a content line into lines of at most icsLineLimit octets,
+// continuation lines starting with a space. Multi-byte characters are not split.
+func icsFold(line string) string {
+	if len(line) <= icsLineLimit {
+		return line
+	}
+
+	var b strings.Builder
+	width := 0
+	for _, r := range line {
+		size := len(string(r))
+		if width+size > icsLineLimit {
+			b.WriteString("\r\n ")
+			width = 1
+		}
+		b.WriteRune(r)
+		width += size
+	}
+	return b.String()
+}
+
+func typeLabel(appointmentType string) string {
+	if appointmentType == TypeTestDrive {
+		return "Test drive"
+	}
+	return "Viewing"
+}
@@ -0,0 +1,69 @@
+package appointment
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+func TestCalendar(t *testing.T) {
+	start := time.Date(2026, 3, 14, 15, 0, 0, 0, time.FixedZone("CET", 3600))
+	appt := &Appointment{
+		ID:        uuid.New(),
+		Type:      TypeTestDrive,
+		StartsAt:  start,
+		EndsAt:    start.Add(30 * time.Minute),
+		Status:    StatusConfirmed,
+		Sequence:  2,
+		Note:      "Bring your licence; parking is at the back, gate B",
+		UpdatedAt: start.Add(-24 * time.Hour),
+	}
+	car := &Car{Title: strings.Repeat("Volkswagen Golf GTI ", 5), City: "Berlin", State: "BE"}
+
+	ics := string(Calendar(appt, car))
+
+	if !strings.HasSuffix(ics, "END:VCALENDAR\r\n") || strings.Contains(strings.ReplaceAll(ics, "\r\n", ""), "\n") {
+		t.Fatalf("lines must end in CRLF:\n%q", ics)
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(ics, "\r\n"), "\r\n") {
+		if len(line) > icsLineLimit {
+			t.Errorf("line longer than %d octets: %q", icsLineLimit, line)
+		}
+	}
+
+	unfolded := strings.ReplaceAll(ics, "\r\n ", "")
+	for _, want := range []string{
+		"UID:" + appt.ID.String() + "@appointments\r\n",
+		"DTSTART:20260314T140000Z\r\n",
+		"DTEND:20260314T143000Z\r\n",
+		"SEQUENCE:2\r\n",
+		"STATUS:CONFIRMED\r\n",
+		`Bring your licence\; parking is at the back\, gate B`,
+		"TRIGGER:-PT1H\r\n",
+	} {
+		if !strings.Contains(unfolded, want) {
+			t.Errorf("calendar is missing %q:\n%s", want, unfolded)
+		}
+	}
+
+	appt.Status = StatusCancelled
+	cancelled := string(Calendar(appt, car))
+	if !strings.Contains(cancelled, "STATUS:CANCELLED\r\n") || strings.Contains(cancelled, "BEGIN:VALARM") {
+		t.Errorf("cancelled appointment should be CANCELLED without an alarm:\n%s", cancelled)
+	}
+}
+
+func TestICSFoldKeepsRunes(t *testing.T) {
+	line := "SUMMARY:" + strings.Repeat("é", 60)
+	for _, part := range strings.Split(icsFold(line), "\r\n ") {
+		if !utf8.ValidString(part) {
+			t.Fatalf("fold split a multi-byte character: %q", part)
+		}
+	}
+	if got := strings.ReplaceAll(icsFold(line), "\r\n ", ""); got != line {
+		t.Errorf("unfolded line = %q, want %q", got, line)
+	}
+}
@@ -0,0 +1,146 @@
+package appointment
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Appointment types
+const (
+	TypeViewing   = "viewing"
+	TypeTestDrive = "test_drive"
+)
+
+// Appointment statuses. Requested appointments wait for the side that did not
+// propose the time; the others are final except confirmed, which can still be
+// rescheduled or cancelled.
+const (
+	StatusRequested = "requested"
+	StatusConfirmed = "confirmed"
+	StatusDeclined  = "declined"
+	StatusCancelled = "cancelled"
+	StatusExpired   = "expired"
+)
+
+// Actions on an appointment
+const (
+	ActionConfirm    = "confirm"
+	ActionDecline    = "decline"
+	ActionCancel     = "cancel"
+	ActionReschedule = "reschedule"
+)
+
+// Sides of an appointment
+const (
+	RoleBuyer  = "buyer"
+	RoleSeller = "seller"
+)
+
+// Reasons for requests declined by the system rather than a user
+const (
+	DeclineReasonTimeTaken = "time_taken"
+)
+
+// Notification types sent about appointments
+const (
+	NotificationTypeAppointmentRequested   = "appointment_requested"
+	NotificationTypeAppointmentConfirmed   = "appointment_confirmed"
+	NotificationTypeAppointmentDeclined    = "appointment_declined"
+	NotificationTypeAppointmentCancelled   = "appointment_cancelled"
+	NotificationTypeAppointmentRescheduled = "appointment_rescheduled"
+	NotificationTypeAppointmentExpired     = "appointment_expired"
+	NotificationTypeAppointmentReminder    = "appointment_reminder"
+)
+
+// Window is a time a seller is available for viewings and test drives
+type Window struct {
+	ID        uuid.UUID  `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	SellerID  uuid.UUID  `json:"seller_id" gorm:"type:uuid"`
+	CarID     *uuid.UUID `json:"car_id,omitempty" gorm:"type:uuid"` // Nil for all of the seller's listings
+	StartsAt  time.Time  `json:"starts_at"`
+	EndsAt    time.Time  `json:"ends_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// TableName overrides the default table name
+func (Window) TableName() string { return "seller_availability" }
+
+// Appointment is a buyer's viewing or test drive of a listing
+type Appointment struct {
+	ID             uuid.UUID  `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	CarID          uuid.UUID  `json:"car_id" gorm:"type:uuid"`
+	BuyerID        uuid.UUID  `json:"buyer_id" gorm:"type:uuid"`
+	SellerID       uuid.UUID  `json:"seller_id" gorm:"type:uuid"`
+	Type           string     `json:"type" gorm:"type:varchar(20);default:viewing"`
+	StartsAt       time.Time  `json:"starts_at"`
+	EndsAt         time.Time  `json:"ends_at"`
+	Status         string     `json:"status" gorm:"type:varchar(20);default:requested"`
+	ProposedBy     uuid.UUID  `json:"proposed_by" gorm:"type:uuid"` // Whoever set the current time; the other side answers
+	Note           string     `json:"note,omitempty"`
+	DeclineReason  string     `json:"decline_reason,omitempty"`
+	Sequence       int        `json:"-"`
+	ReminderSentAt *time.Time `json:"-"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+
+	// Listing context, filled in by queries that join cars
+	CarTitle string `json:"car_title,omitempty" gorm:"->;-:migration"`
+}
+
+// TableName overrides the default table name
+func (Appointment) TableName() string { return "appointments" }
+
+// Car is the part of a listing appointments need
+type Car struct {
+	ID       uuid.UUID
+	SellerID uuid.UUID
+	Title    string
+	Status   string
+	City     string
+	State    string
+	Image    string // First image, for notifications
+}
+
+// Location is where the car can be seen
+func (c *Car) Location() string {
+	switch {
+	case c.City != "" && c.State != "":
+		return c.City + ", " + c.State
+	case c.City != "":
+		return c.City
+	}
+	return c.State
+}
+
+// Slot is a time taken by a confirmed appointment
+type Slot struct {
+	StartsAt time.Time `json:"starts_at"`
+	EndsAt   time.Time `json:"ends_at"`
+}
+
+// transition returns the status an appointment moves to when one of its sides
+// takes an action, or ErrInvalidTransition. Only the side that did not
+// propose the time can confirm or decline it; either side can reschedule or
+// cancel an open appointment.
+func transition(status string, proposer bool, action string) (string, error) {
+	switch action {
+	case ActionConfirm:
+		if status == StatusRequested && !proposer {
+			return StatusConfirmed, nil
+		}
+	case ActionDecline:
+		if status == StatusRequested && !proposer {
+			return StatusDeclined, nil
+		}
+	case ActionCancel:
+		if status == StatusRequested || status == StatusConfirmed {
+			return StatusCancelled, nil
+		}
+	case ActionReschedule:
+		if status == StatusRequested || status == StatusConfirmed {
+			return StatusRequested, nil
+		}
+	}
+	return "", ErrInvalidTransition
+}
@@ -0,0 +1,40 @@
+package appointment
+
+import "testing"
+
+func TestTransition(t *testing.T) {
+	tests := []struct {
+		status   string
+		proposer bool
+		action   string
+		want     string // empty when the action is not allowed
+	}{
+		{StatusRequested, false, ActionConfirm, StatusConfirmed},
+		{StatusRequested, false, ActionDecline, StatusDeclined},
+		{StatusRequested, true, ActionCancel, StatusCancelled},
+		{StatusRequested, false, ActionReschedule, StatusRequested},
+		{StatusConfirmed, true, ActionCancel, StatusCancelled},
+		{StatusConfirmed, false, ActionReschedule, StatusRequested},
+
+		{StatusRequested, true, ActionConfirm, ""},
+		{StatusRequested, true, ActionDecline, ""},
+		{StatusConfirmed, false, ActionConfirm, ""},
+		{StatusConfirmed, false, ActionDecline, ""},
+		{StatusDeclined, false, ActionReschedule, ""},
+		{StatusCancelled, true, ActionCancel, ""},
+		{StatusExpired, false, ActionConfirm, ""},
+	}
+
+	for _, tt := range tests {
+		got, err := transition(tt.status, tt.proposer, tt.action)
+		if tt.want == "" {
+			if err != ErrInvalidTransition {
+				t.Errorf("%s on %s (proposer=%v): expected ErrInvalidTransition, got %q", tt.action, tt.status, tt.proposer, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("%s on %s (proposer=%v) = %q, %v; want %q", tt.action, tt.status, tt.proposer, got, err, tt.want)
+		}
+	}
+}
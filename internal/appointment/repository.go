@@ -0,0 +1,346 @@
+package appointment
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	appErrors "github.com/yourusername/car-reselling-backend/pkg/errors"
+)
+
+// Repository handles database operations for availability and appointments
+type Repository struct {
+	db *gorm.DB
+}
+
+// NewRepository creates a new appointment repository
+func NewRepository(db *gorm.DB) *Repository {
+	return &Repository{db: db}
+}
+
+// FindCar retrieves the listing an appointment is about
+func (r *Repository) FindCar(ctx context.Context, carID uuid.UUID) (*Car, error) {
+	var car Car
+	res := r.db.WithContext(ctx).Raw(`
+		SELECT id, seller_id, title, status, COALESCE(city, '') AS city, COALESCE(state, '') AS state,
+			COALESCE(images[1], '') AS image
+		FROM cars WHERE id = ?`,
+		carID,
+	).Scan(&car)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil, appErrors.ErrNotFound
+	}
+	return &car, nil
+}
+
+// HasAcceptedOffer reports whether the buyer has an accepted offer on the listing
+func (r *Repository) HasAcceptedOffer(ctx context.Context, carID, buyerID uuid.UUID) (bool, error) {
+	var accepted bool
+	err := r.db.WithContext(ctx).Raw(
+		`SELECT EXISTS (SELECT 1 FROM offers WHERE car_id = ? AND buyer_id = ? AND status = 'accepted')`,
+		carID, buyerID,
+	).Scan(&accepted).Error
+	return accepted, err
+}
+
+// --- Availability ---
+
+// CreateWindow stores an availability window unless it overlaps another of the seller's
+func (r *Repository) CreateWindow(ctx context.Context, window *Window) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockUsers(tx, window.SellerID); err != nil {
+			return err
+		}
+
+		var overlaps bool
+		if err := tx.Raw(`
+			SELECT EXISTS (
+				SELECT 1 FROM seller_availability
+				WHERE seller_id = ? AND starts_at < ? AND ends_at > ?
+			)`,
+			window.SellerID, window.EndsAt, window.StartsAt,
+		).Scan(&overlaps).Error; err != nil {
+			return err
+		}
+		if overlaps {
+			return ErrWindowOverlap
+		}
+
+		return tx.Create(window).Error
+	})
+}
+
+// ListWindows retrieves a seller's windows that have not ended, in order. If
+// carID is set only the windows that apply to that listing are returned.
+func (r *Repository) ListWindows(ctx context.Context, sellerID uuid.UUID, carID *uuid.UUID, now time.Time) ([]Window, error) {
+	query := r.db.WithContext(ctx).Where("seller_id = ? AND ends_at > ?", sellerID, now)
+	if carID != nil {
+		query = query.Where("car_id IS NULL OR car_id = ?", *carID)
+	}
+
+	windows := []Window{}
+	err := query.Order("starts_at").Find(&windows).Error
+	return windows, err
+}
+
+// DeleteWindow removes a seller's window. Returns false if there was none.
+func (r *Repository) DeleteWindow(ctx context.Context, id, sellerID uuid.UUID) (bool, error) {
+	res := r.db.WithContext(ctx).Where("id = ? AND seller_id = ?", id, sellerID).Delete(&Window{})
+	return res.RowsAffected > 0, res.Error
+}
+
+// FindBusy retrieves the times of a seller's confirmed appointments that have not ended
+func (r *Repository) FindBusy(ctx context.Context, sellerID uuid.UUID, now time.Time) ([]Slot, error) {
+	slots := []Slot{}
+	err := r.db.WithContext(ctx).Model(&Appointment{}).
+		Select("starts_at, ends_at").
+		Where("(seller_id = ? OR buyer_id = ?) AND status = ? AND ends_at > ?", sellerID, sellerID, StatusConfirmed, now).
+		Order("starts_at").
+		Scan(&slots).Error
+	return slots, err
+}
+
+// --- Appointments ---
+
+// Create stores a buyer's request after checking it is inside one of the
+// seller's windows and neither side is busy
+func (r *Repository) Create(ctx context.Context, appt *Appointment) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockUsers(tx, appt.BuyerID, appt.SellerID); err != nil {
+			return err
+		}
+		if err := checkWindow(tx, appt); err != nil {
+			return err
+		}
+		if err := checkConflicts(tx, appt); err != nil {
+			return err
+		}
+		return tx.Create(appt).Error
+	})
+}
+
+// FindByID retrieves an appointment
+func (r *Repository) FindByID(ctx context.Context, id uuid.UUID) (*Appointment, error) {
+	var appt Appointment
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&appt).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, appErrors.ErrNotFound
+	}
+	return &appt, err
+}
+
+// List retrieves a page of a user's appointments as buyer or seller, soonest
+// first, optionally filtered by status, with listing titles
+func (r *Repository) List(ctx context.Context, userID uuid.UUID, role, status string, page, limit int) ([]Appointment, int64, error) {
+	column := "appointments.buyer_id"
+	if role == RoleSeller {
+		column = "appointments.seller_id"
+	}
+
+	query := r.db.WithContext(ctx).Model(&Appointment{}).
+		Joins("JOIN cars ON cars.id = appointments.car_id").
+		Where(column+" = ?", userID)
+	if status != "" {
+		query = query.Where("appointments.status = ?", status)
+	}
+	query = query.Session(&gorm.Session{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	appts := []Appointment{}
+	err := query.Select("appointments.*, cars.title AS car_title").
+		Order("appointments.starts_at").
+		Offset((page - 1) * limit).
+		Limit(limit).
+		Find(&appts).Error
+	return appts, total, err
+}
+
+// Confirm confirms a request that is still open, unless either side has
+// confirmed another appointment at that time meanwhile. Other requests
+// overlapping it for either side are declined and returned.
+func (r *Repository) Confirm(ctx context.Context, appt *Appointment) ([]Appointment, error) {
+	var declined []Appointment
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockUsers(tx, appt.BuyerID, appt.SellerID); err != nil {
+			return err
+		}
+		if err := checkConflicts(tx, appt); err != nil {
+			return err
+		}
+
+		res := tx.Model(&Appointment{}).
+			Where("id = ? AND status = ? AND sequence = ?", appt.ID, StatusRequested, appt.Sequence).
+			Updates(map[string]interface{}{"status": StatusConfirmed, "updated_at": time.Now()})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrInvalidTransition
+		}
+
+		return tx.Raw(`
+			UPDATE appointments
+			SET status = ?, decline_reason = ?, updated_at = NOW()
+			WHERE id <> ? AND status = ?
+			  AND (buyer_id IN (?, ?) OR seller_id IN (?, ?))
+			  AND starts_at < ? AND ends_at > ?
+			RETURNING *`,
+			StatusDeclined, DeclineReasonTimeTaken, appt.ID, StatusRequested,
+			appt.BuyerID, appt.SellerID, appt.BuyerID, appt.SellerID,
+			appt.EndsAt, appt.StartsAt,
+		).Scan(&declined).Error
+	})
+
+	return declined, err
+}
+
+// Reschedule saves a new time proposed by one side, checking the seller's
+// windows if requireWindow is set and that neither side is busy. The
+// appointment must not have changed since it was read.
+func (r *Repository) Reschedule(ctx context.Context, appt *Appointment, from string, requireWindow bool) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockUsers(tx, appt.BuyerID, appt.SellerID); err != nil {
+			return err
+		}
+		if requireWindow {
+			if err := checkWindow(tx, appt); err != nil {
+				return err
+			}
+		}
+		if err := checkConflicts(tx, appt); err != nil {
+			return err
+		}
+
+		res := tx.Model(&Appointment{}).
+			Where("id = ? AND status = ? AND sequence = ?", appt.ID, from, appt.Sequence-1).
+			Updates(map[string]interface{}{
+				"starts_at":        appt.StartsAt,
+				"ends_at":          appt.EndsAt,
+				"status":           appt.Status,
+				"proposed_by":      appt.ProposedBy,
+				"sequence":         appt.Sequence,
+				"reminder_sent_at": nil,
+				"updated_at":       time.Now(),
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrInvalidTransition
+		}
+		return nil
+	})
+}
+
+// Close moves an appointment that is still in status from to a final status
+// (declined or cancelled). Returns false if it changed meanwhile.
+func (r *Repository) Close(ctx context.Context, appt *Appointment, from string) (bool, error) {
+	res := r.db.WithContext(ctx).Model(&Appointment{}).
+		Where("id = ? AND status = ? AND sequence = ?", appt.ID, from, appt.Sequence).
+		Updates(map[string]interface{}{
+			"status":         appt.Status,
+			"decline_reason": appt.DeclineReason,
+			"updated_at":     time.Now(),
+		})
+	return res.RowsAffected > 0, res.Error
+}
+
+// ClaimReminders marks confirmed appointments starting before the given time
+// as reminded and returns them, so each reminder is sent once
+func (r *Repository) ClaimReminders(ctx context.Context, now, before time.Time) ([]Appointment, error) {
+	var appts []Appointment
+	err := r.db.WithContext(ctx).Raw(`
+		UPDATE appointments SET reminder_sent_at = ?
+		WHERE status = ? AND reminder_sent_at IS NULL AND starts_at > ? AND starts_at <= ?
+		RETURNING *`,
+		now, StatusConfirmed, now, before,
+	).Scan(&appts).Error
+	return appts, err
+}
+
+// ExpireRequests closes requests nobody answered before their start time and returns them
+func (r *Repository) ExpireRequests(ctx context.Context, now time.Time) ([]Appointment, error) {
+	var appts []Appointment
+	err := r.db.WithContext(ctx).Raw(`
+		UPDATE appointments SET status = ?, updated_at = NOW()
+		WHERE status = ? AND starts_at <= ?
+		RETURNING *`,
+		StatusExpired, StatusRequested, now,
+	).Scan(&appts).Error
+	return appts, err
+}
+
+// lockUsers serializes bookings of the given users until the transaction
+// ends. Locks are taken in a fixed order so concurrent bookings cannot deadlock.
+func lockUsers(tx *gorm.DB, userIDs ...uuid.UUID) error {
+	keys := make([]string, len(userIDs))
+	for i, id := range userIDs {
+		keys[i] = id.String()
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext('appointments:' || ?))", key).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkWindow returns ErrOutsideAvailability unless the appointment is inside
+// one of the seller's windows for the listing
+func checkWindow(tx *gorm.DB, appt *Appointment) error {
+	var inside bool
+	if err := tx.Raw(`
+		SELECT EXISTS (
+			SELECT 1 FROM seller_availability
+			WHERE seller_id = ? AND (car_id IS NULL OR car_id = ?)
+			  AND starts_at <= ? AND ends_at >= ?
+		)`,
+		appt.SellerID, appt.CarID, appt.StartsAt, appt.EndsAt,
+	).Scan(&inside).Error; err != nil {
+		return err
+	}
+	if !inside {
+		return ErrOutsideAvailability
+	}
+	return nil
+}
+
+// checkConflicts returns ErrTimeConflict if either side has a confirmed
+// appointment overlapping this one, or the proposer has an open request of
+// their own at that time
+func checkConflicts(tx *gorm.DB, appt *Appointment) error {
+	var conflict bool
+	if err := tx.Raw(`
+		SELECT EXISTS (
+			SELECT 1 FROM appointments
+			WHERE id <> ? AND starts_at < ? AND ends_at > ?
+			  AND (
+				(status = ? AND (buyer_id IN (?, ?) OR seller_id IN (?, ?)))
+				OR (status = ? AND proposed_by = ?)
+			  )
+		)`,
+		appt.ID, appt.EndsAt, appt.StartsAt,
+		StatusConfirmed, appt.BuyerID, appt.SellerID, appt.BuyerID, appt.SellerID,
+		StatusRequested, appt.ProposedBy,
+	).Scan(&conflict).Error; err != nil {
+		return err
+	}
+	if conflict {
+		return ErrTimeConflict
+	}
+	return nil
+}
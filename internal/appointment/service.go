@@ -0,0 +1,582 @@
+package appointment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/yourusername/car-reselling-backend/internal/chat"
+	"github.com/yourusername/car-reselling-backend/internal/listing"
+	"github.com/yourusername/car-reselling-backend/internal/notification"
+	appErrors "github.com/yourusername/car-reselling-backend/pkg/errors"
+)
+
+const (
+	// DefaultDuration is the length of an appointment when none is given
+	DefaultDuration = 30 * time.Minute
+
+	// MinNotice is how far ahead an appointment must be booked or moved
+	MinNotice = time.Hour
+
+	// MaxAhead is how far ahead windows and appointments can be set
+	MaxAhead = 60 * 24 * time.Hour
+
+	// MaxWindowLength caps a single availability window
+	MaxWindowLength = 12 * time.Hour
+
+	// ReminderLead is how long before a confirmed appointment both sides are reminded
+	ReminderLead = time.Hour
+
+	// workerInterval is how often reminders and request expiry run
+	workerInterval = time.Minute
+)
+
+var (
+	// ErrInvalidTransition is returned when an appointment cannot take an action in its current status
+	ErrInvalidTransition = errors.New("appointment cannot be changed in its current state")
+	// ErrAppointmentStarted is returned when changing an appointment whose time has come
+	ErrAppointmentStarted = errors.New("appointment has already started")
+	// ErrInvalidWindow is returned for a window that ends before it starts or is too long
+	ErrInvalidWindow = fmt.Errorf("ends_at must be after starts_at and at most %d hours later", int(MaxWindowLength.Hours()))
+	// ErrWindowOverlap is returned when a window overlaps another of the seller's
+	ErrWindowOverlap = errors.New("availability window overlaps an existing one")
+	// ErrOutsideAvailability is returned when a buyer picks a time the seller is not available
+	ErrOutsideAvailability = errors.New("the seller is not available at that time")
+	// ErrTimeConflict is returned when the buyer or the seller already has an appointment at that time
+	ErrTimeConflict = errors.New("the time conflicts with another appointment")
+	// ErrOwnListing is returned when a seller books their own listing
+	ErrOwnListing = errors.New("you cannot book an appointment for your own listing")
+	// ErrListingUnavailable is returned when the listing can no longer be seen
+	ErrListingUnavailable = errors.New("listing is not available")
+	// ErrInvalidTime is returned for times in the past, too soon or too far ahead
+	ErrInvalidTime = fmt.Errorf("time must be between %d hour and %d days from now",
+		int(MinNotice.Hours()), int(MaxAhead.Hours()/24))
+)
+
+// NotificationSender is the subset of the notification service used to inform buyers and sellers
+type NotificationSender interface {
+	CreateAndSend(ctx context.Context, userID uuid.UUID, title, message, notifType, imageURL string, data map[string]interface{}) (*notification.Notification, error)
+}
+
+// ChatPoster posts appointment updates into the buyer and seller's conversation
+type ChatPoster interface {
+	PostCarMessage(msg chat.CarMessage) (uuid.UUID, error)
+}
+
+// Service handles seller availability and appointment booking
+type Service struct {
+	repo         *Repository
+	notification NotificationSender
+	chat         ChatPoster
+}
+
+// NewService creates a new appointment service
+func NewService(repo *Repository, notification NotificationSender) *Service {
+	return &Service{
+		repo:         repo,
+		notification: notification,
+	}
+}
+
+// SetChatPoster sets where appointment updates are posted
+func (s *Service) SetChatPoster(c ChatPoster) {
+	s.chat = c
+}
+
+// --- Availability ---
+
+// AddWindow adds a time the seller is available, for all their listings or one
+func (s *Service) AddWindow(ctx context.Context, sellerID uuid.UUID, req *CreateWindowRequest) (*Window, error) {
+	now := time.Now()
+	if !req.EndsAt.After(req.StartsAt) || req.EndsAt.Sub(req.StartsAt) > MaxWindowLength {
+		return nil, ErrInvalidWindow
+	}
+	if !req.EndsAt.After(now) || req.StartsAt.After(now.Add(MaxAhead)) {
+		return nil, ErrInvalidTime
+	}
+	if req.CarID != nil {
+		car, err := s.repo.FindCar(ctx, *req.CarID)
+		if err != nil {
+			return nil, err
+		}
+		if car.SellerID != sellerID {
+			return nil, appErrors.ErrNotFound
+		}
+	}
+
+	window := &Window{
+		SellerID:  sellerID,
+		CarID:     req.CarID,
+		StartsAt:  req.StartsAt,
+		EndsAt:    req.EndsAt,
+		CreatedAt: now,
+	}
+	if err := s.repo.CreateWindow(ctx, window); err != nil {
+		return nil, err
+	}
+	return window, nil
+}
+
+// GetMyWindows returns the seller's windows that have not ended
+func (s *Service) GetMyWindows(ctx context.Context, sellerID uuid.UUID) ([]Window, error) {
+	return s.repo.ListWindows(ctx, sellerID, nil, time.Now())
+}
+
+// DeleteWindow removes one of the seller's windows. Appointments already
+// booked in it are kept.
+func (s *Service) DeleteWindow(ctx context.Context, sellerID, windowID uuid.UUID) error {
+	deleted, err := s.repo.DeleteWindow(ctx, windowID, sellerID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return appErrors.ErrNotFound
+	}
+	return nil
+}
+
+// GetAvailability returns when a listing can be seen: the seller's windows for
+// it and the times already taken
+func (s *Service) GetAvailability(ctx context.Context, userID, carID uuid.UUID) (*AvailabilityResponse, error) {
+	car, err := s.repo.FindCar(ctx, carID)
+	if err != nil {
+		return nil, err
+	}
+	allowed, err := s.bookable(ctx, car, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, ErrListingUnavailable
+	}
+
+	now := time.Now()
+	windows, err := s.repo.ListWindows(ctx, car.SellerID, &carID, now)
+	if err != nil {
+		return nil, err
+	}
+	busy, err := s.repo.FindBusy(ctx, car.SellerID, now)
+	if err != nil {
+		return nil, err
+	}
+	return &AvailabilityResponse{Windows: windows, Busy: busy}, nil
+}
+
+// --- Appointments ---
+
+// Book requests a viewing or test drive inside one of the seller's windows
+func (s *Service) Book(ctx context.Context, buyerID, carID uuid.UUID, req *BookingRequest) (*Appointment, error) {
+	car, err := s.repo.FindCar(ctx, carID)
+	if err != nil {
+		return nil, err
+	}
+	if car.SellerID == buyerID {
+		return nil, ErrOwnListing
+	}
+	allowed, err := s.bookable(ctx, car, buyerID)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, ErrListingUnavailable
+	}
+
+	now := time.Now()
+	if !validStart(now, req.StartsAt) {
+		return nil, ErrInvalidTime
+	}
+	duration := DefaultDuration
+	if req.DurationMinutes > 0 {
+		duration = time.Duration(req.DurationMinutes) * time.Minute
+	}
+	appointmentType := req.Type
+	if appointmentType == "" {
+		appointmentType = TypeViewing
+	}
+
+	appt := &Appointment{
+		CarID:      carID,
+		BuyerID:    buyerID,
+		SellerID:   car.SellerID,
+		Type:       appointmentType,
+		StartsAt:   req.StartsAt,
+		EndsAt:     req.StartsAt.Add(duration),
+		Status:     StatusRequested,
+		ProposedBy: buyerID,
+		Note:       req.Note,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if err := s.repo.Create(ctx, appt); err != nil {
+		return nil, err
+	}
+
+	s.publish(ctx, car, appt, buyerID, appt.SellerID,
+		fmt.Sprintf("Requested a %s on %s", lowerLabel(appt.Type), formatTime(appt.StartsAt)),
+		"New appointment request",
+		fmt.Sprintf("A buyer asked for a %s of %s on %s", lowerLabel(appt.Type), car.Title, formatTime(appt.StartsAt)),
+		NotificationTypeAppointmentRequested)
+
+	return appt, nil
+}
+
+// List returns a user's appointments as buyer or seller, optionally filtered by status
+func (s *Service) List(ctx context.Context, userID uuid.UUID, role, status string, page, limit int) (*AppointmentListResponse, error) {
+	appts, total, err := s.repo.List(ctx, userID, role, status, page, limit)
+	if err != nil {
+		return nil, err
+	}
+	return &AppointmentListResponse{Items: appts, Total: total, Page: page, Limit: limit}, nil
+}
+
+// Get returns an appointment the user is a side of
+func (s *Service) Get(ctx context.Context, userID, appointmentID uuid.UUID) (*Appointment, error) {
+	appt, _, err := s.load(ctx, userID, appointmentID)
+	return appt, err
+}
+
+// Calendar returns an appointment as an iCalendar file
+func (s *Service) Calendar(ctx context.Context, userID, appointmentID uuid.UUID) ([]byte, error) {
+	appt, car, err := s.load(ctx, userID, appointmentID)
+	if err != nil {
+		return nil, err
+	}
+	return Calendar(appt, car), nil
+}
+
+// Confirm accepts the proposed time. Other requests at that time for either
+// side are declined.
+func (s *Service) Confirm(ctx context.Context, userID, appointmentID uuid.UUID) (*Appointment, error) {
+	appt, car, err := s.prepare(ctx, userID, appointmentID, ActionConfirm)
+	if err != nil {
+		return nil, err
+	}
+
+	declined, err := s.repo.Confirm(ctx, appt)
+	if err != nil {
+		return nil, err
+	}
+	appt.Status = StatusConfirmed
+	appt.UpdatedAt = time.Now()
+
+	s.publish(ctx, car, appt, userID, appt.ProposedBy,
+		fmt.Sprintf("Confirmed the %s on %s", lowerLabel(appt.Type), formatTime(appt.StartsAt)),
+		"Appointment confirmed ✅",
+		fmt.Sprintf("Your %s of %s on %s is confirmed", lowerLabel(appt.Type), car.Title, formatTime(appt.StartsAt)),
+		NotificationTypeAppointmentConfirmed)
+
+	for i := range declined {
+		other := &declined[i]
+		otherCar := car
+		if other.CarID != car.ID {
+			if otherCar, err = s.repo.FindCar(ctx, other.CarID); err != nil {
+				log.Printf("Failed to load car %s for declined appointment %s: %v", other.CarID, other.ID, err)
+				continue
+			}
+		}
+		s.publish(ctx, otherCar, other, counterpart(other, other.ProposedBy), other.ProposedBy,
+			fmt.Sprintf("The %s on %s is no longer possible, that time was booked", lowerLabel(other.Type), formatTime(other.StartsAt)),
+			"Appointment declined",
+			fmt.Sprintf("The %s of %s on %s is no longer possible, that time was booked", lowerLabel(other.Type), otherCar.Title, formatTime(other.StartsAt)),
+			NotificationTypeAppointmentDeclined)
+	}
+
+	return appt, nil
+}
+
+// Decline turns down the proposed time
+func (s *Service) Decline(ctx context.Context, userID, appointmentID uuid.UUID, reason string) (*Appointment, error) {
+	appt, car, err := s.close(ctx, userID, appointmentID, ActionDecline, reason)
+	if err != nil {
+		return nil, err
+	}
+
+	content := fmt.Sprintf("Declined the %s on %s", lowerLabel(appt.Type), formatTime(appt.StartsAt))
+	if reason != "" {
+		content += ": " + reason
+	}
+	s.publish(ctx, car, appt, userID, appt.ProposedBy, content,
+		"Appointment declined",
+		fmt.Sprintf("Your %s request for %s on %s was declined", lowerLabel(appt.Type), car.Title, formatTime(appt.StartsAt)),
+		NotificationTypeAppointmentDeclined)
+	return appt, nil
+}
+
+// Cancel calls off a requested or confirmed appointment
+func (s *Service) Cancel(ctx context.Context, userID, appointmentID uuid.UUID) (*Appointment, error) {
+	appt, car, err := s.close(ctx, userID, appointmentID, ActionCancel, "")
+	if err != nil {
+		return nil, err
+	}
+
+	s.publish(ctx, car, appt, userID, counterpart(appt, userID),
+		fmt.Sprintf("Cancelled the %s on %s", lowerLabel(appt.Type), formatTime(appt.StartsAt)),
+		"Appointment cancelled",
+		fmt.Sprintf("The %s of %s on %s was cancelled", lowerLabel(appt.Type), car.Title, formatTime(appt.StartsAt)),
+		NotificationTypeAppointmentCancelled)
+	return appt, nil
+}
+
+// Reschedule proposes a new time, which the other side then confirms or
+// declines. Buyers must pick a time inside the seller's availability.
+func (s *Service) Reschedule(ctx context.Context, userID, appointmentID uuid.UUID, req *RescheduleRequest) (*Appointment, error) {
+	appt, car, err := s.prepare(ctx, userID, appointmentID, ActionReschedule)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if !validStart(now, req.StartsAt) {
+		return nil, ErrInvalidTime
+	}
+
+	duration := appt.EndsAt.Sub(appt.StartsAt)
+	if req.DurationMinutes > 0 {
+		duration = time.Duration(req.DurationMinutes) * time.Minute
+	}
+	previous := appt.StartsAt
+	from := appt.Status
+
+	appt.StartsAt = req.StartsAt
+	appt.EndsAt = req.StartsAt.Add(duration)
+	appt.Status = StatusRequested
+	appt.ProposedBy = userID
+	appt.Sequence++
+	appt.ReminderSentAt = nil
+	appt.UpdatedAt = now
+
+	if err := s.repo.Reschedule(ctx, appt, from, userID == appt.BuyerID); err != nil {
+		return nil, err
+	}
+
+	s.publish(ctx, car, appt, userID, counterpart(appt, userID),
+		fmt.Sprintf("Proposed moving the %s from %s to %s", lowerLabel(appt.Type), formatTime(previous), formatTime(appt.StartsAt)),
+		"Appointment rescheduled",
+		fmt.Sprintf("The %s of %s was moved to %s, please confirm", lowerLabel(appt.Type), car.Title, formatTime(appt.StartsAt)),
+		NotificationTypeAppointmentRescheduled)
+	return appt, nil
+}
+
+// load retrieves an appointment the user is a side of, with its listing.
+// Appointments are hidden from everyone else.
+func (s *Service) load(ctx context.Context, userID, appointmentID uuid.UUID) (*Appointment, *Car, error) {
+	appt, err := s.repo.FindByID(ctx, appointmentID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if userID != appt.BuyerID && userID != appt.SellerID {
+		return nil, nil, appErrors.ErrNotFound
+	}
+
+	car, err := s.repo.FindCar(ctx, appt.CarID)
+	if err != nil {
+		return nil, nil, err
+	}
+	appt.CarTitle = car.Title
+	return appt, car, nil
+}
+
+// prepare loads an appointment and checks the user can take the action on it
+func (s *Service) prepare(ctx context.Context, userID, appointmentID uuid.UUID, action string) (*Appointment, *Car, error) {
+	appt, car, err := s.load(ctx, userID, appointmentID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, err := transition(appt.Status, userID == appt.ProposedBy, action); err != nil {
+		return nil, nil, err
+	}
+	if !time.Now().Before(appt.StartsAt) {
+		return nil, nil, ErrAppointmentStarted
+	}
+	return appt, car, nil
+}
+
+// close declines or cancels an appointment
+func (s *Service) close(ctx context.Context, userID, appointmentID uuid.UUID, action, reason string) (*Appointment, *Car, error) {
+	appt, car, err := s.prepare(ctx, userID, appointmentID, action)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	from := appt.Status
+	appt.Status, _ = transition(from, userID == appt.ProposedBy, action)
+	appt.DeclineReason = reason
+	appt.UpdatedAt = time.Now()
+
+	closed, err := s.repo.Close(ctx, appt, from)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !closed {
+		return nil, nil, ErrInvalidTransition
+	}
+	return appt, car, nil
+}
+
+// --- Reminders ---
+
+// RunWorker sends reminders before confirmed appointments and expires
+// unanswered requests. Blocks forever; run in a goroutine.
+func (s *Service) RunWorker() {
+	ticker := time.NewTicker(workerInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		ctx := context.Background()
+		s.SendReminders(ctx)
+		s.ExpireRequests(ctx)
+	}
+}
+
+// SendReminders reminds both sides of confirmed appointments starting within ReminderLead
+func (s *Service) SendReminders(ctx context.Context) {
+	now := time.Now()
+	appts, err := s.repo.ClaimReminders(ctx, now, now.Add(ReminderLead))
+	if err != nil {
+		log.Printf("Failed to claim appointment reminders: %v", err)
+		return
+	}
+
+	for i := range appts {
+		appt := &appts[i]
+		car, err := s.repo.FindCar(ctx, appt.CarID)
+		if err != nil {
+			log.Printf("Failed to load car %s for appointment reminder %s: %v", appt.CarID, appt.ID, err)
+			continue
+		}
+
+		body := fmt.Sprintf("%s of %s at %s", typeLabel(appt.Type), car.Title, formatTime(appt.StartsAt))
+		if location := car.Location(); location != "" {
+			body += " in " + location
+		}
+		for _, userID := range []uuid.UUID{appt.BuyerID, appt.SellerID} {
+			s.notify(ctx, car, appt, userID, "Appointment in one hour ⏰", body, NotificationTypeAppointmentReminder)
+		}
+	}
+	if len(appts) > 0 {
+		log.Printf("Sent %d appointment reminders", len(appts))
+	}
+}
+
+// ExpireRequests closes requests nobody answered before their start time
+func (s *Service) ExpireRequests(ctx context.Context) {
+	appts, err := s.repo.ExpireRequests(ctx, time.Now())
+	if err != nil {
+		log.Printf("Failed to expire appointment requests: %v", err)
+		return
+	}
+
+	for i := range appts {
+		appt := &appts[i]
+		car, err := s.repo.FindCar(ctx, appt.CarID)
+		if err != nil {
+			log.Printf("Failed to load car %s for expired appointment %s: %v", appt.CarID, appt.ID, err)
+			continue
+		}
+		s.publish(ctx, car, appt, counterpart(appt, appt.ProposedBy), appt.ProposedBy,
+			fmt.Sprintf("The %s request for %s expired without an answer", lowerLabel(appt.Type), formatTime(appt.StartsAt)),
+			"Appointment request expired",
+			fmt.Sprintf("Your %s request for %s on %s was not answered in time", lowerLabel(appt.Type), car.Title, formatTime(appt.StartsAt)),
+			NotificationTypeAppointmentExpired)
+	}
+	if len(appts) > 0 {
+		log.Printf("Expired %d appointment requests", len(appts))
+	}
+}
+
+// --- Helpers ---
+
+// publish posts an appointment update into the buyer and seller's
+// conversation as a system message from the acting user, and notifies recipientID
+func (s *Service) publish(ctx context.Context, car *Car, appt *Appointment, actorID, recipientID uuid.UUID, content, title, body, notifType string) {
+	if s.chat != nil {
+		data := appointmentData(appt)
+		data["event"] = notifType
+		_, err := s.chat.PostCarMessage(chat.CarMessage{
+			CarID:       car.ID,
+			CarTitle:    car.Title,
+			SellerID:    appt.SellerID,
+			BuyerID:     appt.BuyerID,
+			SenderID:    actorID,
+			MessageType: chat.MessageTypeSystem,
+			Content:     content,
+			Data:        data,
+		})
+		if err != nil {
+			log.Printf("Failed to post appointment %s message: %v", appt.ID, err)
+		}
+	}
+	s.notify(ctx, car, appt, recipientID, title, body, notifType)
+}
+
+// notify sends an appointment notification to one side
+func (s *Service) notify(ctx context.Context, car *Car, appt *Appointment, userID uuid.UUID, title, body, notifType string) {
+	if s.notification == nil {
+		return
+	}
+	data := appointmentData(appt)
+	data["car_title"] = car.Title
+	if _, err := s.notification.CreateAndSend(ctx, userID, title, body, notifType, car.Image, data); err != nil {
+		log.Printf("Failed to send %s notification for appointment %s: %v", notifType, appt.ID, err)
+	}
+}
+
+// appointmentData is the structured form of an appointment in chat messages and notifications
+func appointmentData(appt *Appointment) map[string]interface{} {
+	data := map[string]interface{}{
+		"appointment_id": appt.ID.String(),
+		"car_id":         appt.CarID.String(),
+		"type":           appt.Type,
+		"status":         appt.Status,
+		"starts_at":      appt.StartsAt,
+		"ends_at":        appt.EndsAt,
+		"proposed_by":    appt.ProposedBy.String(),
+	}
+	if appt.DeclineReason != "" {
+		data["decline_reason"] = appt.DeclineReason
+	}
+	return data
+}
+
+// bookable reports whether a user can see a listing's availability and book
+// it. Active listings are open to everyone; reserved ones only to the seller
+// and to a buyer whose offer on the car was accepted.
+func (s *Service) bookable(ctx context.Context, car *Car, userID uuid.UUID) (bool, error) {
+	switch car.Status {
+	case listing.CarStatusActive:
+		return true, nil
+	case listing.CarStatusReserved:
+		if userID == car.SellerID {
+			return true, nil
+		}
+		return s.repo.HasAcceptedOffer(ctx, car.ID, userID)
+	}
+	return false, nil
+}
+
+// validStart reports whether an appointment can start at t
+func validStart(now, t time.Time) bool {
+	return !t.Before(now.Add(MinNotice)) && !t.After(now.Add(MaxAhead))
+}
+
+// counterpart returns the other side of an appointment
+func counterpart(appt *Appointment, userID uuid.UUID) uuid.UUID {
+	if userID == appt.SellerID {
+		return appt.BuyerID
+	}
+	return appt.SellerID
+}
+
+// formatTime formats an appointment time for notifications and chat messages.
+// The data carries the exact time for clients to show in the user's zone.
+func formatTime(t time.Time) string {
+	return t.UTC().Format("Mon 2 Jan at 15:04 UTC")
+}
+
+func lowerLabel(appointmentType string) string {
+	return strings.ToLower(typeLabel(appointmentType))
+}
@@ -0,0 +1,25 @@
+package audit
+
+import "time"
+
+// ListEventsQuery filters the audit log
+// @Description Audit log filters; all are optional
+type ListEventsQuery struct {
+	Page       int        `form:"page,default=1" binding:"min=1" example:"1"`
+	Limit      int        `form:"limit,default=50" binding:"min=1,max=200" example:"50"`
+	ActorID    string     `form:"actor_id" binding:"omitempty,uuid" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Action     string     `form:"action" binding:"omitempty,max=64" example:"auth.login"` // Exact action, or a prefix ending in "." (e.g. "listing.")
+	TargetType string     `form:"target_type" binding:"omitempty,max=32" example:"listing"`
+	TargetID   string     `form:"target_id" binding:"omitempty,max=64" example:"550e8400-e29b-41d4-a716-446655440000"`
+	From       *time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00" example:"2024-01-01T00:00:00Z"`
+	To         *time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00" example:"2024-02-01T00:00:00Z"`
+}
+
+// EventListResponse is a page of audit events, newest first
+// @Description Paginated audit events
+type EventListResponse struct {
+	Events []Event `json:"events"`
+	Total  int64   `json:"total" example:"120"`
+	Page   int     `json:"page" example:"1"`
+	Limit  int     `json:"limit" example:"50"`
+}
@@ -0,0 +1,102 @@
+package audit
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	appErrors "github.com/yourusername/car-reselling-backend/pkg/errors"
+)
+
+// Handler handles HTTP requests for the audit log
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a new audit handler
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// RegisterRoutes registers the self-service audit route
+func (h *Handler) RegisterRoutes(router *gin.RouterGroup, authMiddleware gin.HandlerFunc) {
+	router.GET("/auth/me/audit-events", authMiddleware, h.ListMyEvents)
+}
+
+// RegisterAdminRoutes registers the audit log route on an admin-only group
+func (h *Handler) RegisterAdminRoutes(admin *gin.RouterGroup) {
+	admin.GET("/audit-events", h.ListEvents)
+}
+
+// ListMyEvents lists security events of the current user
+// @Summary List my security events
+// @Description Actions the current user performed, and events on their account such as failed logins and admin changes, newest first
+// @Tags auth
+// @Security BearerAuth
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(50)
+// @Param action query string false "Action, or a prefix ending in '.' (e.g. auth.)"
+// @Param target_type query string false "Target type" Enums(user, session, listing, device)
+// @Param from query string false "From (RFC 3339)"
+// @Param to query string false "To, exclusive (RFC 3339)"
+// @Success 200 {object} EventListResponse
+// @Failure 400 {object} appErrors.ErrorResponse
+// @Failure 401 {object} appErrors.ErrorResponse
+// @Router /api/auth/me/audit-events [get]
+func (h *Handler) ListMyEvents(c *gin.Context) {
+	userID, err := uuid.Parse(c.GetString("userID"))
+	if err != nil {
+		appErrors.HandleError(c, appErrors.ErrUnauthorized)
+		return
+	}
+
+	var query ListEventsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		appErrors.HandleErrorWithMessage(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	events, err := h.service.ListForUser(c.Request.Context(), userID, &query)
+	if err != nil {
+		appErrors.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, events)
+}
+
+// ListEvents lists audit events
+// @Summary List audit events
+// @Description Search the audit log, newest first (admin only)
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(50)
+// @Param actor_id query string false "User who performed the action"
+// @Param action query string false "Action, or a prefix ending in '.' (e.g. listing.)"
+// @Param target_type query string false "Target type" Enums(user, session, listing, device)
+// @Param target_id query string false "Target ID"
+// @Param from query string false "From (RFC 3339)"
+// @Param to query string false "To, exclusive (RFC 3339)"
+// @Success 200 {object} EventListResponse
+// @Failure 400 {object} appErrors.ErrorResponse
+// @Failure 403 {object} appErrors.ErrorResponse
+// @Router /api/admin/audit-events [get]
+func (h *Handler) ListEvents(c *gin.Context) {
+	var query ListEventsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		appErrors.HandleErrorWithMessage(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	events, err := h.service.List(c.Request.Context(), &query)
+	if err != nil {
+		appErrors.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, events)
+}
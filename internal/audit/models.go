@@ -0,0 +1,125 @@
+package audit
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Actions
+const (
+	ActionLogin                    = "auth.login"
+	ActionLoginFailed              = "auth.login_failed"
+	ActionAccountLocked            = "auth.account_locked"
+	ActionLogout                   = "auth.logout"
+	ActionSessionRevoked           = "auth.session_revoked"
+	ActionPasswordChanged          = "auth.password_changed"
+	ActionPasswordReset            = "auth.password_reset"
+	ActionProfileUpdated           = "auth.profile_updated"
+	ActionEmailVerified            = "auth.email_verified"
+	ActionTwoFactorEnabled         = "auth.2fa_enabled"
+	ActionTwoFactorDisabled        = "auth.2fa_disabled"
+	ActionRecoveryCodesRegenerated = "auth.recovery_codes_regenerated"
+
+	ActionUserRoleChanged   = "admin.user_role_changed"
+	ActionUserStatusChanged = "admin.user_status_changed"
+	ActionUserUnlocked      = "admin.user_unlocked"
+
+	ActionDealerVerificationReviewed = "admin.dealer_verification_reviewed"
+	ActionPlanUpdated                = "admin.plan_updated"
+	ActionUserPlanChanged            = "admin.user_plan_changed"
+	ActionPaymentRefunded            = "admin.payment_refunded"
+
+	ActionListingCreated      = "listing.created"
+	ActionListingUpdated      = "listing.updated"
+	ActionListingPriceChanged = "listing.price_changed"
+	ActionListingRenewed      = "listing.renewed"
+	ActionListingDeleted      = "listing.deleted"
+	ActionListingBoosted      = "listing.boosted"
+
+	ActionPlanPurchased = "payment.plan_purchased"
+
+	ActionDeviceRegistered   = "chat.device_registered"
+	ActionDeviceUnregistered = "chat.device_unregistered"
+)
+
+// Target types
+const (
+	TargetUser    = "user"
+	TargetSession = "session"
+	TargetListing = "listing"
+	TargetDevice  = "device"
+	TargetPlan    = "plan"
+	TargetPayment = "payment"
+)
+
+// Event is one entry in the audit log. Events are never updated or deleted.
+type Event struct {
+	ID         uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ActorID    *uuid.UUID `gorm:"type:uuid" json:"actor_id"` // Nil for anonymous requests and the system
+	Action     string     `gorm:"not null" json:"action"`
+	TargetType string     `json:"target_type"`
+	TargetID   string     `json:"target_id"`
+	IPAddress  string     `json:"ip_address"`
+	UserAgent  string     `json:"user_agent"`
+	Changes    Changes    `gorm:"type:jsonb" json:"changes,omitempty" swaggertype:"object"`
+	Metadata   Metadata   `gorm:"type:jsonb" json:"metadata,omitempty" swaggertype:"object"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// TableName overrides the table name
+func (Event) TableName() string {
+	return "audit_events"
+}
+
+// Change is the value of a field before and after an action
+type Change struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// Changes maps field names to their change
+type Changes map[string]Change
+
+// Value implements driver.Valuer interface for GORM
+func (c Changes) Value() (driver.Value, error) {
+	if c == nil {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(c)
+}
+
+// Scan implements sql.Scanner interface for GORM
+func (c *Changes) Scan(value interface{}) error {
+	return scanJSON(value, c)
+}
+
+// Metadata holds extra details about an event
+type Metadata map[string]interface{}
+
+// Value implements driver.Valuer interface for GORM
+func (m Metadata) Value() (driver.Value, error) {
+	if m == nil {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(m)
+}
+
+// Scan implements sql.Scanner interface for GORM
+func (m *Metadata) Scan(value interface{}) error {
+	return scanJSON(value, m)
+}
+
+func scanJSON(value interface{}, dest interface{}) error {
+	if value == nil {
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("failed to unmarshal JSONB value: expected []byte")
+	}
+	return json.Unmarshal(bytes, dest)
+}
@@ -0,0 +1,68 @@
+package audit
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Repository handles database operations for the audit log
+type Repository struct {
+	db *gorm.DB
+}
+
+// NewRepository creates a new audit repository
+func NewRepository(db *gorm.DB) *Repository {
+	return &Repository{db: db}
+}
+
+// Create appends an event
+func (r *Repository) Create(ctx context.Context, event *Event) error {
+	return r.db.WithContext(ctx).Create(event).Error
+}
+
+// List retrieves events matching the query, newest first. With a subject, only
+// events the subject performed or that target their account are included.
+func (r *Repository) List(ctx context.Context, q *ListEventsQuery, subject *uuid.UUID) ([]Event, int64, error) {
+	query := r.db.WithContext(ctx).Model(&Event{})
+
+	if subject != nil {
+		query = query.Where("(actor_id = ? OR (target_type = ? AND target_id = ?))", *subject, TargetUser, subject.String())
+	}
+	if q.ActorID != "" {
+		query = query.Where("actor_id = ?", q.ActorID)
+	}
+	if q.Action != "" {
+		if strings.HasSuffix(q.Action, ".") {
+			query = query.Where("action LIKE ?", q.Action+"%")
+		} else {
+			query = query.Where("action = ?", q.Action)
+		}
+	}
+	if q.TargetType != "" {
+		query = query.Where("target_type = ?", q.TargetType)
+	}
+	if q.TargetID != "" {
+		query = query.Where("target_id = ?", q.TargetID)
+	}
+	if q.From != nil {
+		query = query.Where("created_at >= ?", *q.From)
+	}
+	if q.To != nil {
+		query = query.Where("created_at < ?", *q.To)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	events := []Event{}
+	err := query.Order("created_at DESC").
+		Offset((q.Page - 1) * q.Limit).
+		Limit(q.Limit).
+		Find(&events).Error
+	return events, total, err
+}
@@ -0,0 +1,130 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"reflect"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Service records and queries the audit log
+type Service struct {
+	repo *Repository
+}
+
+// NewService creates a new audit service
+func NewService(repo *Repository) *Service {
+	return &Service{repo: repo}
+}
+
+// Record appends an event. The IP address and user agent are taken from the
+// request context unless set. Failures are logged, not returned, so auditing
+// never breaks the action being audited.
+func (s *Service) Record(ctx context.Context, event Event) {
+	if info, ok := ctx.Value(requestInfoKey{}).(requestInfo); ok {
+		if event.IPAddress == "" {
+			event.IPAddress = info.ipAddress
+		}
+		if event.UserAgent == "" {
+			event.UserAgent = info.userAgent
+		}
+	}
+	if event.ID == uuid.Nil {
+		event.ID = uuid.New()
+	}
+
+	// Record even if the client has gone away
+	if err := s.repo.Create(context.WithoutCancel(ctx), &event); err != nil {
+		log.Printf("Failed to record audit event %s (actor %v, target %s/%s): %v",
+			event.Action, event.ActorID, event.TargetType, event.TargetID, err)
+	}
+}
+
+// List returns events matching the query (admin)
+func (s *Service) List(ctx context.Context, q *ListEventsQuery) (*EventListResponse, error) {
+	return s.list(ctx, q, nil)
+}
+
+// ListForUser returns events the user performed or that target their account
+func (s *Service) ListForUser(ctx context.Context, userID uuid.UUID, q *ListEventsQuery) (*EventListResponse, error) {
+	return s.list(ctx, q, &userID)
+}
+
+func (s *Service) list(ctx context.Context, q *ListEventsQuery, subject *uuid.UUID) (*EventListResponse, error) {
+	events, total, err := s.repo.List(ctx, q, subject)
+	if err != nil {
+		return nil, err
+	}
+	return &EventListResponse{
+		Events: events,
+		Total:  total,
+		Page:   q.Page,
+		Limit:  q.Limit,
+	}, nil
+}
+
+// Diff returns the fields that differ between two values of the same type,
+// keyed by JSON name. Fields hidden from JSON (such as password hashes) never
+// show up.
+func Diff(before, after interface{}) Changes {
+	b, errB := toMap(before)
+	a, errA := toMap(after)
+	if errB != nil || errA != nil {
+		return nil
+	}
+
+	changes := Changes{}
+	for key, old := range b {
+		if !reflect.DeepEqual(old, a[key]) {
+			changes[key] = Change{Before: old, After: a[key]}
+		}
+	}
+	for key, value := range a {
+		if _, ok := b[key]; !ok {
+			changes[key] = Change{Before: nil, After: value}
+		}
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+	return changes
+}
+
+func toMap(v interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	m := map[string]interface{}{}
+	err = json.Unmarshal(data, &m)
+	return m, err
+}
+
+// requestInfo is what Middleware stores about the request
+type requestInfo struct {
+	ipAddress string
+	userAgent string
+}
+
+type requestInfoKey struct{}
+
+// Middleware makes the client IP and user agent available to Record through
+// the request context
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := context.WithValue(c.Request.Context(), requestInfoKey{}, requestInfo{
+			ipAddress: c.ClientIP(),
+			userAgent: c.Request.UserAgent(),
+		})
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// ActorID is a helper for Event.ActorID
+func ActorID(id uuid.UUID) *uuid.UUID {
+	return &id
+}
@@ -0,0 +1,33 @@
+package audit
+
+import "testing"
+
+func TestDiff(t *testing.T) {
+	type profile struct {
+		Name     string `json:"name"`
+		City     string `json:"city"`
+		Verified bool   `json:"verified"`
+		Password string `json:"-"`
+	}
+
+	before := profile{Name: "Ana", City: "Pune", Password: "old"}
+	after := profile{Name: "Ana", City: "Mumbai", Verified: true, Password: "new"}
+
+	changes := Diff(before, after)
+	if len(changes) != 2 {
+		t.Fatalf("expected 2 changes, got %v", changes)
+	}
+	if c := changes["city"]; c.Before != "Pune" || c.After != "Mumbai" {
+		t.Errorf("unexpected city change: %+v", c)
+	}
+	if c := changes["verified"]; c.Before != false || c.After != true {
+		t.Errorf("unexpected verified change: %+v", c)
+	}
+	if _, ok := changes["name"]; ok {
+		t.Error("unchanged field reported")
+	}
+
+	if changes := Diff(before, before); changes != nil {
+		t.Errorf("expected no changes, got %v", changes)
+	}
+}
@@ -16,12 +16,14 @@ type RegisterRequest struct {
 type LoginRequest struct {
 	EmailOrPhone string `json:"email_or_phone" binding:"required" example:"user@example.com"`
 	Password     string `json:"password" binding:"required" example:"SecurePass123"`
+	DeviceName   string `json:"device_name" binding:"omitempty,max=100" example:"Pixel 8"` // Shown in the session list
 }
 
 // SendOTPRequest represents the send OTP request
 // @Description Request to send OTP to phone number
 type SendOTPRequest struct {
-	Phone string `json:"phone" binding:"required" example:"+1234567890"`
+	Phone  string `json:"phone" binding:"required" example:"+1234567890"`
+	Locale string `json:"locale" binding:"omitempty,max=35" example:"es"` // SMS language; defaults to Accept-Language, then English
 }
 
 // VerifyOTPRequest represents the verify OTP request
@@ -31,6 +33,34 @@ type VerifyOTPRequest struct {
 	Code  string `json:"code" binding:"required,len=6" example:"123456"`
 }
 
+// ForgotPasswordRequest represents the request to start a password reset
+// @Description Request a password reset OTP for the account with this email or phone
+type ForgotPasswordRequest struct {
+	EmailOrPhone string `json:"email_or_phone" binding:"required" example:"user@example.com"`
+	Locale       string `json:"locale" binding:"omitempty,max=35" example:"es"` // SMS language; defaults to Accept-Language, then English
+}
+
+// VerifyResetOTPRequest represents the request to verify a password reset OTP
+// @Description Request to exchange a password reset OTP for a reset token
+type VerifyResetOTPRequest struct {
+	EmailOrPhone string `json:"email_or_phone" binding:"required" example:"user@example.com"`
+	Code         string `json:"code" binding:"required,len=6" example:"123456"`
+}
+
+// ResetTokenResponse represents a single-use password reset token
+// @Description Reset token to use with the reset-password endpoint
+type ResetTokenResponse struct {
+	ResetToken string `json:"reset_token" example:"9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"`
+	ExpiresIn  int64  `json:"expires_in" example:"900"` // Token lifetime in seconds
+}
+
+// ResetPasswordRequest represents the request to set a new password with a reset token
+// @Description Request to set a new password using a reset token
+type ResetPasswordRequest struct {
+	ResetToken  string `json:"reset_token" binding:"required" example:"9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"`
+	NewPassword string `json:"new_password" binding:"required,min=8" example:"NewPassword123!"`
+}
+
 // RefreshTokenRequest represents the refresh token request
 // @Description Request to refresh access token
 type RefreshTokenRequest struct {
@@ -42,21 +72,84 @@ type RefreshTokenRequest struct {
 type AuthResponse struct {
 	AccessToken  string  `json:"access_token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
 	RefreshToken string  `json:"refresh_token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	ExpiresIn    int64   `json:"expires_in" example:"900"` // Access token lifetime in seconds
 	User         UserDTO `json:"user"`
 }
 
+// TwoFactorChallengeResponse is returned by login when the account has 2FA on
+// @Description Password accepted; complete the login with a TOTP or recovery code
+type TwoFactorChallengeResponse struct {
+	TwoFactorRequired bool   `json:"two_factor_required" example:"true"`
+	ChallengeToken    string `json:"challenge_token" example:"3f7a0c2e9b5d4e8f1a6c0b2d9e7f5a3c1b8d6e4f2a0c9b7d5e3f1a8c6b4d2e0f"`
+	ExpiresIn         int64  `json:"expires_in" example:"300"` // Challenge lifetime in seconds
+}
+
+// TwoFactorLoginRequest represents the second step of a 2FA login
+// @Description Challenge token from login and a TOTP or recovery code
+type TwoFactorLoginRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required" example:"3f7a0c2e9b5d4e8f1a6c0b2d9e7f5a3c1b8d6e4f2a0c9b7d5e3f1a8c6b4d2e0f"`
+	Code           string `json:"code" binding:"required,max=20" example:"123456"` // 6-digit TOTP code or a recovery code
+}
+
+// TwoFactorSetupResponse represents a pending TOTP enrollment
+// @Description Secret to add to an authenticator app, as text, otpauth URI or QR code
+type TwoFactorSetupResponse struct {
+	Secret     string `json:"secret" example:"JBSWY3DPEHPK3PXP"`
+	OTPAuthURI string `json:"otpauth_uri" example:"otpauth://totp/Car%20Reselling:user@example.com?issuer=Car%20Reselling&secret=JBSWY3DPEHPK3PXP"`
+	QRCode     string `json:"qr_code" example:"data:image/png;base64,iVBORw0KGgo..."` // PNG data URI of the otpauth URI
+	ExpiresIn  int64  `json:"expires_in" example:"600"`                               // Seconds left to confirm the setup
+}
+
+// TwoFactorCodeRequest represents a request confirmed with a TOTP code
+// @Description Current code from the authenticator app
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required,len=6" example:"123456"`
+}
+
+// DisableTwoFactorRequest represents the request to turn off 2FA
+// @Description Password and a TOTP or recovery code
+type DisableTwoFactorRequest struct {
+	Password string `json:"password" binding:"required" example:"SecurePass123"`
+	Code     string `json:"code" binding:"required,max=20" example:"123456"` // 6-digit TOTP code or a recovery code
+}
+
+// RecoveryCodesResponse lists newly generated recovery codes
+// @Description Single-use recovery codes. They are shown only once; store them safely.
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes" example:"k7m2p-x9q4r,a3b5c-d7e9f"`
+}
+
+// TokenResponse represents a rotated token pair
+// @Description New access token and the refresh token to use next time (the old one is no longer valid)
+type TokenResponse struct {
+	AccessToken  string `json:"access_token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	RefreshToken string `json:"refresh_token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	ExpiresIn    int64  `json:"expires_in" example:"900"` // Access token lifetime in seconds
+}
+
+// RevokeSessionsResponse reports how many sessions were logged out
+// @Description Number of revoked sessions
+type RevokeSessionsResponse struct {
+	Revoked int `json:"revoked" example:"2"`
+}
+
 // UserDTO represents user data in API responses
 // @Description User information in API responses
 type UserDTO struct {
-	ID              string  `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
-	Email           string  `json:"email" example:"user@example.com"`
-	Phone           string  `json:"phone" example:"+1234567890"`
-	FullName        string  `json:"full_name" example:"John Doe"`
-	Gender          *string `json:"gender" example:"male"`
-	DOB             *string `json:"dob" example:"1990-01-01T00:00:00Z"`
-	ProfilePhotoURL *string `json:"profile_photo_url" example:"https://example.com/photo.jpg"`
-	IsVerified      bool    `json:"is_verified" example:"true"`
-	IsDealer        bool    `json:"is_dealer" example:"false"`
+	ID               string  `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Email            string  `json:"email" example:"user@example.com"`
+	Phone            string  `json:"phone" example:"+1234567890"`
+	FullName         string  `json:"full_name" example:"John Doe"`
+	Gender           *string `json:"gender" example:"male"`
+	DOB              *string `json:"dob" example:"1990-01-01T00:00:00Z"`
+	ProfilePhotoURL  *string `json:"profile_photo_url" example:"https://example.com/photo.jpg"`
+	IsVerified       bool    `json:"is_verified" example:"true"`
+	EmailVerified    bool    `json:"email_verified" example:"false"`
+	TwoFactorEnabled bool    `json:"two_factor_enabled" example:"false"`
+	IsDealer         bool    `json:"is_dealer" example:"false"`
+	Role             string  `json:"role" example:"user"`
+
+	DeletionScheduledAt *string `json:"deletion_scheduled_at,omitempty" example:"2024-02-01T00:00:00Z"` // Set while account deletion is pending
 }
 
 // UpdateProfileRequest represents the update profile request
@@ -80,3 +173,43 @@ type ChangePasswordRequest struct {
 	CurrentPassword string `json:"current_password" binding:"required" example:"OldPassword123!"`
 	NewPassword     string `json:"new_password" binding:"required,min=8" example:"NewPassword123!"`
 }
+
+// ListUsersQuery represents the query parameters for listing users (admin)
+// @Description Filters for the admin user list
+type ListUsersQuery struct {
+	Page   int    `form:"page,default=1" binding:"min=1" example:"1"`
+	Limit  int    `form:"limit,default=20" binding:"min=1,max=100" example:"20"`
+	Query  string `form:"q" binding:"omitempty,max=100" example:"john"` // Matches name, email or phone
+	Role   string `form:"role" binding:"omitempty,oneof=user dealer moderator admin" example:"dealer"`
+	Active *bool  `form:"is_active" example:"true"`
+}
+
+// AdminUserDTO represents user data in admin responses
+// @Description User information including account status
+type AdminUserDTO struct {
+	UserDTO
+	IsActive    bool    `json:"is_active" example:"true"`
+	CreatedAt   string  `json:"created_at" example:"2024-01-01T00:00:00Z"`
+	LastLoginAt *string `json:"last_login_at" example:"2024-01-02T00:00:00Z"`
+}
+
+// UserListResponse represents a paginated list of users
+// @Description Paginated user list
+type UserListResponse struct {
+	Users []AdminUserDTO `json:"users"`
+	Total int64          `json:"total" example:"120"`
+	Page  int            `json:"page" example:"1"`
+	Limit int            `json:"limit" example:"20"`
+}
+
+// UpdateUserRoleRequest represents the request to change a user's role
+// @Description Request to change a user's role
+type UpdateUserRoleRequest struct {
+	Role string `json:"role" binding:"required" example:"moderator"`
+}
+
+// UpdateUserStatusRequest represents the request to activate or deactivate a user
+// @Description Request to activate or deactivate a user
+type UpdateUserStatusRequest struct {
+	IsActive *bool `json:"is_active" binding:"required" example:"false"`
+}
@@ -0,0 +1,118 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"time"
+
+	"github.com/yourusername/car-reselling-backend/internal/audit"
+	"github.com/yourusername/car-reselling-backend/internal/database"
+	"github.com/yourusername/car-reselling-backend/internal/models"
+	appErrors "github.com/yourusername/car-reselling-backend/pkg/errors"
+	"github.com/yourusername/car-reselling-backend/pkg/utils"
+)
+
+// maxVerificationEmails caps verification emails per user per hour
+const maxVerificationEmails = 3
+
+// Mailer sends account emails
+type Mailer interface {
+	SendVerification(ctx context.Context, to, name, link string, ttl time.Duration) error
+	SendWelcome(ctx context.Context, to, name string) error
+	SendAccountLocked(ctx context.Context, to, name, ipAddress string, lockedFor time.Duration) error
+	SendPasswordReset(ctx context.Context, to, name, code string, ttl time.Duration) error
+}
+
+// SetMailer sets the service used to send account emails
+func (s *Service) SetMailer(m Mailer) {
+	s.mailer = m
+}
+
+// ResendVerificationEmail sends a new email verification link to the user
+func (s *Service) ResendVerificationEmail(ctx context.Context, userID string) error {
+	user, err := s.repo.GetUserByID(userID)
+	if err != nil {
+		return err
+	}
+	if user.EmailVerified {
+		return appErrors.ErrEmailAlreadyVerified
+	}
+
+	// Rate limit (max 3 emails per hour)
+	rateLimitKey := fmt.Sprintf("email_verification_rate_limit:%s", userID)
+	count, err := database.Increment(ctx, rateLimitKey)
+	if err != nil {
+		return fmt.Errorf("failed to check email rate limit: %w", err)
+	}
+	if count == 1 {
+		database.RedisClient.Expire(ctx, rateLimitKey, time.Hour)
+	}
+	if count > maxVerificationEmails {
+		return appErrors.ErrTooManyAttempts
+	}
+
+	if err := s.sendVerificationEmail(ctx, user); err != nil {
+		log.Printf("Verification email to user %s failed: %v", userID, err)
+		return appErrors.ErrEmailDeliveryFailed
+	}
+	return nil
+}
+
+// VerifyEmail marks the user's email as verified using a link token.
+// Verifying an already verified email succeeds.
+func (s *Service) VerifyEmail(ctx context.Context, token string) error {
+	claims, err := utils.ValidateEmailVerificationToken(token, s.config.EmailTokenSecret)
+	if err != nil {
+		return appErrors.ErrInvalidToken
+	}
+
+	user, err := s.repo.GetUserByID(claims.UserID)
+	if err != nil {
+		return appErrors.ErrInvalidToken
+	}
+	// The link was sent to an address the user no longer has
+	if user.Email != claims.Email {
+		return appErrors.ErrInvalidToken
+	}
+	if user.EmailVerified {
+		return nil
+	}
+
+	now := time.Now()
+	user.EmailVerified = true
+	user.EmailVerifiedAt = &now
+	if err := s.repo.UpdateUser(user); err != nil {
+		return fmt.Errorf("failed to verify email: %w", err)
+	}
+
+	event := userEvent(audit.ActionEmailVerified, claims.UserID, claims.UserID)
+	event.Metadata = audit.Metadata{"email": user.Email}
+	s.audit(ctx, event)
+
+	if s.mailer != nil {
+		go func(to, name string) {
+			if err := s.mailer.SendWelcome(context.Background(), to, name); err != nil {
+				log.Printf("Welcome email to user %s failed: %v", user.ID, err)
+			}
+		}(user.Email, user.FullName)
+	}
+
+	return nil
+}
+
+// sendVerificationEmail sends a verification link for the user's current email
+func (s *Service) sendVerificationEmail(ctx context.Context, user *models.User) error {
+	if s.mailer == nil {
+		return fmt.Errorf("no mailer configured")
+	}
+
+	token, err := utils.GenerateEmailVerificationToken(user.ID.String(), user.Email, s.config.EmailTokenSecret)
+	if err != nil {
+		return fmt.Errorf("failed to generate verification token: %w", err)
+	}
+	link := fmt.Sprintf("%s/api/auth/verify-email?token=%s", s.config.PublicURL, url.QueryEscape(token))
+
+	return s.mailer.SendVerification(ctx, user.Email, user.FullName, link, utils.EmailVerificationTTL)
+}
@@ -1,7 +1,9 @@
 package auth
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 
@@ -53,15 +55,17 @@ func (h *Handler) Register(c *gin.Context) {
 
 // Login handles user login
 // @Summary Login user
-// @Description Login with email/phone and password
+// @Description Login with email/phone and password. Accounts with two-factor authentication get 202 with a challenge token; finish with /api/auth/login/2fa.
 // @Tags auth
 // @Accept json
 // @Produce json
 // @Param request body LoginRequest true "Login credentials"
 // @Success 200 {object} AuthResponse
+// @Success 202 {object} TwoFactorChallengeResponse
 // @Failure 400 {object} ErrorResponse
 // @Failure 401 {object} ErrorResponse
 // @Failure 403 {object} ErrorResponse
+// @Failure 429 {object} ErrorResponse "Too many failed attempts; see Retry-After"
 // @Router /api/auth/login [post]
 func (h *Handler) Login(c *gin.Context) {
 	var req LoginRequest
@@ -70,15 +74,63 @@ func (h *Handler) Login(c *gin.Context) {
 		return
 	}
 
-	response, err := h.service.Login(c.Request.Context(), &req)
+	meta := SessionMeta{
+		DeviceName: req.DeviceName,
+		UserAgent:  c.Request.UserAgent(),
+		IPAddress:  c.ClientIP(),
+	}
+
+	response, challenge, err := h.service.Login(c.Request.Context(), &req, meta)
 	if err != nil {
-		appErrors.HandleError(c, err)
+		handleLoginError(c, err)
+		return
+	}
+	if challenge != nil {
+		c.JSON(http.StatusAccepted, challenge)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// VerifyTwoFactorLogin completes a login with two-factor authentication
+// @Summary Complete 2FA login
+// @Description Exchange the login challenge token and a TOTP or recovery code for tokens. Each recovery code works once.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body TwoFactorLoginRequest true "Challenge token and code"
+// @Success 200 {object} AuthResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 429 {object} ErrorResponse
+// @Router /api/auth/login/2fa [post]
+func (h *Handler) VerifyTwoFactorLogin(c *gin.Context) {
+	var req TwoFactorLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		appErrors.HandleErrorWithMessage(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	response, err := h.service.VerifyTwoFactorLogin(c.Request.Context(), &req)
+	if err != nil {
+		handleLoginError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, response)
 }
 
+// handleLoginError responds to a login error, with Retry-After on lockouts
+func handleLoginError(c *gin.Context, err error) {
+	var lockout *LockoutError
+	if errors.As(err, &lockout) {
+		c.Header("Retry-After", strconv.Itoa(ceilSeconds(lockout.RetryAfter)))
+		err = appErrors.ErrAccountLocked
+	}
+	appErrors.HandleError(c, err)
+}
+
 // SendOTP sends an OTP to the user's phone
 // @Summary Send OTP
 // @Description Send a verification OTP to the phone number
@@ -89,6 +141,7 @@ func (h *Handler) Login(c *gin.Context) {
 // @Success 200 {object} MessageResponse
 // @Failure 400 {object} ErrorResponse
 // @Failure 429 {object} ErrorResponse
+// @Failure 502 {object} ErrorResponse
 // @Router /api/auth/send-otp [post]
 func (h *Handler) SendOTP(c *gin.Context) {
 	var req SendOTPRequest
@@ -97,7 +150,7 @@ func (h *Handler) SendOTP(c *gin.Context) {
 		return
 	}
 
-	if err := h.service.SendOTP(c.Request.Context(), req.Phone); err != nil {
+	if err := h.service.SendOTP(c.Request.Context(), req.Phone, requestLocale(c, req.Locale)); err != nil {
 		appErrors.HandleError(c, err)
 		return
 	}
@@ -135,14 +188,96 @@ func (h *Handler) VerifyOTP(c *gin.Context) {
 	})
 }
 
+// ForgotPassword starts a password reset
+// @Summary Forgot password
+// @Description Send a password reset OTP to the account with this email or phone: by email when an email is given, by SMS otherwise. At most 3 codes are sent per hour. Always succeeds for unknown accounts and when over the limit, so it can't be used to check who is registered.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body ForgotPasswordRequest true "Email or phone"
+// @Success 200 {object} MessageResponse
+// @Failure 400 {object} ErrorResponse
+// @Router /api/auth/forgot-password [post]
+func (h *Handler) ForgotPassword(c *gin.Context) {
+	var req ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		appErrors.HandleErrorWithMessage(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.service.ForgotPassword(c.Request.Context(), req.EmailOrPhone, requestLocale(c, req.Locale)); err != nil {
+		appErrors.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, MessageResponse{
+		Message: "If an account exists, a reset code has been sent to it",
+	})
+}
+
+// VerifyResetOTP verifies a password reset OTP
+// @Summary Verify password reset OTP
+// @Description Exchange the password reset OTP for a single-use reset token
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body VerifyResetOTPRequest true "Email or phone and OTP code"
+// @Success 200 {object} ResetTokenResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 429 {object} ErrorResponse
+// @Router /api/auth/forgot-password/verify [post]
+func (h *Handler) VerifyResetOTP(c *gin.Context) {
+	var req VerifyResetOTPRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		appErrors.HandleErrorWithMessage(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	response, err := h.service.VerifyResetOTP(c.Request.Context(), req.EmailOrPhone, req.Code)
+	if err != nil {
+		appErrors.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ResetPassword sets a new password using a reset token
+// @Summary Reset password
+// @Description Set a new password using a reset token. All sessions are logged out.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body ResetPasswordRequest true "Reset token and new password"
+// @Success 200 {object} MessageResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /api/auth/reset-password [post]
+func (h *Handler) ResetPassword(c *gin.Context) {
+	var req ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		appErrors.HandleErrorWithMessage(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.service.ResetPassword(c.Request.Context(), &req); err != nil {
+		appErrors.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, MessageResponse{
+		Message: "Password reset successfully. Please login with your new password.",
+	})
+}
+
 // RefreshToken refreshes an access token
 // @Summary Refresh access token
-// @Description Refresh the access token using a refresh token
+// @Description Exchange a refresh token for a new access token and a new refresh token. Each refresh token can be used once; reusing one logs out that session.
 // @Tags auth
 // @Accept json
 // @Produce json
 // @Param request body RefreshTokenRequest true "Refresh token"
-// @Success 200 {object} map[string]string
+// @Success 200 {object} TokenResponse
 // @Failure 400 {object} ErrorResponse
 // @Failure 401 {object} ErrorResponse
 // @Router /api/auth/refresh [post]
@@ -153,20 +288,18 @@ func (h *Handler) RefreshToken(c *gin.Context) {
 		return
 	}
 
-	accessToken, err := h.service.RefreshToken(c.Request.Context(), req.RefreshToken)
+	tokens, err := h.service.RefreshToken(c.Request.Context(), req.RefreshToken)
 	if err != nil {
 		appErrors.HandleError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"access_token": accessToken,
-	})
+	c.JSON(http.StatusOK, tokens)
 }
 
 // Logout logs out the current user
 // @Summary Logout user
-// @Description Logout the current user by invalidating their session
+// @Description Logout the current device by revoking its session
 // @Tags auth
 // @Security BearerAuth
 // @Produce json
@@ -180,7 +313,7 @@ func (h *Handler) Logout(c *gin.Context) {
 		return
 	}
 
-	if err := h.service.Logout(c.Request.Context(), userID.(string)); err != nil {
+	if err := h.service.Logout(c.Request.Context(), userID.(string), c.GetString("sessionID")); err != nil {
 		appErrors.HandleError(c, err)
 		return
 	}
@@ -251,7 +384,7 @@ func (h *Handler) UpdateProfile(c *gin.Context) {
 
 // ChangePassword changes the authenticated user's password
 // @Summary Change password
-// @Description Change the authenticated user's password
+// @Description Change the authenticated user's password. Other sessions are logged out.
 // @Tags auth
 // @Security BearerAuth
 // @Accept json
@@ -274,7 +407,7 @@ func (h *Handler) ChangePassword(c *gin.Context) {
 		return
 	}
 
-	if err := h.service.ChangePassword(c.Request.Context(), userID.(string), &req); err != nil {
+	if err := h.service.ChangePassword(c.Request.Context(), userID.(string), c.GetString("sessionID"), &req); err != nil {
 		appErrors.HandleError(c, err)
 		return
 	}
@@ -283,3 +416,402 @@ func (h *Handler) ChangePassword(c *gin.Context) {
 		Message: "Password changed successfully",
 	})
 }
+
+// VerifyEmail verifies the user's email address
+// @Summary Verify email
+// @Description Verify an email address with the token from the verification link
+// @Tags auth
+// @Produce json
+// @Param token query string true "Verification token from the email link"
+// @Success 200 {object} MessageResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /api/auth/verify-email [get]
+func (h *Handler) VerifyEmail(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		appErrors.HandleErrorWithMessage(c, http.StatusBadRequest, "token is required")
+		return
+	}
+
+	if err := h.service.VerifyEmail(c.Request.Context(), token); err != nil {
+		appErrors.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, MessageResponse{
+		Message: "Email verified successfully",
+	})
+}
+
+// ResendVerificationEmail sends a new email verification link
+// @Summary Resend verification email
+// @Description Send a new verification link to the current user's email address (max 3 per hour)
+// @Tags auth
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} MessageResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 429 {object} ErrorResponse
+// @Failure 502 {object} ErrorResponse
+// @Router /api/auth/verify-email/resend [post]
+func (h *Handler) ResendVerificationEmail(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		appErrors.HandleError(c, appErrors.ErrUnauthorized)
+		return
+	}
+
+	if err := h.service.ResendVerificationEmail(c.Request.Context(), userID.(string)); err != nil {
+		appErrors.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, MessageResponse{
+		Message: "Verification email sent",
+	})
+}
+
+// SetupTwoFactor starts two-factor enrollment
+// @Summary Set up 2FA
+// @Description Generate a TOTP secret to add to an authenticator app. Confirm it with /api/auth/2fa/enable within 10 minutes.
+// @Tags auth
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} TwoFactorSetupResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /api/auth/2fa/setup [post]
+func (h *Handler) SetupTwoFactor(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		appErrors.HandleError(c, appErrors.ErrUnauthorized)
+		return
+	}
+
+	setup, err := h.service.SetupTwoFactor(c.Request.Context(), userID.(string))
+	if err != nil {
+		appErrors.HandleError(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, setup)
+}
+
+// EnableTwoFactor confirms two-factor enrollment
+// @Summary Enable 2FA
+// @Description Turn on two-factor authentication with a code from the authenticator app. Returns recovery codes, shown only once.
+// @Tags auth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body TwoFactorCodeRequest true "TOTP code"
+// @Success 200 {object} RecoveryCodesResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /api/auth/2fa/enable [post]
+func (h *Handler) EnableTwoFactor(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		appErrors.HandleError(c, appErrors.ErrUnauthorized)
+		return
+	}
+
+	var req TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		appErrors.HandleErrorWithMessage(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	codes, err := h.service.EnableTwoFactor(c.Request.Context(), userID.(string), req.Code)
+	if err != nil {
+		appErrors.HandleError(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, codes)
+}
+
+// DisableTwoFactor turns off two-factor authentication
+// @Summary Disable 2FA
+// @Description Turn off two-factor authentication with the password and a TOTP or recovery code
+// @Tags auth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body DisableTwoFactorRequest true "Password and code"
+// @Success 200 {object} MessageResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /api/auth/2fa/disable [post]
+func (h *Handler) DisableTwoFactor(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		appErrors.HandleError(c, appErrors.ErrUnauthorized)
+		return
+	}
+
+	var req DisableTwoFactorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		appErrors.HandleErrorWithMessage(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.service.DisableTwoFactor(c.Request.Context(), userID.(string), &req); err != nil {
+		appErrors.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, MessageResponse{
+		Message: "Two-factor authentication disabled",
+	})
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes
+// @Summary Regenerate recovery codes
+// @Description Replace all recovery codes with new ones; the old ones stop working
+// @Tags auth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body TwoFactorCodeRequest true "TOTP code"
+// @Success 200 {object} RecoveryCodesResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /api/auth/2fa/recovery-codes [post]
+func (h *Handler) RegenerateRecoveryCodes(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		appErrors.HandleError(c, appErrors.ErrUnauthorized)
+		return
+	}
+
+	var req TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		appErrors.HandleErrorWithMessage(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	codes, err := h.service.RegenerateRecoveryCodes(c.Request.Context(), userID.(string), req.Code)
+	if err != nil {
+		appErrors.HandleError(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, codes)
+}
+
+// ListSessions lists the current user's sessions
+// @Summary List sessions
+// @Description List the devices the current user is logged in on, most recently used first
+// @Tags auth
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} Session
+// @Failure 401 {object} ErrorResponse
+// @Router /api/auth/sessions [get]
+func (h *Handler) ListSessions(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		appErrors.HandleError(c, appErrors.ErrUnauthorized)
+		return
+	}
+
+	sessions, err := h.service.ListSessions(c.Request.Context(), userID.(string), c.GetString("sessionID"))
+	if err != nil {
+		appErrors.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, sessions)
+}
+
+// RevokeSession logs out one of the current user's sessions
+// @Summary Revoke session
+// @Description Log out one of the current user's devices
+// @Tags auth
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Session ID"
+// @Success 200 {object} MessageResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/auth/sessions/{id} [delete]
+func (h *Handler) RevokeSession(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		appErrors.HandleError(c, appErrors.ErrUnauthorized)
+		return
+	}
+
+	if err := h.service.RevokeSession(c.Request.Context(), userID.(string), c.Param("id")); err != nil {
+		appErrors.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, MessageResponse{
+		Message: "Session revoked",
+	})
+}
+
+// RevokeOtherSessions logs out all of the current user's other sessions
+// @Summary Revoke other sessions
+// @Description Log out every device except the current one
+// @Tags auth
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} RevokeSessionsResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /api/auth/sessions [delete]
+func (h *Handler) RevokeOtherSessions(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		appErrors.HandleError(c, appErrors.ErrUnauthorized)
+		return
+	}
+
+	revoked, err := h.service.RevokeOtherSessions(c.Request.Context(), userID.(string), c.GetString("sessionID"))
+	if err != nil {
+		appErrors.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, RevokeSessionsResponse{Revoked: revoked})
+}
+
+// RegisterAdminRoutes registers user management routes on an admin-only group
+func (h *Handler) RegisterAdminRoutes(admin *gin.RouterGroup) {
+	users := admin.Group("/users")
+	{
+		users.GET("", h.ListUsers)
+		users.PUT("/:id/role", h.UpdateUserRole)
+		users.PUT("/:id/status", h.UpdateUserStatus)
+		users.POST("/:id/unlock", h.UnlockUser)
+	}
+}
+
+// ListUsers lists users for administration
+// @Summary List users
+// @Description List users with optional search, role and status filters (admin only)
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Param q query string false "Search name, email or phone"
+// @Param role query string false "Filter by role" Enums(user, dealer, moderator, admin)
+// @Param is_active query bool false "Filter by account status"
+// @Success 200 {object} UserListResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /api/admin/users [get]
+func (h *Handler) ListUsers(c *gin.Context) {
+	var query ListUsersQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		appErrors.HandleErrorWithMessage(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	users, err := h.service.ListUsers(c.Request.Context(), &query)
+	if err != nil {
+		appErrors.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, users)
+}
+
+// UpdateUserRole changes a user's role
+// @Summary Change user role
+// @Description Set a user's role to user, dealer, moderator or admin (admin only). The user must log in again to use the new role.
+// @Tags admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param request body UpdateUserRoleRequest true "New role"
+// @Success 200 {object} AdminUserDTO
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/admin/users/{id}/role [put]
+func (h *Handler) UpdateUserRole(c *gin.Context) {
+	var req UpdateUserRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		appErrors.HandleErrorWithMessage(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	user, err := h.service.UpdateUserRole(c.Request.Context(), c.GetString("userID"), c.Param("id"), req.Role)
+	if err != nil {
+		appErrors.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// UpdateUserStatus activates or deactivates a user
+// @Summary Activate or deactivate user
+// @Description Deactivated users cannot log in and are logged out (admin only)
+// @Tags admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param request body UpdateUserStatusRequest true "Account status"
+// @Success 200 {object} AdminUserDTO
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/admin/users/{id}/status [put]
+func (h *Handler) UpdateUserStatus(c *gin.Context) {
+	var req UpdateUserStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		appErrors.HandleErrorWithMessage(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	user, err := h.service.UpdateUserStatus(c.Request.Context(), c.GetString("userID"), c.Param("id"), *req.IsActive)
+	if err != nil {
+		appErrors.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// UnlockUser lifts a failed-login lockout
+// @Summary Unlock user
+// @Description Clear a user's failed login attempts and lockout (admin only)
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} MessageResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/admin/users/{id}/unlock [post]
+func (h *Handler) UnlockUser(c *gin.Context) {
+	if err := h.service.UnlockUser(c.Request.Context(), c.GetString("userID"), c.Param("id")); err != nil {
+		appErrors.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, MessageResponse{Message: "User unlocked"})
+}
+
+// requestLocale returns the locale from the request body, or else the first
+// Accept-Language tag
+func requestLocale(c *gin.Context, locale string) string {
+	if locale != "" {
+		return locale
+	}
+	return parseAcceptLanguage(c.GetHeader("Accept-Language"))
+}
@@ -0,0 +1,202 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/yourusername/car-reselling-backend/internal/audit"
+	"github.com/yourusername/car-reselling-backend/internal/database"
+	"github.com/yourusername/car-reselling-backend/internal/models"
+	"github.com/yourusername/car-reselling-backend/internal/notification"
+	appErrors "github.com/yourusername/car-reselling-backend/pkg/errors"
+)
+
+// Failed login throttling. Every failed login counts against both the account
+// and the client IP. Past a few free failures each further failure imposes an
+// exponentially growing wait before the next attempt; past the threshold the
+// account or IP is locked out entirely.
+const (
+	// loginFailureWindow is how long failed attempts are remembered
+	loginFailureWindow = time.Hour
+
+	// LoginLockoutDuration is how long an account or IP stays locked
+	LoginLockoutDuration = 15 * time.Minute
+
+	loginBackoffBase = time.Second
+	loginBackoffMax  = 5 * time.Minute
+
+	accountFreeFailures     = 3
+	accountLockoutThreshold = 10
+
+	// IPs get more room: many users can share one (NAT, offices)
+	ipFreeFailures     = 10
+	ipLockoutThreshold = 50
+
+	NotificationTypeAccountLocked = "account_locked"
+)
+
+// LockoutError is returned by Login while an account or IP must wait before
+// trying again. It matches appErrors.ErrAccountLocked with errors.Is.
+type LockoutError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LockoutError) Error() string { return appErrors.ErrAccountLocked.Error() }
+func (e *LockoutError) Unwrap() error { return appErrors.ErrAccountLocked }
+
+// NotificationSender is the subset of the notification service used for security alerts
+type NotificationSender interface {
+	CreateAndSend(ctx context.Context, userID uuid.UUID, title, message, notifType, imageURL string, data map[string]interface{}) (*notification.Notification, error)
+}
+
+// SetNotificationService sets the service used to alert users about their account
+func (s *Service) SetNotificationService(n NotificationSender) {
+	s.notifier = n
+}
+
+// loginSubject is something failed logins are counted against
+type loginSubject struct {
+	key       string // "account:<user id>", "login:<email or phone>" or "ip:<address>"
+	free      int
+	threshold int
+}
+
+func accountSubject(userID string) loginSubject {
+	return loginSubject{key: "account:" + userID, free: accountFreeFailures, threshold: accountLockoutThreshold}
+}
+
+// unknownAccountSubject throttles identifiers without an account exactly like
+// real accounts, so lockouts don't reveal which accounts exist
+func unknownAccountSubject(emailOrPhone string) loginSubject {
+	return loginSubject{key: "login:" + strings.ToLower(emailOrPhone), free: accountFreeFailures, threshold: accountLockoutThreshold}
+}
+
+func ipSubject(ip string) loginSubject {
+	return loginSubject{key: "ip:" + ip, free: ipFreeFailures, threshold: ipLockoutThreshold}
+}
+
+func (l loginSubject) failuresKey() string { return "login_failures:" + l.key }
+func (l loginSubject) backoffKey() string  { return "login_backoff:" + l.key }
+func (l loginSubject) lockKey() string     { return "login_lockout:" + l.key }
+
+// checkLoginThrottle returns a LockoutError if any subject is locked out or
+// still waiting out its backoff. Redis errors let the login through.
+func checkLoginThrottle(ctx context.Context, subjects ...loginSubject) error {
+	pipe := database.RedisClient.Pipeline()
+	ttls := make([]*redis.DurationCmd, 0, 2*len(subjects))
+	for _, subject := range subjects {
+		ttls = append(ttls, pipe.PTTL(ctx, subject.lockKey()), pipe.PTTL(ctx, subject.backoffKey()))
+	}
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		log.Printf("Failed to check login throttle: %v", err)
+		return nil
+	}
+
+	var wait time.Duration
+	for _, ttl := range ttls {
+		if d := ttl.Val(); d > wait {
+			wait = d
+		}
+	}
+	if wait > 0 {
+		return &LockoutError{RetryAfter: wait}
+	}
+	return nil
+}
+
+// recordLoginFailure counts a failed attempt and applies backoff or a lockout.
+// It reports whether this failure started a lockout.
+func recordLoginFailure(ctx context.Context, subject loginSubject) bool {
+	rdb := database.RedisClient
+
+	failures, err := rdb.Incr(ctx, subject.failuresKey()).Result()
+	if err != nil {
+		log.Printf("Failed to record login failure: %v", err)
+		return false
+	}
+	if failures == 1 {
+		rdb.Expire(ctx, subject.failuresKey(), loginFailureWindow)
+	}
+
+	if failures >= int64(subject.threshold) {
+		locked, err := rdb.SetNX(ctx, subject.lockKey(), "1", LoginLockoutDuration).Result()
+		if err != nil {
+			log.Printf("Failed to lock out %s: %v", subject.key, err)
+			return false
+		}
+		// Counting starts over once the lockout ends
+		rdb.Del(ctx, subject.failuresKey(), subject.backoffKey())
+		return locked
+	}
+
+	if excess := int(failures) - subject.free; excess > 0 {
+		rdb.Set(ctx, subject.backoffKey(), "1", loginBackoff(excess))
+	}
+	return false
+}
+
+// loginBackoff is the wait after the n-th failure past the free ones: 1s, 2s, 4s, ...
+func loginBackoff(n int) time.Duration {
+	if n > 16 {
+		return loginBackoffMax
+	}
+	d := loginBackoffBase << (n - 1)
+	if d > loginBackoffMax {
+		return loginBackoffMax
+	}
+	return d
+}
+
+// clearLoginFailures resets the counters of a subject (successful login, admin unlock)
+func clearLoginFailures(ctx context.Context, subject loginSubject) error {
+	return database.RedisClient.Del(ctx, subject.failuresKey(), subject.backoffKey(), subject.lockKey()).Err()
+}
+
+// notifyAccountLocked tells the owner their account was locked, by push/in-app
+// notification and by email if their address is verified
+func (s *Service) notifyAccountLocked(user *models.User, ipAddress string) {
+	ctx := context.Background()
+
+	event := userEvent(audit.ActionAccountLocked, "", user.ID.String())
+	event.IPAddress = ipAddress
+	event.Metadata = audit.Metadata{"locked_for_seconds": int(LoginLockoutDuration.Seconds())}
+	s.audit(ctx, event)
+
+	if s.notifier != nil {
+		title := "Your account was temporarily locked"
+		body := fmt.Sprintf("Too many failed login attempts. Login is blocked for %d minutes. If this wasn't you, change your password.",
+			int(LoginLockoutDuration.Minutes()))
+		data := map[string]interface{}{"ip_address": ipAddress}
+		if _, err := s.notifier.CreateAndSend(ctx, user.ID, title, body, NotificationTypeAccountLocked, "", data); err != nil {
+			log.Printf("Failed to send lockout notification to %s: %v", user.ID, err)
+		}
+	}
+
+	if s.mailer != nil && user.EmailVerified {
+		if err := s.mailer.SendAccountLocked(ctx, user.Email, user.FullName, ipAddress, LoginLockoutDuration); err != nil {
+			log.Printf("Failed to send lockout email to %s: %v", user.ID, err)
+		}
+	}
+}
+
+// UnlockUser clears the failed login counters and lockout of a user (admin)
+func (s *Service) UnlockUser(ctx context.Context, actorID, userID string) error {
+	user, err := s.repo.GetUserByID(userID)
+	if err != nil {
+		return err
+	}
+
+	if err := clearLoginFailures(ctx, accountSubject(user.ID.String())); err != nil {
+		return fmt.Errorf("failed to unlock user: %w", err)
+	}
+
+	s.audit(ctx, userEvent(audit.ActionUserUnlocked, actorID, userID))
+	return nil
+}
@@ -1,15 +1,12 @@
 package auth
 
 import (
-	"fmt"
-	"net/http"
+	"log"
 	"strings"
-	"time"
 
 	"github.com/gin-gonic/gin"
 
 	"github.com/yourusername/car-reselling-backend/internal/config"
-	"github.com/yourusername/car-reselling-backend/internal/database"
 	appErrors "github.com/yourusername/car-reselling-backend/pkg/errors"
 	"github.com/yourusername/car-reselling-backend/pkg/utils"
 )
@@ -19,22 +16,7 @@ import (
 // This is useful for public endpoints that need to know if a user is logged in.
 func OptionalAuthMiddleware(cfg *config.Config) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		var token string
-
-		// First, try Authorization header
-		authHeader := c.GetHeader("Authorization")
-		if authHeader != "" {
-			// Extract token from "Bearer <token>"
-			parts := strings.Split(authHeader, " ")
-			if len(parts) == 2 && parts[0] == "Bearer" {
-				token = parts[1]
-			}
-		}
-
-		// If no header token, try query parameter (for WebSocket connections)
-		if token == "" {
-			token = c.Query("token")
-		}
+		token := requestToken(c)
 
 		// If no token, just continue without setting user context
 		if token == "" {
@@ -43,16 +25,14 @@ func OptionalAuthMiddleware(cfg *config.Config) gin.HandlerFunc {
 		}
 
 		// Validate token (if present)
-		claims, err := utils.ValidateToken(token, cfg.JWTSecret)
+		claims, err := validateAccessToken(c, token, cfg.JWTSecret)
 		if err != nil {
 			// Invalid token, but still allow request to proceed (as unauthenticated)
 			c.Next()
 			return
 		}
 
-		// Set user ID in context
-		c.Set("userID", claims.UserID)
-		c.Set("email", claims.Email)
+		setUserContext(c, claims)
 
 		c.Next()
 	}
@@ -61,22 +41,7 @@ func OptionalAuthMiddleware(cfg *config.Config) gin.HandlerFunc {
 // AuthMiddleware validates JWT tokens and sets user context
 func AuthMiddleware(cfg *config.Config) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		var token string
-
-		// First, try Authorization header
-		authHeader := c.GetHeader("Authorization")
-		if authHeader != "" {
-			// Extract token from "Bearer <token>"
-			parts := strings.Split(authHeader, " ")
-			if len(parts) == 2 && parts[0] == "Bearer" {
-				token = parts[1]
-			}
-		}
-
-		// If no header token, try query parameter (for WebSocket connections)
-		if token == "" {
-			token = c.Query("token")
-		}
+		token := requestToken(c)
 
 		// If still no token, unauthorized
 		if token == "" {
@@ -86,52 +51,79 @@ func AuthMiddleware(cfg *config.Config) gin.HandlerFunc {
 		}
 
 		// Validate token
-		claims, err := utils.ValidateToken(token, cfg.JWTSecret)
+		claims, err := validateAccessToken(c, token, cfg.JWTSecret)
 		if err != nil {
 			appErrors.HandleError(c, appErrors.ErrInvalidToken)
 			c.Abort()
 			return
 		}
 
-		// Set user ID in context
-		c.Set("userID", claims.UserID)
-		c.Set("email", claims.Email)
+		setUserContext(c, claims)
 
 		c.Next()
 	}
 }
 
-// RateLimitMiddleware implements rate limiting using Redis
-func RateLimitMiddleware() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		// Get client IP
-		clientIP := c.ClientIP()
+// requestToken returns the bearer token from the Authorization header, or the
+// token query parameter (for WebSocket connections)
+func requestToken(c *gin.Context) string {
+	authHeader := c.GetHeader("Authorization")
+	if authHeader != "" {
+		// Extract token from "Bearer <token>"
+		parts := strings.Split(authHeader, " ")
+		if len(parts) == 2 && parts[0] == "Bearer" {
+			return parts[1]
+		}
+	}
+	return c.Query("token")
+}
+
+// validateAccessToken checks the token signature and expiry, and that its jti
+// hasn't been revoked. Tokens without a session (issued before per-device
+// sessions) are rejected so clients fall back to refreshing.
+func validateAccessToken(c *gin.Context, token, secret string) (*utils.JWTClaims, error) {
+	claims, err := utils.ValidateToken(token, secret)
+	if err != nil {
+		return nil, err
+	}
+	if claims.ID == "" || claims.SessionID == "" {
+		return nil, utils.ErrInvalidToken
+	}
 
-		// Rate limit key
-		rateLimitKey := "rate_limit:" + clientIP
+	revoked, err := isTokenRevoked(c.Request.Context(), claims.ID)
+	if err != nil {
+		// Don't lock everyone out while Redis is unavailable
+		log.Printf("Failed to check token denylist: %v", err)
+	}
+	if revoked {
+		return nil, utils.ErrInvalidToken
+	}
+	return claims, nil
+}
 
-		// Check current count
-		ctx := c.Request.Context()
-		count, err := database.Get(ctx, rateLimitKey)
-		if err != nil {
-			// First request, set count to 1
-			database.Set(ctx, rateLimitKey, "1", time.Minute)
-			c.Next()
-			return
-		}
+// setUserContext stores the authenticated user's identity on the request
+func setUserContext(c *gin.Context, claims *utils.JWTClaims) {
+	c.Set("userID", claims.UserID)
+	c.Set("email", claims.Email)
+	c.Set("role", claims.Role)
+	c.Set("sessionID", claims.SessionID)
+}
 
-		var requestCount int
-		fmt.Sscanf(count, "%d", &requestCount)
+// RequireRole allows only users whose token carries one of the given roles.
+// Must run after AuthMiddleware. Role changes take effect once the user logs in
+// again or refreshes their access token.
+func RequireRole(roles ...string) gin.HandlerFunc {
+	allowed := make(map[string]bool, len(roles))
+	for _, role := range roles {
+		allowed[role] = true
+	}
 
-		// Limit: 100 requests per minute (increased for testing)
-		if requestCount >= 100 {
-			appErrors.HandleErrorWithMessage(c, http.StatusTooManyRequests, "Too many requests. Please try again later.")
+	return func(c *gin.Context) {
+		if !allowed[c.GetString("role")] {
+			appErrors.HandleError(c, appErrors.ErrForbidden)
 			c.Abort()
 			return
 		}
-
-		// Increment count
-		database.Increment(ctx, rateLimitKey)
 		c.Next()
 	}
 }
@@ -0,0 +1,165 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/yourusername/car-reselling-backend/internal/config"
+	"github.com/yourusername/car-reselling-backend/internal/models"
+	"github.com/yourusername/car-reselling-backend/pkg/utils"
+)
+
+const testUserID = "550e8400-e29b-41d4-a716-446655440000"
+
+// stubDenylist replaces the Redis-backed denylist check for the duration of a test
+func stubDenylist(t *testing.T, revoked ...string) {
+	t.Helper()
+	denied := make(map[string]bool, len(revoked))
+	for _, id := range revoked {
+		denied[id] = true
+	}
+
+	original := isTokenRevoked
+	isTokenRevoked = func(ctx context.Context, tokenID string) (bool, error) {
+		return denied[tokenID], nil
+	}
+	t.Cleanup(func() { isTokenRevoked = original })
+}
+
+func TestRequireRole(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	stubDenylist(t)
+	cfg := &config.Config{JWTSecret: "test-secret"}
+
+	router := gin.New()
+	router.GET("/staff", AuthMiddleware(cfg), RequireRole(models.RoleModerator, models.RoleAdmin), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	tests := []struct {
+		name string
+		role string
+		want int
+	}{
+		{"admin allowed", models.RoleAdmin, http.StatusOK},
+		{"moderator allowed", models.RoleModerator, http.StatusOK},
+		{"dealer forbidden", models.RoleDealer, http.StatusForbidden},
+		{"user forbidden", models.RoleUser, http.StatusForbidden},
+		{"token without role forbidden", "", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := utils.GenerateAccessToken(testUserID, "user@example.com", tt.role, "session-1", "token-1", cfg.JWTSecret)
+			if err != nil {
+				t.Fatalf("GenerateAccessToken: %v", err)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/staff", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequireRole_NoToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	stubDenylist(t)
+	cfg := &config.Config{JWTSecret: "test-secret"}
+
+	router := gin.New()
+	router.GET("/admin", AuthMiddleware(cfg), RequireRole(models.RoleAdmin), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin", nil))
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestAuthMiddleware_RejectsOtherTokens(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	stubDenylist(t)
+	cfg := &config.Config{JWTSecret: "test-secret"}
+
+	router := gin.New()
+	router.GET("/me", AuthMiddleware(cfg), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	refresh, err := utils.GenerateRefreshToken(testUserID, "session-1", "token-1", cfg.JWTSecret)
+	if err != nil {
+		t.Fatalf("GenerateRefreshToken: %v", err)
+	}
+	verification, err := utils.GenerateEmailVerificationToken(testUserID, "user@example.com", cfg.JWTSecret)
+	if err != nil {
+		t.Fatalf("GenerateEmailVerificationToken: %v", err)
+	}
+
+	for name, token := range map[string]string{"refresh token": refresh, "email verification token": verification} {
+		req := httptest.NewRequest(http.MethodGet, "/me", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusUnauthorized {
+			t.Errorf("%s: status = %d, want %d", name, rec.Code, http.StatusUnauthorized)
+		}
+	}
+}
+
+func TestAuthMiddleware_TokenChecks(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	stubDenylist(t, "revoked-token")
+	cfg := &config.Config{JWTSecret: "test-secret"}
+
+	router := gin.New()
+	router.GET("/me", AuthMiddleware(cfg), func(c *gin.Context) {
+		c.String(http.StatusOK, c.GetString("sessionID"))
+	})
+
+	tests := []struct {
+		name      string
+		sessionID string
+		tokenID   string
+		want      int
+	}{
+		{"valid session token", "session-1", "token-1", http.StatusOK},
+		{"revoked token", "session-1", "revoked-token", http.StatusUnauthorized},
+		{"token without session", "", "token-2", http.StatusUnauthorized},
+		{"token without jti", "session-1", "", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := utils.GenerateAccessToken(testUserID, "user@example.com", models.RoleUser, tt.sessionID, tt.tokenID, cfg.JWTSecret)
+			if err != nil {
+				t.Fatalf("GenerateAccessToken: %v", err)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/me", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+			if tt.want == http.StatusOK && rec.Body.String() != tt.sessionID {
+				t.Errorf("sessionID = %q, want %q", rec.Body.String(), tt.sessionID)
+			}
+		})
+	}
+}
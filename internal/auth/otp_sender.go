@@ -0,0 +1,217 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/twilio/twilio-go"
+	twilioApi "github.com/twilio/twilio-go/rest/api/v2010"
+
+	"github.com/yourusername/car-reselling-backend/internal/config"
+)
+
+// OTP delivery providers, selected with OTP_PROVIDER
+const (
+	OTPProviderTwilio  = "twilio"
+	OTPProviderWebhook = "webhook"
+	OTPProviderConsole = "console"
+)
+
+// OTPMessage is one OTP to deliver
+type OTPMessage struct {
+	Phone   string
+	Code    string
+	Purpose string // OTPPurposeVerification, OTPPurposePasswordReset
+	Locale  string // e.g. "es" or "pt-BR"; unknown locales fall back to English
+	TTL     time.Duration
+}
+
+// Text renders the SMS body for the message's purpose and locale
+func (m OTPMessage) Text() string {
+	return renderOTPTemplate(m.Locale, m.Purpose, m.Code, m.TTL)
+}
+
+// OTPSender delivers OTP codes. Send returns an error when the provider did not
+// accept the message, so callers can tell the user the code was not sent.
+type OTPSender interface {
+	Send(ctx context.Context, msg OTPMessage) error
+}
+
+// NewOTPSender creates the OTP sender configured by OTP_PROVIDER. When no
+// provider is set, Twilio is used if it has credentials, otherwise the console
+// provider outside production.
+func NewOTPSender(cfg *config.Config) (OTPSender, error) {
+	provider := cfg.OTPProvider
+	if provider == "" {
+		if cfg.TwilioAccountSID != "" && cfg.TwilioAuthToken != "" {
+			provider = OTPProviderTwilio
+		} else {
+			provider = OTPProviderConsole
+		}
+	}
+
+	switch provider {
+	case OTPProviderTwilio:
+		return NewTwilioOTPSender(cfg.TwilioAccountSID, cfg.TwilioAuthToken, cfg.TwilioPhoneNumber)
+	case OTPProviderWebhook:
+		return NewWebhookOTPSender(cfg.OTPWebhookURL, cfg.OTPWebhookSecret)
+	case OTPProviderConsole:
+		// Printing codes is only acceptable on a developer machine
+		if cfg.Environment == "production" {
+			return nil, fmt.Errorf("OTP provider %q is not allowed in production", provider)
+		}
+		return NewConsoleOTPSender(cfg.OTPDevFile), nil
+	default:
+		return nil, fmt.Errorf("unknown OTP provider %q (use twilio, webhook or console)", provider)
+	}
+}
+
+// --- Twilio ---
+
+// TwilioOTPSender sends OTPs as SMS through Twilio
+type TwilioOTPSender struct {
+	client *twilio.RestClient
+	from   string
+}
+
+// NewTwilioOTPSender creates a Twilio OTP sender
+func NewTwilioOTPSender(accountSID, authToken, fromNumber string) (*TwilioOTPSender, error) {
+	if accountSID == "" || authToken == "" || fromNumber == "" {
+		return nil, fmt.Errorf("twilio OTP provider requires TWILIO_ACCOUNT_SID, TWILIO_AUTH_TOKEN and TWILIO_PHONE_NUMBER")
+	}
+
+	client := twilio.NewRestClientWithParams(twilio.ClientParams{
+		Username: accountSID,
+		Password: authToken,
+	})
+
+	return &TwilioOTPSender{client: client, from: fromNumber}, nil
+}
+
+// Send sends the OTP via Twilio SMS
+func (t *TwilioOTPSender) Send(ctx context.Context, msg OTPMessage) error {
+	params := &twilioApi.CreateMessageParams{}
+	params.SetTo(msg.Phone)
+	params.SetFrom(t.from)
+	params.SetBody(msg.Text())
+
+	if _, err := t.client.Api.CreateMessage(params); err != nil {
+		return fmt.Errorf("twilio: %w", err)
+	}
+	return nil
+}
+
+// --- Webhook ---
+
+// WebhookOTPSender posts OTPs to an HTTP endpoint, for SMS gateways without a
+// dedicated provider. With a secret, the JSON body is signed with HMAC-SHA256
+// in the X-Signature header ("sha256=<hex>").
+type WebhookOTPSender struct {
+	url    string
+	secret string
+	client *http.Client
+}
+
+// webhookOTPPayload is the JSON body posted by WebhookOTPSender
+type webhookOTPPayload struct {
+	To      string `json:"to"`
+	Message string `json:"message"`
+	Code    string `json:"code"`
+	Purpose string `json:"purpose"`
+	Locale  string `json:"locale"`
+}
+
+// NewWebhookOTPSender creates a webhook OTP sender
+func NewWebhookOTPSender(url, secret string) (*WebhookOTPSender, error) {
+	if url == "" {
+		return nil, fmt.Errorf("webhook OTP provider requires OTP_WEBHOOK_URL")
+	}
+
+	return &WebhookOTPSender{
+		url:    url,
+		secret: secret,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+// Send posts the OTP to the webhook; any non-2xx response is a delivery failure
+func (w *WebhookOTPSender) Send(ctx context.Context, msg OTPMessage) error {
+	body, err := json.Marshal(webhookOTPPayload{
+		To:      msg.Phone,
+		Message: msg.Text(),
+		Code:    msg.Code,
+		Purpose: msg.Purpose,
+		Locale:  msg.Locale,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if w.secret != "" {
+		mac := hmac.New(sha256.New, []byte(w.secret))
+		mac.Write(body)
+		req.Header.Set("X-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook: unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// --- Console (development) ---
+
+// ConsoleOTPSender is a development provider that writes OTPs to a file, or to
+// the log when no file is set. NewOTPSender refuses it in production.
+type ConsoleOTPSender struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewConsoleOTPSender creates a console OTP sender; path may be empty
+func NewConsoleOTPSender(path string) *ConsoleOTPSender {
+	return &ConsoleOTPSender{path: path}
+}
+
+// Send writes the OTP message
+func (c *ConsoleOTPSender) Send(ctx context.Context, msg OTPMessage) error {
+	line := fmt.Sprintf("[%s] OTP to %s (%s): %s", time.Now().Format(time.RFC3339), msg.Phone, msg.Purpose, msg.Text())
+
+	if c.path == "" {
+		log.Println(line)
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	f, err := os.OpenFile(c.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("console OTP file: %w", err)
+	}
+	defer f.Close()
+
+	_, err = fmt.Fprintln(f, line)
+	return err
+}
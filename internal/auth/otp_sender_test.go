@@ -0,0 +1,121 @@
+package auth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourusername/car-reselling-backend/internal/config"
+)
+
+func TestRenderOTPTemplate(t *testing.T) {
+	tests := []struct {
+		name    string
+		locale  string
+		purpose string
+		want    string
+	}{
+		{"english", "en", OTPPurposeVerification, "Your verification code is: 123456. Valid for 10 minutes."},
+		{"empty locale", "", OTPPurposeVerification, "Your verification code is: 123456. Valid for 10 minutes."},
+		{"language", "es", OTPPurposeVerification, "Tu código de verificación es: 123456. Válido durante 10 minutos."},
+		{"region falls back to language", "pt_BR", OTPPurposeVerification, "Seu código de verificação é: 123456. Válido por 10 minutos."},
+		{"case insensitive", "FR-ca", OTPPurposeVerification, "Votre code de vérification est : 123456. Valable 10 minutes."},
+		{"unknown locale", "xx", OTPPurposePasswordReset, "Your password reset code is: 123456. Valid for 10 minutes. If you didn't ask to reset your password, ignore this message."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := renderOTPTemplate(tt.locale, tt.purpose, "123456", 10*time.Minute)
+			if got != tt.want {
+				t.Errorf("renderOTPTemplate(%q, %q) = %q, want %q", tt.locale, tt.purpose, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseAcceptLanguage(t *testing.T) {
+	tests := map[string]string{
+		"":                        "",
+		"*":                       "",
+		"es":                      "es",
+		"pt-BR,pt;q=0.9,en;q=0.8": "pt-BR",
+		" fr-CA ;q=0.9, en;q=0.5": "fr-CA",
+	}
+	for header, want := range tests {
+		if got := parseAcceptLanguage(header); got != want {
+			t.Errorf("parseAcceptLanguage(%q) = %q, want %q", header, got, want)
+		}
+	}
+}
+
+func TestNewOTPSender(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     config.Config
+		wantErr bool
+	}{
+		{"defaults to console", config.Config{Environment: "development"}, false},
+		{"console refused in production", config.Config{Environment: "production", OTPProvider: OTPProviderConsole}, true},
+		{"no silent console fallback in production", config.Config{Environment: "production"}, true},
+		{"twilio needs credentials", config.Config{OTPProvider: OTPProviderTwilio}, true},
+		{"webhook needs url", config.Config{OTPProvider: OTPProviderWebhook}, true},
+		{"unknown provider", config.Config{OTPProvider: "pigeon"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewOTPSender(&tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewOTPSender() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWebhookOTPSender(t *testing.T) {
+	const secret = "webhook-secret"
+
+	status := http.StatusOK
+	var payload webhookOTPPayload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(body)
+		if got, want := r.Header.Get("X-Signature"), "sha256="+hex.EncodeToString(mac.Sum(nil)); got != want {
+			t.Errorf("X-Signature = %q, want %q", got, want)
+		}
+		if err := json.Unmarshal(body, &payload); err != nil {
+			t.Errorf("invalid JSON body: %v", err)
+		}
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	sender, err := NewWebhookOTPSender(server.URL, secret)
+	if err != nil {
+		t.Fatalf("NewWebhookOTPSender() error = %v", err)
+	}
+
+	msg := OTPMessage{Phone: "+1234567890", Code: "654321", Purpose: OTPPurposeVerification, Locale: "es", TTL: 10 * time.Minute}
+	if err := sender.Send(context.Background(), msg); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if payload.To != msg.Phone || payload.Code != msg.Code || !strings.Contains(payload.Message, "654321") {
+		t.Errorf("unexpected payload %+v", payload)
+	}
+
+	// Gateway errors must reach the caller
+	status = http.StatusInternalServerError
+	if err := sender.Send(context.Background(), msg); err == nil {
+		t.Error("Send() error = nil for a 500 response")
+	}
+}
@@ -0,0 +1,80 @@
+package auth
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// OTP purposes, used to pick the message template
+const (
+	OTPPurposeVerification  = "verification"
+	OTPPurposePasswordReset = "password_reset"
+)
+
+// defaultOTPLocale is used when the requested locale has no templates
+const defaultOTPLocale = "en"
+
+// otpTemplates are the SMS bodies per locale and purpose. {code} and {minutes}
+// are replaced when rendering. Locales are lower-case BCP 47 tags; a regional
+// tag ("pt-br") may be added next to its language ("pt") to override it.
+var otpTemplates = map[string]map[string]string{
+	"en": {
+		OTPPurposeVerification:  "Your verification code is: {code}. Valid for {minutes} minutes.",
+		OTPPurposePasswordReset: "Your password reset code is: {code}. Valid for {minutes} minutes. If you didn't ask to reset your password, ignore this message.",
+	},
+	"es": {
+		OTPPurposeVerification:  "Tu código de verificación es: {code}. Válido durante {minutes} minutos.",
+		OTPPurposePasswordReset: "Tu código para restablecer la contraseña es: {code}. Válido durante {minutes} minutos. Si no lo solicitaste, ignora este mensaje.",
+	},
+	"fr": {
+		OTPPurposeVerification:  "Votre code de vérification est : {code}. Valable {minutes} minutes.",
+		OTPPurposePasswordReset: "Votre code de réinitialisation du mot de passe est : {code}. Valable {minutes} minutes. Si vous n'avez rien demandé, ignorez ce message.",
+	},
+	"pt": {
+		OTPPurposeVerification:  "Seu código de verificação é: {code}. Válido por {minutes} minutos.",
+		OTPPurposePasswordReset: "Seu código para redefinir a senha é: {code}. Válido por {minutes} minutos. Se você não pediu, ignore esta mensagem.",
+	},
+	"ar": {
+		OTPPurposeVerification:  "رمز التحقق الخاص بك هو: {code}. صالح لمدة {minutes} دقيقة.",
+		OTPPurposePasswordReset: "رمز إعادة تعيين كلمة المرور هو: {code}. صالح لمدة {minutes} دقيقة. إذا لم تطلب ذلك، تجاهل هذه الرسالة.",
+	},
+}
+
+// renderOTPTemplate renders the template for a locale and purpose, falling back
+// from "pt-BR" to "pt" to English
+func renderOTPTemplate(locale, purpose, code string, ttl time.Duration) string {
+	tmpl := lookupOTPTemplate(locale, purpose)
+	return strings.NewReplacer(
+		"{code}", code,
+		"{minutes}", strconv.Itoa(int(ttl.Minutes())),
+	).Replace(tmpl)
+}
+
+func lookupOTPTemplate(locale, purpose string) string {
+	locale = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"))
+
+	candidates := []string{locale}
+	if i := strings.Index(locale, "-"); i > 0 {
+		candidates = append(candidates, locale[:i])
+	}
+	candidates = append(candidates, defaultOTPLocale)
+
+	for _, l := range candidates {
+		if tmpl, ok := otpTemplates[l][purpose]; ok {
+			return tmpl
+		}
+	}
+	return otpTemplates[defaultOTPLocale][OTPPurposeVerification]
+}
+
+// parseAcceptLanguage returns the first language tag of an Accept-Language header
+func parseAcceptLanguage(header string) string {
+	first, _, _ := strings.Cut(header, ",")
+	tag, _, _ := strings.Cut(first, ";")
+	tag = strings.TrimSpace(tag)
+	if tag == "*" {
+		return ""
+	}
+	return tag
+}
@@ -0,0 +1,222 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/yourusername/car-reselling-backend/internal/audit"
+	"github.com/yourusername/car-reselling-backend/internal/database"
+	"github.com/yourusername/car-reselling-backend/internal/models"
+	appErrors "github.com/yourusername/car-reselling-backend/pkg/errors"
+	"github.com/yourusername/car-reselling-backend/pkg/utils"
+)
+
+const (
+	// passwordResetOTPTTL is how long a password reset OTP can be verified
+	passwordResetOTPTTL = 10 * time.Minute
+
+	// PasswordResetTokenTTL is how long a verified reset token can be used
+	PasswordResetTokenTTL = 15 * time.Minute
+
+	// maxPasswordResetRequests caps reset OTPs per user per hour
+	maxPasswordResetRequests = 3
+
+	// maxPasswordResetAttempts caps wrong codes per OTP
+	maxPasswordResetAttempts = 3
+)
+
+// Redis keys for the forgot-password flow. OTP keys are per user, so an email
+// and a phone request for the same account share one code and one rate limit.
+const (
+	passwordResetOTPPrefix      = "password_reset_otp:"
+	passwordResetAttemptsPrefix = "password_reset_attempts:"
+	passwordResetRatePrefix     = "password_reset_rate_limit:"
+	passwordResetTokenPrefix    = "password_reset_token:"
+)
+
+// ForgotPassword sends a password reset OTP by email when the account is
+// identified by its email address, and by SMS to the account's phone
+// otherwise. Unknown or inactive accounts, and accounts over the request
+// limit, are silently ignored so the endpoint can't be used to find out who
+// is registered.
+func (s *Service) ForgotPassword(ctx context.Context, emailOrPhone, locale string) error {
+	if !utils.ValidateEmail(emailOrPhone) && !utils.ValidatePhone(emailOrPhone) {
+		return appErrors.ErrInvalidEmailOrPhone
+	}
+
+	user, err := s.findUserForReset(emailOrPhone)
+	if err != nil || !user.IsActive {
+		return nil
+	}
+	userID := user.ID.String()
+
+	// Rate limit (max 3 reset OTPs per hour)
+	count, err := database.Increment(ctx, passwordResetRatePrefix+userID)
+	if err != nil {
+		return fmt.Errorf("failed to check reset rate limit: %w", err)
+	}
+	if count == 1 {
+		database.RedisClient.Expire(ctx, passwordResetRatePrefix+userID, time.Hour)
+	}
+	if count > maxPasswordResetRequests {
+		// Answered like any other request; an error here would reveal the account
+		log.Printf("Password reset for user %s skipped: too many requests", userID)
+		return nil
+	}
+
+	// A new code replaces the old one and resets the attempt counter
+	otpCode := utils.GenerateOTP()
+	if err := database.Set(ctx, passwordResetOTPPrefix+userID, otpCode, passwordResetOTPTTL); err != nil {
+		return fmt.Errorf("failed to store reset OTP: %w", err)
+	}
+	database.Delete(ctx, passwordResetAttemptsPrefix+userID)
+
+	if utils.ValidateEmail(emailOrPhone) {
+		err = s.emailResetOTP(ctx, user, otpCode)
+	} else {
+		err = s.deliverOTP(ctx, OTPMessage{
+			Phone:   user.Phone,
+			Code:    otpCode,
+			Purpose: OTPPurposePasswordReset,
+			Locale:  locale,
+			TTL:     passwordResetOTPTTL,
+		})
+	}
+	if err != nil {
+		// Reporting the failure would reveal that the account exists, so it
+		// is only logged
+		database.Delete(ctx, passwordResetOTPPrefix+userID)
+	}
+	return nil
+}
+
+// emailResetOTP sends a password reset OTP to the account's email address
+func (s *Service) emailResetOTP(ctx context.Context, user *models.User, code string) error {
+	if s.mailer == nil {
+		log.Printf("Password reset email to user %s failed: email is not configured", user.ID)
+		return appErrors.ErrOTPDeliveryFailed
+	}
+	if err := s.mailer.SendPasswordReset(ctx, user.Email, user.FullName, code, passwordResetOTPTTL); err != nil {
+		log.Printf("Password reset email to user %s failed: %v", user.ID, err)
+		return appErrors.ErrOTPDeliveryFailed
+	}
+	return nil
+}
+
+// VerifyResetOTP exchanges a valid password reset OTP for a single-use reset token
+func (s *Service) VerifyResetOTP(ctx context.Context, emailOrPhone, code string) (*ResetTokenResponse, error) {
+	user, err := s.findUserForReset(emailOrPhone)
+	if err != nil {
+		// Same answer as an unknown code, to avoid leaking which accounts exist
+		return nil, appErrors.ErrOTPExpired
+	}
+	userID := user.ID.String()
+
+	otpKey := passwordResetOTPPrefix + userID
+	storedCode, err := database.Get(ctx, otpKey)
+	if err != nil {
+		return nil, appErrors.ErrOTPExpired
+	}
+
+	// Check attempts (max 3)
+	attemptsKey := passwordResetAttemptsPrefix + userID
+	if storedCode != code {
+		attempts, err := database.Increment(ctx, attemptsKey)
+		if err == nil && attempts == 1 {
+			database.RedisClient.Expire(ctx, attemptsKey, passwordResetOTPTTL)
+		}
+		if attempts >= maxPasswordResetAttempts {
+			database.Delete(ctx, otpKey) // Code is burned, a new one must be requested
+			return nil, appErrors.ErrTooManyAttempts
+		}
+		return nil, appErrors.ErrOTPInvalid
+	}
+
+	// The OTP is single-use too
+	database.Delete(ctx, otpKey)
+	database.Delete(ctx, attemptsKey)
+
+	token, err := generateResetToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate reset token: %w", err)
+	}
+	if err := database.Set(ctx, passwordResetTokenPrefix+token, userID, PasswordResetTokenTTL); err != nil {
+		return nil, fmt.Errorf("failed to store reset token: %w", err)
+	}
+
+	return &ResetTokenResponse{
+		ResetToken: token,
+		ExpiresIn:  int64(PasswordResetTokenTTL.Seconds()),
+	}, nil
+}
+
+// ResetPassword sets a new password using a reset token and logs the user out
+// of every device
+func (s *Service) ResetPassword(ctx context.Context, req *ResetPasswordRequest) error {
+	// Validate before consuming the token, so a weak password can be retried
+	if err := utils.ValidatePassword(req.NewPassword); err != nil {
+		return appErrors.ErrWeakPassword
+	}
+
+	// GETDEL makes the token single-use even under concurrent requests
+	userID, err := database.RedisClient.GetDel(ctx, passwordResetTokenPrefix+req.ResetToken).Result()
+	if errors.Is(err, redis.Nil) {
+		return appErrors.ErrInvalidToken
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read reset token: %w", err)
+	}
+
+	user, err := s.repo.GetUserByID(userID)
+	if err != nil || !user.IsActive {
+		return appErrors.ErrInvalidToken
+	}
+
+	passwordHash, err := utils.HashPassword(req.NewPassword)
+	if err != nil {
+		return fmt.Errorf("failed to hash new password: %w", err)
+	}
+	if err := s.repo.UpdateUserPassword(userID, passwordHash); err != nil {
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+
+	// Whoever had the old password must not stay logged in
+	if err := s.RevokeAllSessions(ctx, userID); err != nil {
+		fmt.Printf("Warning: failed to revoke sessions for %s: %v\n", userID, err)
+	}
+	// Proving ownership lifts a failed-login lockout
+	if err := clearLoginFailures(ctx, accountSubject(userID)); err != nil {
+		fmt.Printf("Warning: failed to clear login failures for %s: %v\n", userID, err)
+	}
+
+	s.audit(ctx, userEvent(audit.ActionPasswordReset, userID, userID))
+
+	return nil
+}
+
+// findUserForReset looks up the account a reset request refers to
+func (s *Service) findUserForReset(emailOrPhone string) (*models.User, error) {
+	if utils.ValidateEmail(emailOrPhone) {
+		return s.repo.GetUserByEmail(emailOrPhone)
+	}
+	if utils.ValidatePhone(emailOrPhone) {
+		return s.repo.GetUserByPhone(emailOrPhone)
+	}
+	return nil, appErrors.ErrNotFound
+}
+
+// generateResetToken returns a random 256-bit token, hex encoded
+func generateResetToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,186 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/yourusername/car-reselling-backend/internal/config"
+	"github.com/yourusername/car-reselling-backend/internal/database"
+	appErrors "github.com/yourusername/car-reselling-backend/pkg/errors"
+	"github.com/yourusername/car-reselling-backend/pkg/utils"
+)
+
+// RateLimitPolicy allows Limit requests per client in any sliding Window.
+// A zero Limit disables rate limiting.
+type RateLimitPolicy struct {
+	Name   string
+	Limit  int
+	Window time.Duration
+}
+
+// RateLimitPolicies maps routes to policies. Routes are keyed by method and
+// gin route pattern, e.g. "POST /api/auth/send-otp" or "GET /api/cars/:id".
+// Routes without their own policy share the Default budget.
+type RateLimitPolicies struct {
+	Default RateLimitPolicy
+	Routes  map[string]RateLimitPolicy
+}
+
+// DefaultRateLimitPolicies returns the API's rate limits. Endpoints that send
+// SMS/email, check passwords or store files get their own, stricter budgets.
+func DefaultRateLimitPolicies() RateLimitPolicies {
+	unlimited := RateLimitPolicy{Name: "unlimited"}
+	otp := RateLimitPolicy{Name: "otp", Limit: 5, Window: 15 * time.Minute}
+	login := RateLimitPolicy{Name: "login", Limit: 20, Window: time.Minute}
+	upload := RateLimitPolicy{Name: "upload", Limit: 30, Window: time.Minute}
+
+	return RateLimitPolicies{
+		Default: RateLimitPolicy{Name: "default", Limit: 100, Window: time.Minute},
+		Routes: map[string]RateLimitPolicy{
+			"GET /health":                       unlimited,
+			"GET /swagger/*any":                 unlimited,
+			"GET /api/chat/ws":                  unlimited,
+			"POST /api/payments/webhook":        unlimited, // Signed; provider retries must not be throttled
+			"POST /api/upload":                  upload,
+			"POST /api/test/upload":             upload,
+			"POST /api/dealers/me/logo":         upload,
+			"POST /api/dealers/me/verification": upload,
+
+			"POST /api/auth/send-otp":               otp,
+			"POST /api/auth/forgot-password":        otp,
+			"POST /api/auth/verify-email/resend":    otp,
+			"POST /api/auth/login":                  login,
+			"POST /api/auth/login/2fa":              login,
+			"POST /api/auth/verify-otp":             login,
+			"POST /api/auth/forgot-password/verify": login,
+			"POST /api/auth/register":               {Name: "register", Limit: 10, Window: time.Hour},
+		},
+	}
+}
+
+// policyFor returns the policy of a route
+func (p RateLimitPolicies) policyFor(method, route string) RateLimitPolicy {
+	if policy, ok := p.Routes[method+" "+route]; ok {
+		return policy
+	}
+	return p.Default
+}
+
+// rateLimitResult is the outcome of counting one request
+type rateLimitResult struct {
+	Allowed   bool
+	Count     int
+	ResetTime time.Duration // until the oldest counted request leaves the window
+}
+
+// slidingWindowScript keeps a sorted set of request timestamps (ms) per client
+// and route policy. Runs atomically, using the Redis clock so all replicas agree.
+// KEYS[1] = key, ARGV[1] = window (ms), ARGV[2] = limit, ARGV[3] = random suffix
+var slidingWindowScript = redis.NewScript(`
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local window = tonumber(ARGV[1])
+local limit = tonumber(ARGV[2])
+
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now - window)
+local count = redis.call('ZCARD', KEYS[1])
+local allowed = 0
+if count < limit then
+	redis.call('ZADD', KEYS[1], now, now .. '-' .. ARGV[3])
+	count = count + 1
+	allowed = 1
+end
+redis.call('PEXPIRE', KEYS[1], window)
+
+local oldest = redis.call('ZRANGE', KEYS[1], 0, 0, 'WITHSCORES')
+local reset = window
+if oldest[2] then
+	reset = tonumber(oldest[2]) + window - now
+end
+return {allowed, count, reset}
+`)
+
+// countRequest records a request against a sliding window. It is a variable so
+// tests can run without Redis.
+var countRequest = func(ctx context.Context, key string, policy RateLimitPolicy) (rateLimitResult, error) {
+	res, err := slidingWindowScript.Run(ctx, database.RedisClient, []string{key},
+		policy.Window.Milliseconds(), policy.Limit, rand.Int63()).Int64Slice()
+	if err != nil {
+		return rateLimitResult{}, err
+	}
+	return rateLimitResult{
+		Allowed:   res[0] == 1,
+		Count:     int(res[1]),
+		ResetTime: time.Duration(res[2]) * time.Millisecond,
+	}, nil
+}
+
+// RateLimitMiddleware limits requests per client with a sliding window per
+// route policy. Clients are identified by user ID when they send a valid access
+// token, and by IP otherwise. Responses carry RateLimit-Limit,
+// RateLimit-Remaining and RateLimit-Reset headers, plus Retry-After when a
+// request is rejected. Redis errors let requests through.
+func RateLimitMiddleware(cfg *config.Config, policies RateLimitPolicies) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		policy := policies.policyFor(c.Request.Method, c.FullPath())
+		if policy.Limit <= 0 {
+			c.Next()
+			return
+		}
+
+		key := fmt.Sprintf("rate_limit:%s:%s", policy.Name, rateLimitClient(c, cfg))
+		result, err := countRequest(c.Request.Context(), key, policy)
+		if err != nil {
+			log.Printf("Rate limiter unavailable: %v", err)
+			c.Next()
+			return
+		}
+
+		remaining := policy.Limit - result.Count
+		if remaining < 0 || !result.Allowed {
+			remaining = 0
+		}
+		reset := strconv.Itoa(ceilSeconds(result.ResetTime))
+		c.Header("RateLimit-Limit", strconv.Itoa(policy.Limit))
+		c.Header("RateLimit-Remaining", strconv.Itoa(remaining))
+		c.Header("RateLimit-Reset", reset)
+		c.Header("RateLimit-Policy", fmt.Sprintf("%d;w=%d", policy.Limit, ceilSeconds(policy.Window)))
+
+		if !result.Allowed {
+			c.Header("Retry-After", reset)
+			appErrors.HandleErrorWithMessage(c, http.StatusTooManyRequests, "Too many requests. Please try again later.")
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// rateLimitClient identifies the client: "user:<id>" for a valid access token,
+// "ip:<address>" otherwise. Revocation isn't checked here; AuthMiddleware does.
+func rateLimitClient(c *gin.Context, cfg *config.Config) string {
+	if token := requestToken(c); token != "" {
+		if claims, err := utils.ValidateToken(token, cfg.JWTSecret); err == nil && claims.UserID != "" {
+			return "user:" + claims.UserID
+		}
+	}
+	return "ip:" + c.ClientIP()
+}
+
+// ceilSeconds rounds a duration up to whole seconds, at least 1
+func ceilSeconds(d time.Duration) int {
+	s := int((d + time.Second - 1) / time.Second)
+	if s < 1 {
+		return 1
+	}
+	return s
+}
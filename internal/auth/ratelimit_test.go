@@ -0,0 +1,116 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/yourusername/car-reselling-backend/internal/config"
+	"github.com/yourusername/car-reselling-backend/internal/models"
+	"github.com/yourusername/car-reselling-backend/pkg/utils"
+)
+
+// stubRateLimiter counts requests in memory (ignoring the window) and records the keys used
+func stubRateLimiter(t *testing.T) map[string]int {
+	t.Helper()
+	counts := make(map[string]int)
+
+	original := countRequest
+	countRequest = func(ctx context.Context, key string, policy RateLimitPolicy) (rateLimitResult, error) {
+		if counts[key] >= policy.Limit {
+			return rateLimitResult{Allowed: false, Count: counts[key], ResetTime: 1500 * time.Millisecond}, nil
+		}
+		counts[key]++
+		return rateLimitResult{Allowed: true, Count: counts[key], ResetTime: policy.Window}, nil
+	}
+	t.Cleanup(func() { countRequest = original })
+	return counts
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	counts := stubRateLimiter(t)
+	cfg := &config.Config{JWTSecret: "test-secret"}
+
+	policies := RateLimitPolicies{
+		Default: RateLimitPolicy{Name: "default", Limit: 5, Window: time.Minute},
+		Routes: map[string]RateLimitPolicy{
+			"POST /otp":   {Name: "otp", Limit: 2, Window: time.Minute},
+			"GET /health": {Name: "unlimited"},
+		},
+	}
+
+	router := gin.New()
+	router.Use(RateLimitMiddleware(cfg, policies))
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	router.POST("/otp", ok)
+	router.GET("/cars/:id", ok)
+	router.GET("/health", ok)
+
+	do := func(method, path, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// Stricter route policy, with its own budget
+	for i := 0; i < 2; i++ {
+		if rec := do(http.MethodPost, "/otp", ""); rec.Code != http.StatusOK {
+			t.Fatalf("request %d: status = %d, want 200", i+1, rec.Code)
+		}
+	}
+	rec := do(http.MethodPost, "/otp", "")
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("status = %d, want 429", rec.Code)
+	}
+	if got := rec.Header().Get("Retry-After"); got != "2" {
+		t.Errorf("Retry-After = %q, want %q", got, "2")
+	}
+	if got := rec.Header().Get("RateLimit-Remaining"); got != "0" {
+		t.Errorf("RateLimit-Remaining = %q, want %q", got, "0")
+	}
+
+	// Route patterns share the default budget
+	rec = do(http.MethodGet, "/cars/123", "")
+	if rec.Code != http.StatusOK || rec.Header().Get("RateLimit-Limit") != "5" || rec.Header().Get("RateLimit-Remaining") != "4" {
+		t.Errorf("default policy: status = %d, headers = %v", rec.Code, rec.Header())
+	}
+
+	// Exempt routes are not counted
+	for i := 0; i < 10; i++ {
+		if rec := do(http.MethodGet, "/health", ""); rec.Code != http.StatusOK {
+			t.Fatalf("health: status = %d, want 200", rec.Code)
+		}
+	}
+
+	// Authenticated clients are counted by user, not IP
+	token, err := utils.GenerateAccessToken(testUserID, "user@example.com", models.RoleUser, "session-1", "token-1", cfg.JWTSecret)
+	if err != nil {
+		t.Fatalf("GenerateAccessToken: %v", err)
+	}
+	if rec := do(http.MethodPost, "/otp", token); rec.Code != http.StatusOK {
+		t.Errorf("authenticated: status = %d, want 200", rec.Code)
+	}
+
+	want := map[string]int{
+		"rate_limit:otp:ip:192.0.2.1":       2,
+		"rate_limit:default:ip:192.0.2.1":   1,
+		"rate_limit:otp:user:" + testUserID: 1,
+	}
+	if len(counts) != len(want) {
+		t.Errorf("counted keys = %v, want %v", counts, want)
+	}
+	for key, n := range want {
+		if counts[key] != n {
+			t.Errorf("count[%q] = %d, want %d", key, counts[key], n)
+		}
+	}
+}
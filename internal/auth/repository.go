@@ -138,3 +138,85 @@ func (r *Repository) UpdateUserPassword(userID string, passwordHash string) erro
 	}
 	return nil
 }
+
+// EnableTwoFactor stores the encrypted TOTP secret and the user's recovery codes
+func (r *Repository) EnableTwoFactor(userID uuid.UUID, encryptedSecret string, codeHashes []string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+			"two_factor_enabled":    true,
+			"two_factor_enabled_at": time.Now(),
+			"totp_secret":           encryptedSecret,
+		}).Error; err != nil {
+			return err
+		}
+		return replaceRecoveryCodes(tx, userID, codeHashes)
+	})
+}
+
+// DisableTwoFactor removes the TOTP secret and all recovery codes
+func (r *Repository) DisableTwoFactor(userID uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+			"two_factor_enabled":    false,
+			"two_factor_enabled_at": nil,
+			"totp_secret":           nil,
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&models.UserRecoveryCode{}).Error
+	})
+}
+
+// ReplaceRecoveryCodes invalidates the user's recovery codes and stores new ones
+func (r *Repository) ReplaceRecoveryCodes(userID uuid.UUID, codeHashes []string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return replaceRecoveryCodes(tx, userID, codeHashes)
+	})
+}
+
+func replaceRecoveryCodes(tx *gorm.DB, userID uuid.UUID, codeHashes []string) error {
+	if err := tx.Where("user_id = ?", userID).Delete(&models.UserRecoveryCode{}).Error; err != nil {
+		return err
+	}
+	codes := make([]models.UserRecoveryCode, len(codeHashes))
+	for i, hash := range codeHashes {
+		codes[i] = models.UserRecoveryCode{ID: uuid.New(), UserID: userID, CodeHash: hash}
+	}
+	return tx.Create(&codes).Error
+}
+
+// UseRecoveryCode marks an unused recovery code as used. It reports false if
+// the code doesn't exist or was already used.
+func (r *Repository) UseRecoveryCode(userID uuid.UUID, codeHash string) (bool, error) {
+	result := r.db.Model(&models.UserRecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", time.Now())
+	return result.RowsAffected > 0, result.Error
+}
+
+// ListUsers retrieves a filtered, paginated list of users
+func (r *Repository) ListUsers(q *ListUsersQuery) ([]models.User, int64, error) {
+	query := r.db.Model(&models.User{})
+	if q.Query != "" {
+		like := "%" + q.Query + "%"
+		query = query.Where("full_name ILIKE ? OR email ILIKE ? OR phone ILIKE ?", like, like, like)
+	}
+	if q.Role != "" {
+		query = query.Where("role = ?", q.Role)
+	}
+	if q.Active != nil {
+		query = query.Where("is_active = ?", *q.Active)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var users []models.User
+	err := query.Order("created_at DESC").
+		Offset((q.Page - 1) * q.Limit).
+		Limit(q.Limit).
+		Find(&users).Error
+	return users, total, err
+}
@@ -3,8 +3,12 @@ package auth
 import (
 	"context"
 	"fmt"
+	"log"
 	"time"
 
+	"github.com/google/uuid"
+
+	"github.com/yourusername/car-reselling-backend/internal/audit"
 	"github.com/yourusername/car-reselling-backend/internal/config"
 	"github.com/yourusername/car-reselling-backend/internal/database"
 	"github.com/yourusername/car-reselling-backend/internal/models"
@@ -14,8 +18,17 @@ import (
 
 // Service handles authentication business logic
 type Service struct {
-	repo   *Repository
-	config *config.Config
+	repo      *Repository
+	config    *config.Config
+	otpSender OTPSender
+	mailer    Mailer
+	notifier  NotificationSender
+	auditLog  AuditLogger
+}
+
+// AuditLogger records security-relevant actions
+type AuditLogger interface {
+	Record(ctx context.Context, event audit.Event)
 }
 
 // NewService creates a new authentication service
@@ -26,6 +39,33 @@ func NewService(repo *Repository, cfg *config.Config) *Service {
 	}
 }
 
+// SetOTPSender sets the provider used to deliver OTP codes
+func (s *Service) SetOTPSender(sender OTPSender) {
+	s.otpSender = sender
+}
+
+// SetAuditLogger sets the audit log for account and admin actions
+func (s *Service) SetAuditLogger(l AuditLogger) {
+	s.auditLog = l
+}
+
+// audit records an event if an audit log is set
+func (s *Service) audit(ctx context.Context, event audit.Event) {
+	if s.auditLog != nil {
+		s.auditLog.Record(ctx, event)
+	}
+}
+
+// userEvent builds an audit event on a user account. actorID may be empty for
+// anonymous requests.
+func userEvent(action, actorID, userID string) audit.Event {
+	event := audit.Event{Action: action, TargetType: audit.TargetUser, TargetID: userID}
+	if id, err := uuid.Parse(actorID); err == nil {
+		event.ActorID = &id
+	}
+	return event
+}
+
 // Register handles user registration
 func (s *Service) Register(ctx context.Context, req *RegisterRequest) error {
 	// Validate input with specific error messages
@@ -63,18 +103,35 @@ func (s *Service) Register(ctx context.Context, req *RegisterRequest) error {
 		FullName:     utils.SanitizeString(req.FullName),
 		IsVerified:   true, // Auto-verify users (OTP disabled)
 		IsActive:     true, // Auto-activate users
+		Role:         models.RoleUser,
 	}
 
 	if err := s.repo.CreateUser(user); err != nil {
 		return err
 	}
 
-	// OTP disabled - users are immediately verified
+	// OTP disabled - users are immediately verified. The email address is
+	// verified separately through the link sent here.
+	if s.mailer != nil {
+		go func() {
+			if err := s.sendVerificationEmail(context.Background(), user); err != nil {
+				log.Printf("Verification email to user %s failed: %v", user.ID, err)
+			}
+		}()
+	}
+
 	return nil
 }
 
-// Login handles user login
-func (s *Service) Login(ctx context.Context, req *LoginRequest) (*AuthResponse, error) {
+// Login handles user login. Failed attempts are throttled per account and
+// per IP (see lockout.go). Users with two-factor authentication get a
+// challenge instead of tokens, to be completed with VerifyTwoFactorLogin.
+func (s *Service) Login(ctx context.Context, req *LoginRequest, meta SessionMeta) (*AuthResponse, *TwoFactorChallengeResponse, error) {
+	ip := ipSubject(meta.IPAddress)
+	if err := checkLoginThrottle(ctx, ip); err != nil {
+		return nil, nil, err
+	}
+
 	// Get user by email or phone
 	var user *models.User
 	var err error
@@ -84,43 +141,70 @@ func (s *Service) Login(ctx context.Context, req *LoginRequest) (*AuthResponse,
 	} else if utils.ValidatePhone(req.EmailOrPhone) {
 		user, err = s.repo.GetUserByPhone(req.EmailOrPhone)
 	} else {
-		return nil, appErrors.ErrInvalidCredentials
+		return nil, nil, appErrors.ErrInvalidCredentials
 	}
 
 	if err != nil {
-		return nil, appErrors.ErrInvalidCredentials
+		// Unknown accounts are throttled like real ones
+		account := unknownAccountSubject(req.EmailOrPhone)
+		if err := checkLoginThrottle(ctx, account); err != nil {
+			return nil, nil, err
+		}
+		recordLoginFailure(ctx, account)
+		recordLoginFailure(ctx, ip)
+
+		event := userEvent(audit.ActionLoginFailed, "", "")
+		event.Metadata = audit.Metadata{"identifier": req.EmailOrPhone, "reason": "unknown_account"}
+		s.audit(ctx, event)
+		return nil, nil, appErrors.ErrInvalidCredentials
+	}
+
+	account := accountSubject(user.ID.String())
+	if err := checkLoginThrottle(ctx, account); err != nil {
+		return nil, nil, err
 	}
 
 	// Check password
 	if !utils.CheckPassword(req.Password, user.PasswordHash) {
-		return nil, appErrors.ErrInvalidCredentials
+		if recordLoginFailure(ctx, account) {
+			go s.notifyAccountLocked(user, meta.IPAddress)
+		}
+		recordLoginFailure(ctx, ip)
+
+		event := userEvent(audit.ActionLoginFailed, "", user.ID.String())
+		event.Metadata = audit.Metadata{"reason": "invalid_password"}
+		s.audit(ctx, event)
+		return nil, nil, appErrors.ErrInvalidCredentials
+	}
+	if err := clearLoginFailures(ctx, account); err != nil {
+		log.Printf("Failed to clear login failures for %s: %v", user.ID, err)
 	}
 
 	// Check if user is verified
 	if !user.IsVerified {
-		return nil, appErrors.ErrUserNotVerified
+		return nil, nil, appErrors.ErrUserNotVerified
 	}
 
 	// Check if user is active
 	if !user.IsActive {
-		return nil, appErrors.ErrForbidden
+		return nil, nil, appErrors.ErrForbidden
 	}
 
-	// Generate tokens
-	accessToken, err := utils.GenerateAccessToken(user.ID.String(), user.Email, s.config.JWTSecret)
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate access token: %w", err)
+	if user.TwoFactorEnabled {
+		challenge, err := s.startTwoFactorChallenge(ctx, user, meta)
+		return nil, challenge, err
 	}
 
-	refreshToken, err := utils.GenerateRefreshToken(user.ID.String(), s.config.JWTRefreshSecret)
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
-	}
+	resp, err := s.completeLogin(ctx, user, meta)
+	return resp, nil, err
+}
 
-	// Store refresh token in Redis (30 days expiry)
-	sessionKey := fmt.Sprintf("session:%s", user.ID.String())
-	if err := database.Set(ctx, sessionKey, refreshToken, 5*365*24*time.Hour); err != nil {
-		return nil, fmt.Errorf("failed to store session: %w", err)
+// completeLogin starts a session for a fully authenticated user
+func (s *Service) completeLogin(ctx context.Context, user *models.User, meta SessionMeta) (*AuthResponse, error) {
+	// Start a session for this device
+	tokens, err := s.createSession(ctx, user, meta)
+	if err != nil {
+		return nil, err
 	}
 
 	// Update last login
@@ -131,15 +215,23 @@ func (s *Service) Login(ctx context.Context, req *LoginRequest) (*AuthResponse,
 		fmt.Printf("Warning: failed to update last login: %v\n", err)
 	}
 
+	event := userEvent(audit.ActionLogin, user.ID.String(), user.ID.String())
+	event.IPAddress = meta.IPAddress
+	event.UserAgent = meta.UserAgent
+	event.Metadata = audit.Metadata{"device_name": meta.DeviceName, "two_factor": user.TwoFactorEnabled}
+	s.audit(ctx, event)
+
 	return &AuthResponse{
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
+		AccessToken:  tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    tokens.ExpiresIn,
 		User:         s.userToDTO(user),
 	}, nil
 }
 
-// SendOTP sends an OTP to the phone number
-func (s *Service) SendOTP(ctx context.Context, phone string) error {
+// SendOTP sends an OTP to the phone number, in the given locale when a
+// template exists for it
+func (s *Service) SendOTP(ctx context.Context, phone, locale string) error {
 	// Validate phone format
 	if !utils.ValidatePhone(phone) {
 		return appErrors.ErrInvalidPhone
@@ -170,27 +262,36 @@ func (s *Service) SendOTP(ctx context.Context, phone string) error {
 		database.Set(ctx, rateLimitKey, "1", time.Hour)
 	}
 
-	// Send via Twilio
-	if s.config.TwilioAccountSID != "" && s.config.TwilioAuthToken != "" {
-		if err := utils.SendOTP(
-			phone,
-			otpCode,
-			s.config.TwilioAccountSID,
-			s.config.TwilioAuthToken,
-			s.config.TwilioPhoneNumber,
-		); err != nil {
-			// Log error but don't fail (for development, OTP might be in logs)
-			fmt.Printf("Warning: failed to send OTP via Twilio: %v\n", err)
-			fmt.Printf("OTP for %s: %s\n", phone, otpCode) // For development
-		}
-	} else {
-		// Development mode: print OTP to console
-		fmt.Printf("OTP for %s: %s\n", phone, otpCode)
+	err = s.deliverOTP(ctx, OTPMessage{
+		Phone:   phone,
+		Code:    otpCode,
+		Purpose: OTPPurposeVerification,
+		Locale:  locale,
+		TTL:     10 * time.Minute,
+	})
+	if err != nil {
+		// The user never got this code, so don't leave it verifiable
+		database.Delete(ctx, otpKey)
+		return err
 	}
 
 	return nil
 }
 
+// deliverOTP sends an OTP through the configured provider. Provider errors are
+// logged (without the code) and reported as ErrOTPDeliveryFailed.
+func (s *Service) deliverOTP(ctx context.Context, msg OTPMessage) error {
+	if s.otpSender == nil {
+		log.Printf("OTP delivery to %s failed: no OTP provider configured", msg.Phone)
+		return appErrors.ErrOTPDeliveryFailed
+	}
+	if err := s.otpSender.Send(ctx, msg); err != nil {
+		log.Printf("OTP delivery to %s failed: %v", msg.Phone, err)
+		return appErrors.ErrOTPDeliveryFailed
+	}
+	return nil
+}
+
 // VerifyOTP verifies an OTP code
 func (s *Service) VerifyOTP(ctx context.Context, phone, code string) error {
 	// Get OTP from Redis
@@ -238,40 +339,16 @@ func (s *Service) VerifyOTP(ctx context.Context, phone, code string) error {
 	return nil
 }
 
-// RefreshToken refreshes an access token using a refresh token
-func (s *Service) RefreshToken(ctx context.Context, refreshToken string) (string, error) {
-	// Validate refresh token
-	claims, err := utils.ValidateToken(refreshToken, s.config.JWTRefreshSecret)
-	if err != nil {
-		return "", appErrors.ErrInvalidToken
-	}
-
-	// Check if session exists in Redis
-	sessionKey := fmt.Sprintf("session:%s", claims.UserID)
-	exists, err := database.Exists(ctx, sessionKey)
-	if err != nil || !exists {
-		return "", appErrors.ErrInvalidToken
-	}
-
-	// Get user
-	user, err := s.repo.GetUserByID(claims.UserID)
-	if err != nil {
-		return "", err
-	}
-
-	// Generate new access token
-	accessToken, err := utils.GenerateAccessToken(user.ID.String(), user.Email, s.config.JWTSecret)
-	if err != nil {
-		return "", fmt.Errorf("failed to generate access token: %w", err)
+// Logout ends the current session
+func (s *Service) Logout(ctx context.Context, userID, sessionID string) error {
+	if err := s.revokeSession(ctx, userID, sessionID); err != nil && err != appErrors.ErrNotFound {
+		return err
 	}
 
-	return accessToken, nil
-}
-
-// Logout logs out a user by removing their session
-func (s *Service) Logout(ctx context.Context, userID string) error {
-	sessionKey := fmt.Sprintf("session:%s", userID)
-	return database.Delete(ctx, sessionKey)
+	event := userEvent(audit.ActionLogout, userID, userID)
+	event.Metadata = audit.Metadata{"session_id": sessionID}
+	s.audit(ctx, event)
+	return nil
 }
 
 // GetCurrentUser retrieves the current user by ID
@@ -286,17 +363,25 @@ func (s *Service) GetCurrentUser(userID string) (*UserDTO, error) {
 
 // userToDTO converts a User model to UserDTO
 func (s *Service) userToDTO(user *models.User) UserDTO {
-	return UserDTO{
-		ID:              user.ID.String(),
-		Email:           user.Email,
-		Phone:           user.Phone,
-		FullName:        user.FullName,
-		Gender:          user.Gender,
-		DOB:             formatTime(user.DOB),
-		ProfilePhotoURL: user.ProfilePhotoURL,
-		IsVerified:      user.IsVerified,
-		IsDealer:        user.IsDealer,
-	}
+	dto := UserDTO{
+		ID:               user.ID.String(),
+		Email:            user.Email,
+		Phone:            user.Phone,
+		FullName:         user.FullName,
+		Gender:           user.Gender,
+		DOB:              formatTime(user.DOB),
+		ProfilePhotoURL:  user.ProfilePhotoURL,
+		IsVerified:       user.IsVerified,
+		EmailVerified:    user.EmailVerified,
+		TwoFactorEnabled: user.TwoFactorEnabled,
+		IsDealer:         user.IsDealer,
+		Role:             user.Role,
+	}
+	if user.DeletionScheduledAt != nil {
+		scheduled := user.DeletionScheduledAt.Format(time.RFC3339)
+		dto.DeletionScheduledAt = &scheduled
+	}
+	return dto
 }
 
 func formatTime(t *time.Time) *string {
@@ -314,6 +399,7 @@ func (s *Service) UpdateProfile(ctx context.Context, userID string, req *UpdateP
 	if err != nil {
 		return nil, err
 	}
+	before := s.userToDTO(user)
 
 	// Update fields if provided
 	if req.FullName != nil {
@@ -338,12 +424,17 @@ func (s *Service) UpdateProfile(ctx context.Context, userID string, req *UpdateP
 	}
 
 	dto := s.userToDTO(user)
+	if changes := audit.Diff(before, dto); changes != nil {
+		event := userEvent(audit.ActionProfileUpdated, userID, userID)
+		event.Changes = changes
+		s.audit(ctx, event)
+	}
 	return &dto, nil
 }
 
-// ChangePassword changes the user's password
+// ChangePassword changes the user's password and logs out their other sessions
 // Security: Verifies current password, validates new password strength, ensures new != current
-func (s *Service) ChangePassword(ctx context.Context, userID string, req *ChangePasswordRequest) error {
+func (s *Service) ChangePassword(ctx context.Context, userID, sessionID string, req *ChangePasswordRequest) error {
 	// Get user from database
 	user, err := s.repo.GetUserByID(userID)
 	if err != nil {
@@ -376,5 +467,113 @@ func (s *Service) ChangePassword(ctx context.Context, userID string, req *Change
 		return fmt.Errorf("failed to update password: %w", err)
 	}
 
+	// Other devices must log in again with the new password
+	if _, err := s.RevokeOtherSessions(ctx, userID, sessionID); err != nil {
+		fmt.Printf("Warning: failed to revoke other sessions for %s: %v\n", userID, err)
+	}
+
+	s.audit(ctx, userEvent(audit.ActionPasswordChanged, userID, userID))
 	return nil
 }
+
+// --- Admin: User Management ---
+
+// ListUsers returns a filtered, paginated list of users
+func (s *Service) ListUsers(ctx context.Context, q *ListUsersQuery) (*UserListResponse, error) {
+	users, total, err := s.repo.ListUsers(q)
+	if err != nil {
+		return nil, err
+	}
+
+	dtos := make([]AdminUserDTO, len(users))
+	for i := range users {
+		dtos[i] = s.userToAdminDTO(&users[i])
+	}
+
+	return &UserListResponse{
+		Users: dtos,
+		Total: total,
+		Page:  q.Page,
+		Limit: q.Limit,
+	}, nil
+}
+
+// UpdateUserRole changes a user's role. The user's sessions are revoked so the
+// new role is picked up on their next login.
+func (s *Service) UpdateUserRole(ctx context.Context, actorID, userID, role string) (*AdminUserDTO, error) {
+	if !models.IsValidRole(role) {
+		return nil, appErrors.ErrInvalidRole
+	}
+	if actorID == userID {
+		return nil, appErrors.ErrCannotModifySelf
+	}
+
+	user, err := s.repo.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	oldRole := user.Role
+	user.Role = role
+	user.IsDealer = role == models.RoleDealer
+	if err := s.repo.UpdateUser(user); err != nil {
+		return nil, err
+	}
+
+	event := userEvent(audit.ActionUserRoleChanged, actorID, userID)
+	event.Changes = audit.Changes{"role": {Before: oldRole, After: role}}
+	s.audit(ctx, event)
+
+	if err := s.RevokeAllSessions(ctx, userID); err != nil {
+		fmt.Printf("Warning: failed to revoke sessions for %s: %v\n", userID, err)
+	}
+
+	dto := s.userToAdminDTO(user)
+	return &dto, nil
+}
+
+// UpdateUserStatus activates or deactivates a user. Deactivated users cannot
+// log in and their sessions are revoked.
+func (s *Service) UpdateUserStatus(ctx context.Context, actorID, userID string, isActive bool) (*AdminUserDTO, error) {
+	if actorID == userID {
+		return nil, appErrors.ErrCannotModifySelf
+	}
+
+	user, err := s.repo.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	wasActive := user.IsActive
+	user.IsActive = isActive
+	if err := s.repo.UpdateUser(user); err != nil {
+		return nil, err
+	}
+
+	event := userEvent(audit.ActionUserStatusChanged, actorID, userID)
+	event.Changes = audit.Changes{"is_active": {Before: wasActive, After: isActive}}
+	s.audit(ctx, event)
+
+	if !isActive {
+		if err := s.RevokeAllSessions(ctx, userID); err != nil {
+			fmt.Printf("Warning: failed to revoke sessions for %s: %v\n", userID, err)
+		}
+	}
+
+	dto := s.userToAdminDTO(user)
+	return &dto, nil
+}
+
+// userToAdminDTO converts a User model to AdminUserDTO
+func (s *Service) userToAdminDTO(user *models.User) AdminUserDTO {
+	dto := AdminUserDTO{
+		UserDTO:   s.userToDTO(user),
+		IsActive:  user.IsActive,
+		CreatedAt: user.CreatedAt.Format(time.RFC3339),
+	}
+	if user.LastLoginAt != nil {
+		lastLogin := user.LastLoginAt.Format(time.RFC3339)
+		dto.LastLoginAt = &lastLogin
+	}
+	return dto
+}
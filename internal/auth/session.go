@@ -0,0 +1,374 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/yourusername/car-reselling-backend/internal/audit"
+	"github.com/yourusername/car-reselling-backend/internal/database"
+	"github.com/yourusername/car-reselling-backend/internal/models"
+	appErrors "github.com/yourusername/car-reselling-backend/pkg/errors"
+	"github.com/yourusername/car-reselling-backend/pkg/utils"
+)
+
+// Session is one logged-in device. Each session is a refresh token family:
+// refresh tokens rotate on every use, and presenting an already-used one
+// revokes the whole session.
+type Session struct {
+	ID         string    `json:"id" example:"3f1c2a9e-8d4b-4a7e-9c61-0b5e2f7d9a10"`
+	DeviceName string    `json:"device_name" example:"Pixel 8"`
+	UserAgent  string    `json:"user_agent" example:"okhttp/4.12.0"`
+	IPAddress  string    `json:"ip_address" example:"203.0.113.7"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	Current    bool      `json:"current" example:"true"` // The session making the request
+}
+
+// SessionMeta describes the device a session is created from
+type SessionMeta struct {
+	DeviceName string
+	UserAgent  string
+	IPAddress  string
+}
+
+// sessionRecord is the stored form of a session
+type sessionRecord struct {
+	ID              string    `json:"id"`
+	UserID          string    `json:"user_id"`
+	DeviceName      string    `json:"device_name"`
+	UserAgent       string    `json:"user_agent"`
+	IPAddress       string    `json:"ip_address"`
+	CreatedAt       time.Time `json:"created_at"`
+	LastUsedAt      time.Time `json:"last_used_at"`
+	RefreshTokenID  string    `json:"refresh_jti"` // Only this refresh token may be used next
+	AccessTokenID   string    `json:"access_jti"`  // Denylisted when the session is revoked
+	AccessExpiresAt time.Time `json:"access_expires_at"`
+}
+
+func (r *sessionRecord) toSession() Session {
+	return Session{
+		ID:         r.ID,
+		DeviceName: r.DeviceName,
+		UserAgent:  r.UserAgent,
+		IPAddress:  r.IPAddress,
+		CreatedAt:  r.CreatedAt,
+		LastUsedAt: r.LastUsedAt,
+	}
+}
+
+// errSessionChanged is returned when a session is rotated concurrently
+var errSessionChanged = errors.New("session changed during refresh")
+
+// Redis keys
+func sessionKey(sessionID string) string    { return fmt.Sprintf("auth:session:%s", sessionID) }
+func userSessionsKey(userID string) string  { return fmt.Sprintf("auth:user_sessions:%s", userID) }
+func revokedTokenKey(tokenID string) string { return fmt.Sprintf("auth:revoked:%s", tokenID) }
+func legacySessionKey(userID string) string { return fmt.Sprintf("session:%s", userID) }
+
+// isTokenRevoked reports whether an access token's jti is on the denylist.
+// A variable so middleware tests can run without Redis.
+var isTokenRevoked = func(ctx context.Context, tokenID string) (bool, error) {
+	return database.Exists(ctx, revokedTokenKey(tokenID))
+}
+
+// --- Session lifecycle ---
+
+// createSession starts a new session for the user and issues its first token pair
+func (s *Service) createSession(ctx context.Context, user *models.User, meta SessionMeta) (*TokenResponse, error) {
+	now := time.Now()
+	rec := &sessionRecord{
+		ID:         uuid.NewString(),
+		UserID:     user.ID.String(),
+		DeviceName: meta.DeviceName,
+		UserAgent:  meta.UserAgent,
+		IPAddress:  meta.IPAddress,
+		CreatedAt:  now,
+	}
+	if rec.DeviceName == "" {
+		rec.DeviceName = "Unknown device"
+	}
+
+	tokens, err := s.rotateTokens(rec, user)
+	if err != nil {
+		return nil, err
+	}
+
+	pipe := database.RedisClient.TxPipeline()
+	if err := saveSession(ctx, pipe, rec); err != nil {
+		return nil, err
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, fmt.Errorf("failed to store session: %w", err)
+	}
+
+	return tokens, nil
+}
+
+// rotateTokens issues a new access/refresh token pair and records their IDs on the session
+func (s *Service) rotateTokens(rec *sessionRecord, user *models.User) (*TokenResponse, error) {
+	now := time.Now()
+	rec.RefreshTokenID = uuid.NewString()
+	rec.AccessTokenID = uuid.NewString()
+	rec.AccessExpiresAt = now.Add(utils.AccessTokenTTL)
+	rec.LastUsedAt = now
+
+	accessToken, err := utils.GenerateAccessToken(user.ID.String(), user.Email, user.Role, rec.ID, rec.AccessTokenID, s.config.JWTSecret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate access token: %w", err)
+	}
+
+	refreshToken, err := utils.GenerateRefreshToken(user.ID.String(), rec.ID, rec.RefreshTokenID, s.config.JWTRefreshSecret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+
+	return &TokenResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(utils.AccessTokenTTL.Seconds()),
+	}, nil
+}
+
+// RefreshToken rotates a session's tokens. A refresh token that was already
+// used revokes its whole session, since it has most likely been stolen.
+func (s *Service) RefreshToken(ctx context.Context, refreshToken string) (*TokenResponse, error) {
+	claims, err := utils.ValidateRefreshToken(refreshToken, s.config.JWTRefreshSecret)
+	if err != nil {
+		return nil, appErrors.ErrInvalidToken
+	}
+
+	// Tokens issued before per-device sessions: migrate into a new session once
+	if claims.SessionID == "" {
+		return s.migrateLegacySession(ctx, claims.UserID, refreshToken)
+	}
+
+	var tokens *TokenResponse
+	key := sessionKey(claims.SessionID)
+
+	// Optimistic lock so two concurrent refreshes can't both rotate the same token
+	err = database.RedisClient.Watch(ctx, func(tx *redis.Tx) error {
+		rec, err := loadSession(ctx, tx, claims.SessionID)
+		if err != nil {
+			return err
+		}
+		if rec.UserID != claims.UserID {
+			return appErrors.ErrInvalidToken
+		}
+
+		if claims.ID != rec.RefreshTokenID {
+			log.Printf("Refresh token reuse detected for session %s (user %s), revoking session", rec.ID, rec.UserID)
+			if err := s.revokeSessions(ctx, rec.UserID, []*sessionRecord{rec}); err != nil {
+				log.Printf("Failed to revoke session %s: %v", rec.ID, err)
+			}
+			return appErrors.ErrInvalidToken
+		}
+
+		user, err := s.repo.GetUserByID(claims.UserID)
+		if err != nil {
+			return appErrors.ErrInvalidToken
+		}
+		if !user.IsActive {
+			return appErrors.ErrForbidden
+		}
+
+		tokens, err = s.rotateTokens(rec, user)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			return saveSession(ctx, pipe, rec)
+		})
+		if errors.Is(err, redis.TxFailedErr) {
+			return errSessionChanged
+		}
+		return err
+	}, key)
+
+	if errors.Is(err, errSessionChanged) {
+		return nil, appErrors.ErrInvalidToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+// migrateLegacySession exchanges a pre-session refresh token for a new session
+func (s *Service) migrateLegacySession(ctx context.Context, userID, refreshToken string) (*TokenResponse, error) {
+	stored, err := database.Get(ctx, legacySessionKey(userID))
+	if err != nil || stored != refreshToken {
+		return nil, appErrors.ErrInvalidToken
+	}
+	if err := database.Delete(ctx, legacySessionKey(userID)); err != nil {
+		return nil, err
+	}
+
+	user, err := s.repo.GetUserByID(userID)
+	if err != nil {
+		return nil, appErrors.ErrInvalidToken
+	}
+	if !user.IsActive {
+		return nil, appErrors.ErrForbidden
+	}
+
+	return s.createSession(ctx, user, SessionMeta{})
+}
+
+// --- Session management ---
+
+// ListSessions returns the user's active sessions, most recently used first
+func (s *Service) ListSessions(ctx context.Context, userID, currentSessionID string) ([]Session, error) {
+	records, err := s.loadUserSessions(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make([]Session, 0, len(records))
+	for _, rec := range records {
+		session := rec.toSession()
+		session.Current = rec.ID == currentSessionID
+		sessions = append(sessions, session)
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].LastUsedAt.After(sessions[j].LastUsedAt)
+	})
+	return sessions, nil
+}
+
+// RevokeSession logs out one of the user's sessions
+func (s *Service) RevokeSession(ctx context.Context, userID, sessionID string) error {
+	if err := s.revokeSession(ctx, userID, sessionID); err != nil {
+		return err
+	}
+
+	event := userEvent(audit.ActionSessionRevoked, userID, userID)
+	event.Metadata = audit.Metadata{"session_id": sessionID}
+	s.audit(ctx, event)
+	return nil
+}
+
+func (s *Service) revokeSession(ctx context.Context, userID, sessionID string) error {
+	rec, err := loadSession(ctx, database.RedisClient, sessionID)
+	if err != nil || rec.UserID != userID {
+		return appErrors.ErrNotFound
+	}
+	return s.revokeSessions(ctx, userID, []*sessionRecord{rec})
+}
+
+// RevokeOtherSessions logs out every session of the user except the current one
+func (s *Service) RevokeOtherSessions(ctx context.Context, userID, currentSessionID string) (int, error) {
+	records, err := s.loadUserSessions(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	var others []*sessionRecord
+	for _, rec := range records {
+		if rec.ID != currentSessionID {
+			others = append(others, rec)
+		}
+	}
+	if err := s.revokeSessions(ctx, userID, others); err != nil {
+		return 0, err
+	}
+
+	if len(others) > 0 {
+		event := userEvent(audit.ActionSessionRevoked, userID, userID)
+		event.Metadata = audit.Metadata{"sessions": len(others), "kept_session_id": currentSessionID}
+		s.audit(ctx, event)
+	}
+	return len(others), nil
+}
+
+// RevokeAllSessions logs the user out everywhere (role change, deactivation, ...)
+func (s *Service) RevokeAllSessions(ctx context.Context, userID string) error {
+	records, err := s.loadUserSessions(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := database.Delete(ctx, legacySessionKey(userID)); err != nil {
+		return err
+	}
+	return s.revokeSessions(ctx, userID, records)
+}
+
+// revokeSessions deletes sessions and denylists their current access tokens
+func (s *Service) revokeSessions(ctx context.Context, userID string, records []*sessionRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	pipe := database.RedisClient.TxPipeline()
+	for _, rec := range records {
+		if ttl := rec.AccessExpiresAt.Sub(now); ttl > 0 && rec.AccessTokenID != "" {
+			pipe.Set(ctx, revokedTokenKey(rec.AccessTokenID), "1", ttl)
+		}
+		pipe.Del(ctx, sessionKey(rec.ID))
+		pipe.SRem(ctx, userSessionsKey(userID), rec.ID)
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// --- Storage helpers ---
+
+// saveSession queues the writes storing a session and indexing it under its user
+func saveSession(ctx context.Context, pipe redis.Pipeliner, rec *sessionRecord) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	pipe.Set(ctx, sessionKey(rec.ID), data, utils.RefreshTokenTTL)
+	pipe.SAdd(ctx, userSessionsKey(rec.UserID), rec.ID)
+	pipe.Expire(ctx, userSessionsKey(rec.UserID), utils.RefreshTokenTTL)
+	return nil
+}
+
+// loadSession reads a session, returning ErrInvalidToken if it doesn't exist
+func loadSession(ctx context.Context, rdb redis.Cmdable, sessionID string) (*sessionRecord, error) {
+	data, err := rdb.Get(ctx, sessionKey(sessionID)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, appErrors.ErrInvalidToken
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var rec sessionRecord
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return nil, err
+	}
+	return &rec, nil
+}
+
+// loadUserSessions reads all live sessions of a user, pruning expired ones from the index
+func (s *Service) loadUserSessions(ctx context.Context, userID string) ([]*sessionRecord, error) {
+	ids, err := database.RedisClient.SMembers(ctx, userSessionsKey(userID)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	var records []*sessionRecord
+	for _, id := range ids {
+		rec, err := loadSession(ctx, database.RedisClient, id)
+		if errors.Is(err, appErrors.ErrInvalidToken) {
+			database.RedisClient.SRem(ctx, userSessionsKey(userID), id)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, rec)
+	}
+	return records, nil
+}
@@ -0,0 +1,421 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image/png"
+	"log"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/yourusername/car-reselling-backend/internal/audit"
+	"github.com/yourusername/car-reselling-backend/internal/database"
+	"github.com/yourusername/car-reselling-backend/internal/models"
+	appErrors "github.com/yourusername/car-reselling-backend/pkg/errors"
+	"github.com/yourusername/car-reselling-backend/pkg/utils"
+)
+
+const (
+	// totpIssuer is shown next to the account in authenticator apps
+	totpIssuer = "Car Reselling"
+
+	totpPeriod = 30 * time.Second
+
+	// totpSetupTTL is how long an enrollment can be confirmed
+	totpSetupTTL = 10 * time.Minute
+
+	// TwoFactorChallengeTTL is how long a login challenge can be completed
+	TwoFactorChallengeTTL = 5 * time.Minute
+
+	// maxTwoFactorAttempts caps wrong codes per login challenge
+	maxTwoFactorAttempts = 5
+
+	recoveryCodeCount = 10
+
+	// recoveryCodeAlphabet leaves out look-alike characters (0/o, 1/l/i)
+	recoveryCodeAlphabet = "abcdefghjkmnpqrstuvwxyz23456789"
+)
+
+// Redis keys for two-factor authentication
+const (
+	totpSetupPrefix          = "totp_setup:"
+	totpUsedPrefix           = "totp_used:"
+	twoFactorChallengePrefix = "2fa_challenge:"
+	twoFactorAttemptsPrefix  = "2fa_challenge_attempts:"
+)
+
+// twoFactorChallenge is the stored form of a pending two-step login
+type twoFactorChallenge struct {
+	UserID string      `json:"user_id"`
+	Meta   SessionMeta `json:"meta"`
+}
+
+// SetupTwoFactor starts TOTP enrollment. The secret is only kept for
+// totpSetupTTL until EnableTwoFactor confirms a code from the app.
+func (s *Service) SetupTwoFactor(ctx context.Context, userID string) (*TwoFactorSetupResponse, error) {
+	user, err := s.repo.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TwoFactorEnabled {
+		return nil, appErrors.ErrTwoFactorAlreadyEnabled
+	}
+
+	key, err := totp.Generate(totp.GenerateOpts{
+		Issuer:      totpIssuer,
+		AccountName: user.Email,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate TOTP secret: %w", err)
+	}
+
+	img, err := key.Image(256, 256)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate QR code: %w", err)
+	}
+	var qr bytes.Buffer
+	if err := png.Encode(&qr, img); err != nil {
+		return nil, fmt.Errorf("failed to encode QR code: %w", err)
+	}
+
+	if err := database.Set(ctx, totpSetupPrefix+userID, key.Secret(), totpSetupTTL); err != nil {
+		return nil, fmt.Errorf("failed to store TOTP setup: %w", err)
+	}
+
+	return &TwoFactorSetupResponse{
+		Secret:     key.Secret(),
+		OTPAuthURI: key.URL(),
+		QRCode:     "data:image/png;base64," + base64.StdEncoding.EncodeToString(qr.Bytes()),
+		ExpiresIn:  int64(totpSetupTTL.Seconds()),
+	}, nil
+}
+
+// EnableTwoFactor confirms enrollment with a code from the authenticator app
+// and returns the recovery codes. They are shown only this once.
+func (s *Service) EnableTwoFactor(ctx context.Context, userID, code string) (*RecoveryCodesResponse, error) {
+	user, err := s.repo.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TwoFactorEnabled {
+		return nil, appErrors.ErrTwoFactorAlreadyEnabled
+	}
+
+	secret, err := database.Get(ctx, totpSetupPrefix+userID)
+	if err != nil {
+		return nil, appErrors.ErrTwoFactorSetupExpired
+	}
+	if !s.checkTOTP(ctx, userID, secret, code) {
+		return nil, appErrors.ErrInvalidTwoFactorCode
+	}
+
+	encrypted, err := encryptTOTPSecret(s.config.TOTPEncryptionKey, secret)
+	if err != nil {
+		return nil, err
+	}
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.EnableTwoFactor(user.ID, encrypted, hashes); err != nil {
+		return nil, fmt.Errorf("failed to enable two-factor authentication: %w", err)
+	}
+	database.Delete(ctx, totpSetupPrefix+userID)
+
+	s.audit(ctx, userEvent(audit.ActionTwoFactorEnabled, userID, userID))
+	return &RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// DisableTwoFactor turns 2FA off. It needs the password and a current TOTP or
+// recovery code.
+func (s *Service) DisableTwoFactor(ctx context.Context, userID string, req *DisableTwoFactorRequest) error {
+	user, err := s.repo.GetUserByID(userID)
+	if err != nil {
+		return err
+	}
+	if !user.TwoFactorEnabled {
+		return appErrors.ErrTwoFactorNotEnabled
+	}
+	if !utils.CheckPassword(req.Password, user.PasswordHash) {
+		return appErrors.ErrCurrentPasswordIncorrect
+	}
+
+	ok, err := s.verifySecondFactor(ctx, user, req.Code)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return appErrors.ErrInvalidTwoFactorCode
+	}
+
+	if err := s.repo.DisableTwoFactor(user.ID); err != nil {
+		return err
+	}
+
+	s.audit(ctx, userEvent(audit.ActionTwoFactorDisabled, userID, userID))
+	return nil
+}
+
+// RegenerateRecoveryCodes replaces all recovery codes after checking a TOTP code
+func (s *Service) RegenerateRecoveryCodes(ctx context.Context, userID, code string) (*RecoveryCodesResponse, error) {
+	user, err := s.repo.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.TwoFactorEnabled {
+		return nil, appErrors.ErrTwoFactorNotEnabled
+	}
+
+	secret, err := decryptTOTPSecret(s.config.TOTPEncryptionKey, user.TOTPSecret)
+	if err != nil {
+		return nil, err
+	}
+	if !s.checkTOTP(ctx, userID, secret, code) {
+		return nil, appErrors.ErrInvalidTwoFactorCode
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.ReplaceRecoveryCodes(user.ID, hashes); err != nil {
+		return nil, fmt.Errorf("failed to store recovery codes: %w", err)
+	}
+
+	s.audit(ctx, userEvent(audit.ActionRecoveryCodesRegenerated, userID, userID))
+	return &RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// startTwoFactorChallenge holds a password-verified login until the second
+// factor is checked by VerifyTwoFactorLogin
+func (s *Service) startTwoFactorChallenge(ctx context.Context, user *models.User, meta SessionMeta) (*TwoFactorChallengeResponse, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return nil, fmt.Errorf("failed to generate challenge token: %w", err)
+	}
+	token := hex.EncodeToString(b)
+
+	data, err := json.Marshal(twoFactorChallenge{UserID: user.ID.String(), Meta: meta})
+	if err != nil {
+		return nil, err
+	}
+	if err := database.Set(ctx, twoFactorChallengePrefix+token, string(data), TwoFactorChallengeTTL); err != nil {
+		return nil, fmt.Errorf("failed to store login challenge: %w", err)
+	}
+
+	return &TwoFactorChallengeResponse{
+		TwoFactorRequired: true,
+		ChallengeToken:    token,
+		ExpiresIn:         int64(TwoFactorChallengeTTL.Seconds()),
+	}, nil
+}
+
+// VerifyTwoFactorLogin completes a two-step login with a TOTP or recovery code
+func (s *Service) VerifyTwoFactorLogin(ctx context.Context, req *TwoFactorLoginRequest) (*AuthResponse, error) {
+	key := twoFactorChallengePrefix + req.ChallengeToken
+	data, err := database.Get(ctx, key)
+	if err != nil {
+		return nil, appErrors.ErrInvalidToken
+	}
+	var challenge twoFactorChallenge
+	if err := json.Unmarshal([]byte(data), &challenge); err != nil {
+		return nil, appErrors.ErrInvalidToken
+	}
+
+	account := accountSubject(challenge.UserID)
+	if err := checkLoginThrottle(ctx, account); err != nil {
+		return nil, err
+	}
+
+	// Too many wrong codes burn the challenge; the user has to log in again
+	attemptsKey := twoFactorAttemptsPrefix + req.ChallengeToken
+	attempts, err := database.Increment(ctx, attemptsKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check attempts: %w", err)
+	}
+	if attempts == 1 {
+		database.RedisClient.Expire(ctx, attemptsKey, TwoFactorChallengeTTL)
+	}
+	if attempts > maxTwoFactorAttempts {
+		database.Delete(ctx, key)
+		return nil, appErrors.ErrTooManyAttempts
+	}
+
+	user, err := s.repo.GetUserByID(challenge.UserID)
+	if err != nil || !user.IsActive {
+		return nil, appErrors.ErrInvalidToken
+	}
+
+	ok, err := s.verifySecondFactor(ctx, user, req.Code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		if recordLoginFailure(ctx, account) {
+			go s.notifyAccountLocked(user, challenge.Meta.IPAddress)
+		}
+
+		event := userEvent(audit.ActionLoginFailed, "", challenge.UserID)
+		event.Metadata = audit.Metadata{"reason": "invalid_2fa_code"}
+		s.audit(ctx, event)
+		return nil, appErrors.ErrInvalidTwoFactorCode
+	}
+
+	// The challenge is single use
+	if err := database.RedisClient.GetDel(ctx, key).Err(); err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, appErrors.ErrInvalidToken
+		}
+		return nil, fmt.Errorf("failed to consume login challenge: %w", err)
+	}
+	database.Delete(ctx, attemptsKey)
+	if err := clearLoginFailures(ctx, account); err != nil {
+		log.Printf("Failed to clear login failures for %s: %v", user.ID, err)
+	}
+
+	return s.completeLogin(ctx, user, challenge.Meta)
+}
+
+// verifySecondFactor checks a TOTP code, or else a recovery code (which is used up)
+func (s *Service) verifySecondFactor(ctx context.Context, user *models.User, code string) (bool, error) {
+	code = strings.TrimSpace(code)
+	if len(code) == 6 && strings.Trim(code, "0123456789") == "" {
+		secret, err := decryptTOTPSecret(s.config.TOTPEncryptionKey, user.TOTPSecret)
+		if err != nil {
+			return false, err
+		}
+		return s.checkTOTP(ctx, user.ID.String(), secret, code), nil
+	}
+
+	ok, err := s.repo.UseRecoveryCode(user.ID, hashRecoveryCode(code))
+	if err != nil {
+		return false, fmt.Errorf("failed to check recovery code: %w", err)
+	}
+	if ok {
+		log.Printf("User %s logged in with a recovery code", user.ID)
+	}
+	return ok, nil
+}
+
+// checkTOTP validates a code for the current time step, allowing one step of
+// clock drift either way. Each code is accepted only once.
+func (s *Service) checkTOTP(ctx context.Context, userID, secret, code string) bool {
+	now := time.Now()
+	for _, skew := range []int{0, -1, 1} {
+		t := now.Add(time.Duration(skew) * totpPeriod)
+		expected, err := totp.GenerateCodeCustom(secret, t, totp.ValidateOpts{
+			Period:    uint(totpPeriod.Seconds()),
+			Digits:    otp.DigitsSix,
+			Algorithm: otp.AlgorithmSHA1,
+		})
+		if err != nil {
+			return false
+		}
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) != 1 {
+			continue
+		}
+
+		step := t.Unix() / int64(totpPeriod.Seconds())
+		fresh, err := database.RedisClient.SetNX(ctx, fmt.Sprintf("%s%s:%d", totpUsedPrefix, userID, step), "1", 3*totpPeriod).Result()
+		if err != nil {
+			log.Printf("Failed to record TOTP use: %v", err)
+			return false
+		}
+		return fresh
+	}
+	return false
+}
+
+// generateRecoveryCodes returns new recovery codes ("xxxxx-xxxxx") and their hashes
+func generateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	max := big.NewInt(int64(len(recoveryCodeAlphabet)))
+
+	for i := range codes {
+		var b strings.Builder
+		for j := 0; j < 10; j++ {
+			if j == 5 {
+				b.WriteByte('-')
+			}
+			n, err := rand.Int(rand.Reader, max)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to generate recovery code: %w", err)
+			}
+			b.WriteByte(recoveryCodeAlphabet[n.Int64()])
+		}
+		codes[i] = b.String()
+		hashes[i] = hashRecoveryCode(codes[i])
+	}
+	return codes, hashes, nil
+}
+
+// hashRecoveryCode hashes a recovery code, ignoring case, spaces and dashes
+func hashRecoveryCode(code string) string {
+	normalized := strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+		return r
+	}, strings.ToLower(code))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+// encryptTOTPSecret encrypts a TOTP secret with AES-256-GCM
+func encryptTOTPSecret(key, secret string) (string, error) {
+	gcm, err := totpCipher(key)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	sealed := gcm.Seal(nonce, nonce, []byte(secret), nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// decryptTOTPSecret decrypts a secret stored by encryptTOTPSecret
+func decryptTOTPSecret(key string, encrypted *string) (string, error) {
+	if encrypted == nil {
+		return "", fmt.Errorf("no TOTP secret stored")
+	}
+	gcm, err := totpCipher(key)
+	if err != nil {
+		return "", err
+	}
+	sealed, err := base64.StdEncoding.DecodeString(*encrypted)
+	if err != nil || len(sealed) < gcm.NonceSize() {
+		return "", fmt.Errorf("malformed TOTP secret")
+	}
+	secret, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt TOTP secret: %w", err)
+	}
+	return string(secret), nil
+}
+
+func totpCipher(key string) (cipher.AEAD, error) {
+	sum := sha256.Sum256([]byte(key))
+	block, err := aes.NewCipher(sum[:])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
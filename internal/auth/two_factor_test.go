@@ -0,0 +1,54 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTOTPSecretEncryption(t *testing.T) {
+	encrypted, err := encryptTOTPSecret("key-1", "JBSWY3DPEHPK3PXP")
+	if err != nil {
+		t.Fatalf("encryptTOTPSecret() error = %v", err)
+	}
+	if strings.Contains(encrypted, "JBSWY3DPEHPK3PXP") {
+		t.Fatal("encrypted secret contains the plaintext")
+	}
+
+	secret, err := decryptTOTPSecret("key-1", &encrypted)
+	if err != nil || secret != "JBSWY3DPEHPK3PXP" {
+		t.Errorf("decryptTOTPSecret() = %q, %v; want the original secret", secret, err)
+	}
+	if _, err := decryptTOTPSecret("key-2", &encrypted); err == nil {
+		t.Error("decryptTOTPSecret() with the wrong key succeeded")
+	}
+	if _, err := decryptTOTPSecret("key-1", nil); err == nil {
+		t.Error("decryptTOTPSecret() without a secret succeeded")
+	}
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		t.Fatalf("generateRecoveryCodes() error = %v", err)
+	}
+	if len(codes) != recoveryCodeCount || len(hashes) != recoveryCodeCount {
+		t.Fatalf("got %d codes and %d hashes, want %d", len(codes), len(hashes), recoveryCodeCount)
+	}
+
+	seen := make(map[string]bool)
+	for i, code := range codes {
+		if len(code) != 11 || code[5] != '-' {
+			t.Errorf("code %q is not formatted as xxxxx-xxxxx", code)
+		}
+		if seen[hashes[i]] {
+			t.Errorf("duplicate code %q", code)
+		}
+		seen[hashes[i]] = true
+
+		// Users may type codes without the dash, with spaces or in upper case
+		typed := strings.ToUpper(strings.Replace(code, "-", " ", 1))
+		if hashRecoveryCode(typed) != hashes[i] {
+			t.Errorf("hashRecoveryCode(%q) doesn't match the hash of %q", typed, code)
+		}
+	}
+}
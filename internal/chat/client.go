@@ -71,9 +71,10 @@ func (c *Client) ReadPump() {
 			continue
 		}
 
-		// Set sender and timestamp
+		// Set sender, timestamp and originating connection
 		wsMsg.SenderID = c.UserID
 		wsMsg.Timestamp = time.Now()
+		wsMsg.origin = c
 
 		// Handle different message types
 		switch wsMsg.Type {
@@ -124,8 +125,8 @@ func (c *Client) ReadPump() {
 				wsMsg.Content = c.UserID.String() // Include who saw the messages
 				c.hub.broadcast <- &wsMsg
 			}
-			// Send unread update to this user
-			c.sendUnreadUpdate()
+			// Send unread update to all of this user's devices
+			c.hub.sendUnreadUpdate(c.UserID)
 
 		case "unread:get":
 			// Client requests total unread count
@@ -0,0 +1,246 @@
+package chat
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// How long a node is considered alive without refreshing its heartbeat
+	nodeHeartbeatTTL = 30 * time.Second
+
+	// How often a node refreshes its heartbeat
+	nodeHeartbeatPeriod = 10 * time.Second
+
+	// Presence keys cleared per round trip when a node starts
+	presenceScanBatch = 500
+)
+
+// clusterEnvelope wraps a WebSocket message for delivery on another node
+type clusterEnvelope struct {
+	FromNode string     `json:"from_node"`
+	UserID   uuid.UUID  `json:"user_id"`
+	Message  *WSMessage `json:"message"`
+}
+
+// cluster shares presence and forwards messages between API nodes via Redis.
+//
+// Presence is stored per user as a hash of node ID -> open connection count
+// (chat:presence:<user_id>). Each node keeps a heartbeat key alive
+// (chat:node:<node_id>) so entries left behind by a crashed node are ignored
+// and cleaned up lazily. A node restarting with the same ID clears its old
+// entries before its heartbeat makes them look live again. Messages for a user
+// connected elsewhere are published on the owning node's channel
+// (chat:node:<node_id>:deliver).
+type cluster struct {
+	rdb    *redis.Client
+	nodeID string
+}
+
+func presenceKey(userID uuid.UUID) string {
+	return fmt.Sprintf("chat:presence:%s", userID)
+}
+
+func nodeKey(nodeID string) string {
+	return fmt.Sprintf("chat:node:%s", nodeID)
+}
+
+func nodeChannel(nodeID string) string {
+	return fmt.Sprintf("chat:node:%s:deliver", nodeID)
+}
+
+// EnableCluster turns on cross-node delivery and shared presence using Redis.
+// Must be called before Run.
+func (h *Hub) EnableCluster(rdb *redis.Client, nodeID string) {
+	if nodeID == "" {
+		nodeID = uuid.New().String()
+	}
+	h.cluster = &cluster{rdb: rdb, nodeID: nodeID}
+}
+
+// NodeID returns this node's cluster identifier (empty when clustering is disabled)
+func (h *Hub) NodeID() string {
+	if h.cluster == nil {
+		return ""
+	}
+	return h.cluster.nodeID
+}
+
+// runCluster keeps the node heartbeat alive and delivers messages forwarded by other nodes
+func (h *Hub) runCluster() {
+	ctx := context.Background()
+	c := h.cluster
+
+	c.clearPresence(ctx)
+	if err := c.rdb.Set(ctx, nodeKey(c.nodeID), time.Now().Unix(), nodeHeartbeatTTL).Err(); err != nil {
+		log.Printf("Cluster: failed to set heartbeat: %v", err)
+	}
+	go func() {
+		ticker := time.NewTicker(nodeHeartbeatPeriod)
+		defer ticker.Stop()
+		for range ticker.C {
+			if err := c.rdb.Set(ctx, nodeKey(c.nodeID), time.Now().Unix(), nodeHeartbeatTTL).Err(); err != nil {
+				log.Printf("Cluster: failed to refresh heartbeat: %v", err)
+			}
+		}
+	}()
+
+	pubsub := c.rdb.Subscribe(ctx, nodeChannel(c.nodeID))
+	log.Printf("Cluster: node %s listening for forwarded messages", c.nodeID)
+
+	go func() {
+		defer pubsub.Close()
+		for redisMsg := range pubsub.Channel() {
+			var env clusterEnvelope
+			if err := json.Unmarshal([]byte(redisMsg.Payload), &env); err != nil {
+				log.Printf("Cluster: invalid envelope: %v", err)
+				continue
+			}
+			if env.Message == nil {
+				continue
+			}
+			h.sendToLocal(env.UserID, env.Message, nil)
+		}
+	}()
+}
+
+// clearPresence removes this node's entries from every user's presence. They
+// are left over from a previous run with the same node ID that died without
+// unregistering its connections.
+func (c *cluster) clearPresence(ctx context.Context) {
+	iter := c.rdb.Scan(ctx, 0, "chat:presence:*", presenceScanBatch).Iterator()
+
+	pipe := c.rdb.Pipeline()
+	queued := 0
+	flush := func() {
+		if queued == 0 {
+			return
+		}
+		if _, err := pipe.Exec(ctx); err != nil {
+			log.Printf("Cluster: failed to clear stale presence: %v", err)
+		}
+		queued = 0
+	}
+	for iter.Next(ctx) {
+		pipe.HDel(ctx, iter.Val(), c.nodeID)
+		if queued++; queued == presenceScanBatch {
+			flush()
+		}
+	}
+	flush()
+	if err := iter.Err(); err != nil {
+		log.Printf("Cluster: failed to scan presence: %v", err)
+	}
+}
+
+// addPresence records a new connection for a user on this node
+func (c *cluster) addPresence(userID uuid.UUID) {
+	ctx := context.Background()
+	if err := c.rdb.HIncrBy(ctx, presenceKey(userID), c.nodeID, 1).Err(); err != nil {
+		log.Printf("Cluster: failed to add presence for %s: %v", userID, err)
+	}
+}
+
+// removePresence drops a connection for a user on this node
+func (c *cluster) removePresence(userID uuid.UUID) {
+	ctx := context.Background()
+	left, err := c.rdb.HIncrBy(ctx, presenceKey(userID), c.nodeID, -1).Result()
+	if err != nil {
+		log.Printf("Cluster: failed to remove presence for %s: %v", userID, err)
+		return
+	}
+	if left <= 0 {
+		c.rdb.HDel(ctx, presenceKey(userID), c.nodeID)
+	}
+}
+
+// remoteNodes returns the live nodes (other than this one) holding a connection
+// for the user. It runs on the hub loop, so it takes two round trips however
+// many nodes the user is on.
+func (c *cluster) remoteNodes(userID uuid.UUID) []string {
+	ctx := context.Background()
+	entries, err := c.rdb.HGetAll(ctx, presenceKey(userID)).Result()
+	if err != nil {
+		log.Printf("Cluster: failed to read presence for %s: %v", userID, err)
+		return nil
+	}
+
+	var candidates []string
+	for nodeID, count := range entries {
+		if nodeID == c.nodeID || count == "" || count == "0" {
+			continue
+		}
+		candidates = append(candidates, nodeID)
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	// Check every node's heartbeat at once
+	pipe := c.rdb.Pipeline()
+	alive := make([]*redis.IntCmd, len(candidates))
+	for i, nodeID := range candidates {
+		alive[i] = pipe.Exists(ctx, nodeKey(nodeID))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		log.Printf("Cluster: failed to check nodes for %s: %v", userID, err)
+		return nil
+	}
+
+	var nodes, dead []string
+	for i, nodeID := range candidates {
+		if alive[i].Val() == 0 {
+			dead = append(dead, nodeID)
+			continue
+		}
+		nodes = append(nodes, nodeID)
+	}
+	if len(dead) > 0 {
+		// Nodes died without cleaning up, drop their stale entries
+		c.rdb.HDel(ctx, presenceKey(userID), dead...)
+	}
+	return nodes
+}
+
+// forward publishes a message to every other node holding a connection for the user.
+// Returns true if at least one remote node was targeted.
+func (c *cluster) forward(userID uuid.UUID, msg *WSMessage) bool {
+	nodes := c.remoteNodes(userID)
+	if len(nodes) == 0 {
+		return false
+	}
+
+	payload, err := json.Marshal(clusterEnvelope{
+		FromNode: c.nodeID,
+		UserID:   userID,
+		Message:  msg,
+	})
+	if err != nil {
+		log.Printf("Cluster: failed to marshal envelope: %v", err)
+		return false
+	}
+
+	ctx := context.Background()
+	pipe := c.rdb.Pipeline()
+	published := make([]*redis.IntCmd, len(nodes))
+	for i, nodeID := range nodes {
+		published[i] = pipe.Publish(ctx, nodeChannel(nodeID), payload)
+	}
+	pipe.Exec(ctx)
+
+	sent := false
+	for i, nodeID := range nodes {
+		if err := published[i].Err(); err != nil {
+			log.Printf("Cluster: failed to publish to node %s: %v", nodeID, err)
+			continue
+		}
+		sent = true
+	}
+	return sent
+}
@@ -62,6 +62,7 @@ type MessageResponse struct {
 	Content        string    `json:"content"`
 	MessageType    string    `json:"message_type"`
 	MediaURL       string    `json:"media_url,omitempty"`
+	Metadata       Metadata  `json:"metadata,omitempty"`
 	IsRead         bool      `json:"is_read"`
 	Status         string    `json:"status"`
 	DeliveredAt    string    `json:"delivered_at,omitempty"`
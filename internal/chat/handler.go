@@ -263,7 +263,7 @@ func (h *Handler) RegisterDevice(c *gin.Context) {
 		req.DeviceType = "android"
 	}
 
-	if err := h.service.RegisterDevice(userID, req.FCMToken, req.DeviceType); err != nil {
+	if err := h.service.RegisterDevice(c.Request.Context(), userID, req.FCMToken, req.DeviceType); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to register device"})
 		return
 	}
@@ -292,7 +292,7 @@ func (h *Handler) UnregisterDevice(c *gin.Context) {
 		return
 	}
 
-	if err := h.service.UnregisterDevice(userID, req.FCMToken); err != nil {
+	if err := h.service.UnregisterDevice(c.Request.Context(), userID, req.FCMToken); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unregister device"})
 		return
 	}
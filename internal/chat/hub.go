@@ -11,8 +11,8 @@ import (
 
 // Hub maintains the set of active clients and broadcasts messages
 type Hub struct {
-	// Registered clients by user ID
-	clients map[uuid.UUID]*Client
+	// Registered connections grouped by user ID (a user may be connected from several devices)
+	clients map[uuid.UUID]map[*Client]bool
 
 	// Register requests from clients
 	register chan *Client
@@ -28,12 +28,15 @@ type Hub struct {
 
 	// Chat service for persistence and notifications
 	service *Service
+
+	// Cross-node delivery and shared presence (nil when running a single node)
+	cluster *cluster
 }
 
 // NewHub creates a new Hub instance
 func NewHub(service *Service) *Hub {
 	return &Hub{
-		clients:    make(map[uuid.UUID]*Client),
+		clients:    make(map[uuid.UUID]map[*Client]bool),
 		register:   make(chan *Client),
 		unregister: make(chan *Client),
 		broadcast:  make(chan *WSMessage),
@@ -43,22 +46,47 @@ func NewHub(service *Service) *Hub {
 
 // Run starts the hub's main loop
 func (h *Hub) Run() {
+	if h.cluster != nil {
+		h.runCluster()
+	}
+
 	for {
 		select {
 		case client := <-h.register:
 			h.mu.Lock()
-			h.clients[client.UserID] = client
+			conns, ok := h.clients[client.UserID]
+			if !ok {
+				conns = make(map[*Client]bool)
+				h.clients[client.UserID] = conns
+			}
+			conns[client] = true
+			count := len(conns)
 			h.mu.Unlock()
-			log.Printf("Client registered: %s", client.UserID)
+			if h.cluster != nil {
+				h.cluster.addPresence(client.UserID)
+			}
+			log.Printf("Client registered: %s (%d connections)", client.UserID, count)
 
 		case client := <-h.unregister:
 			h.mu.Lock()
-			if _, ok := h.clients[client.UserID]; ok {
-				delete(h.clients, client.UserID)
-				close(client.send)
+			count := 0
+			removed := false
+			if conns, ok := h.clients[client.UserID]; ok {
+				if _, ok := conns[client]; ok {
+					delete(conns, client)
+					close(client.send)
+					removed = true
+				}
+				count = len(conns)
+				if count == 0 {
+					delete(h.clients, client.UserID)
+				}
 			}
 			h.mu.Unlock()
-			log.Printf("Client unregistered: %s", client.UserID)
+			if removed && h.cluster != nil {
+				h.cluster.removePresence(client.UserID)
+			}
+			log.Printf("Client unregistered: %s (%d connections left)", client.UserID, count)
 
 		case message := <-h.broadcast:
 			h.handleBroadcast(message)
@@ -78,49 +106,70 @@ func (h *Hub) handleBroadcast(msg *WSMessage) {
 	// Track who received the message in real-time
 	var offlineUsers []uuid.UUID
 
-	h.mu.RLock()
 	for _, userID := range participants {
 		if userID == msg.SenderID {
-			continue // Don't send to sender
+			continue // Sender's own devices are handled below
 		}
 
-		if client, ok := h.clients[userID]; ok {
-			select {
-			case client.send <- msg:
-				// Message sent successfully
-				// For new messages, also send unread count update and conversation update
-				if msg.Type == "message" {
-					go h.sendUnreadUpdateToClient(client, userID)
-					go h.sendConversationUpdate(client, msg)
-				}
-			default:
-				// Client buffer full, consider them offline
-				offlineUsers = append(offlineUsers, userID)
+		if h.sendToUser(userID, msg, nil) {
+			// For new messages, also send unread count update and conversation update
+			if msg.Type == "message" {
+				go h.sendUnreadUpdate(userID)
+				go h.sendConversationUpdate(userID, msg)
 			}
 		} else {
-			// User not connected
+			// No connection accepted the message (not connected or every buffer full)
 			offlineUsers = append(offlineUsers, userID)
 		}
 	}
-	h.mu.RUnlock()
 
 	// Send push notifications to offline users
 	if len(offlineUsers) > 0 {
 		go h.service.SendPushNotifications(offlineUsers, msg)
 	}
 
-	// Also notify sender about conversation update (for chat list refresh)
 	if msg.Type == "message" {
-		h.mu.RLock()
-		if senderClient, ok := h.clients[msg.SenderID]; ok {
-			go h.sendConversationUpdate(senderClient, msg)
+		// Mirror the message to the sender's other devices so every session stays in sync
+		h.sendToUser(msg.SenderID, msg, msg.origin)
+
+		// Also notify sender about conversation update (for chat list refresh)
+		go h.sendConversationUpdate(msg.SenderID, msg)
+	}
+}
+
+// sendToUser delivers a message to every connection of a user across the cluster,
+// skipping the optional origin connection. Returns true if at least one
+// connection accepted it locally or the message was forwarded to another node.
+func (h *Hub) sendToUser(userID uuid.UUID, msg *WSMessage, skip *Client) bool {
+	delivered := h.sendToLocal(userID, msg, skip)
+	if h.cluster != nil && h.cluster.forward(userID, msg) {
+		delivered = true
+	}
+	return delivered
+}
+
+// sendToLocal delivers a message to the user's connections held by this node
+func (h *Hub) sendToLocal(userID uuid.UUID, msg *WSMessage, skip *Client) bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	delivered := false
+	for client := range h.clients[userID] {
+		if client == skip {
+			continue
+		}
+		select {
+		case client.send <- msg:
+			delivered = true
+		default:
+			// Buffer full on this connection, skip it
 		}
-		h.mu.RUnlock()
 	}
+	return delivered
 }
 
-// sendConversationUpdate notifies client that a conversation was updated
-func (h *Hub) sendConversationUpdate(client *Client, msg *WSMessage) {
+// sendConversationUpdate notifies a user's connections that a conversation was updated
+func (h *Hub) sendConversationUpdate(userID uuid.UUID, msg *WSMessage) {
 	updateMsg := &WSMessage{
 		Type:           "conversation:updated",
 		ConversationID: msg.ConversationID,
@@ -129,16 +178,11 @@ func (h *Hub) sendConversationUpdate(client *Client, msg *WSMessage) {
 		MessageType:    msg.MessageType,
 		Timestamp:      msg.Timestamp,
 	}
-	select {
-	case client.send <- updateMsg:
-		// Sent successfully
-	default:
-		// Buffer full, skip
-	}
+	h.sendToUser(userID, updateMsg, nil)
 }
 
-// sendUnreadUpdateToClient sends total unread count to a specific client
-func (h *Hub) sendUnreadUpdateToClient(client *Client, userID uuid.UUID) {
+// sendUnreadUpdate sends the total unread count to all of a user's connections
+func (h *Hub) sendUnreadUpdate(userID uuid.UUID) {
 	total, err := h.service.GetTotalUnreadCount(userID)
 	if err != nil {
 		log.Printf("Failed to get total unread count: %v", err)
@@ -149,76 +193,65 @@ func (h *Hub) sendUnreadUpdateToClient(client *Client, userID uuid.UUID) {
 		Content:   fmt.Sprintf("%d", total),
 		Timestamp: time.Now(),
 	}
-	select {
-	case client.send <- unreadMsg:
-		// Sent successfully
-	default:
-		// Buffer full, skip
-	}
+	h.sendToUser(userID, unreadMsg, nil)
 }
 
 // SendUnreadUpdate sends unread update to a specific user if they're online
 func (h *Hub) SendUnreadUpdate(userID uuid.UUID) {
-	h.mu.RLock()
-	client, ok := h.clients[userID]
-	h.mu.RUnlock()
-
-	if ok {
-		go h.sendUnreadUpdateToClient(client, userID)
+	if h.IsUserOnline(userID) {
+		go h.sendUnreadUpdate(userID)
 	}
 }
 
-// IsUserOnline checks if a user is currently connected
+// IsUserOnline checks if a user has at least one active connection on any node
 func (h *Hub) IsUserOnline(userID uuid.UUID) bool {
 	h.mu.RLock()
-	defer h.mu.RUnlock()
-	_, ok := h.clients[userID]
-	return ok
+	local := len(h.clients[userID]) > 0
+	h.mu.RUnlock()
+	if local {
+		return true
+	}
+	return h.cluster != nil && len(h.cluster.remoteNodes(userID)) > 0
 }
 
-// GetOnlineCount returns the number of connected clients
+// GetOnlineCount returns the number of distinct users connected to this node
 func (h *Hub) GetOnlineCount() int {
 	h.mu.RLock()
 	defer h.mu.RUnlock()
 	return len(h.clients)
 }
 
-// SendNotification sends a notification to a specific user via WebSocket
-// Returns true if the user was online and notification was sent, false otherwise
-func (h *Hub) SendNotification(userID uuid.UUID, notificationData map[string]interface{}) bool {
+// GetConnectionCount returns the total number of open connections on this node
+func (h *Hub) GetConnectionCount() int {
 	h.mu.RLock()
-	client, ok := h.clients[userID]
-	h.mu.RUnlock()
-
-	if !ok {
-		return false
+	defer h.mu.RUnlock()
+	total := 0
+	for _, conns := range h.clients {
+		total += len(conns)
 	}
+	return total
+}
+
+// GetUserConnectionCount returns the number of open connections for a user on this node
+func (h *Hub) GetUserConnectionCount(userID uuid.UUID) int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.clients[userID])
+}
 
+// SendNotification sends a notification to all of a user's connections via WebSocket
+// Returns true if at least one connection received it, false otherwise
+func (h *Hub) SendNotification(userID uuid.UUID, notificationData map[string]interface{}) bool {
 	msg := &WSMessage{
 		Type:      "notification",
 		Data:      notificationData,
 		Timestamp: time.Now(),
 	}
-
-	select {
-	case client.send <- msg:
-		return true
-	default:
-		// Buffer full, couldn't send
-		return false
-	}
+	return h.sendToUser(userID, msg, nil)
 }
 
-// SendNotificationCount sends the unread notification count to a specific user
+// SendNotificationCount sends the unread notification count to all of a user's connections
 func (h *Hub) SendNotificationCount(userID uuid.UUID, count int64) bool {
-	h.mu.RLock()
-	client, ok := h.clients[userID]
-	h.mu.RUnlock()
-
-	if !ok {
-		return false
-	}
-
 	msg := &WSMessage{
 		Type: "notification:unread",
 		Data: map[string]interface{}{
@@ -226,11 +259,44 @@ func (h *Hub) SendNotificationCount(userID uuid.UUID, count int64) bool {
 		},
 		Timestamp: time.Now(),
 	}
+	return h.sendToUser(userID, msg, nil)
+}
 
-	select {
-	case client.send <- msg:
-		return true
-	default:
-		return false
+// CarMessage is a message the API posts into the conversation between a
+// listing's seller and a buyer, such as an offer update
+type CarMessage struct {
+	CarID       uuid.UUID
+	CarTitle    string
+	SellerID    uuid.UUID
+	BuyerID     uuid.UUID
+	SenderID    uuid.UUID // User whose action the message reports
+	MessageType string    // MessageTypeOffer or MessageTypeSystem
+	Content     string    // Readable summary for clients that do not render the type
+	Data        Metadata
+}
+
+// PostCarMessage saves a message into the seller and buyer's conversation
+// about a listing, starting the conversation if needed, and delivers it like a
+// chat message. Returns the conversation ID.
+func (h *Hub) PostCarMessage(msg CarMessage) (uuid.UUID, error) {
+	conv, err := h.service.StartConversation([]uuid.UUID{msg.SellerID, msg.BuyerID}, &msg.CarID, msg.CarTitle, nil)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to start conversation: %w", err)
 	}
+
+	wsMsg := &WSMessage{
+		Type:           "message",
+		ConversationID: conv.ID,
+		SenderID:       msg.SenderID,
+		Content:        msg.Content,
+		MessageType:    msg.MessageType,
+		Data:           msg.Data,
+		Timestamp:      time.Now(),
+	}
+	if err := h.service.saveMessage(wsMsg, msg.Data); err != nil {
+		return uuid.Nil, err
+	}
+
+	h.broadcast <- wsMsg
+	return conv.ID, nil
 }
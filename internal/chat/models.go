@@ -6,6 +6,15 @@ import (
 	"github.com/google/uuid"
 )
 
+// Message types. Offer and system messages are posted by the API, never by clients.
+const (
+	MessageTypeText   = "text"
+	MessageTypeImage  = "image"
+	MessageTypeFile   = "file"
+	MessageTypeOffer  = "offer"
+	MessageTypeSystem = "system"
+)
+
 // Conversation represents a chat room between users about a specific car
 type Conversation struct {
 	ID uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
@@ -35,6 +44,7 @@ type ConversationParticipant struct {
 	LastReadMessageID *uuid.UUID `json:"last_read_message_id" gorm:"type:uuid"`
 	UnreadCount       int        `json:"unread_count" gorm:"default:0"`
 	JoinedAt          time.Time  `json:"joined_at"`
+	DigestEmailedAt   *time.Time `json:"-"` // Last unread messages email for this conversation
 }
 
 // Message represents a single chat message
@@ -43,8 +53,9 @@ type Message struct {
 	ConversationID uuid.UUID  `json:"conversation_id" gorm:"type:uuid;index"`
 	SenderID       uuid.UUID  `json:"sender_id" gorm:"type:uuid;index"`
 	Content        string     `json:"content"`
-	MessageType    string     `json:"message_type" gorm:"default:text"` // text, image, file
+	MessageType    string     `json:"message_type" gorm:"default:text"` // text, image, file, offer, system
 	MediaURL       *string    `json:"media_url,omitempty"`
+	Metadata       Metadata   `json:"metadata,omitempty" gorm:"type:jsonb;default:'{}'"` // Structured data of offer and system messages
 	IsRead         bool       `json:"is_read" gorm:"default:false"`
 	Status         string     `json:"status" gorm:"default:sent"` // sent, delivered, seen
 	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
@@ -72,6 +83,9 @@ type WSMessage struct {
 	MediaURL       string                 `json:"media_url,omitempty"`
 	Data           map[string]interface{} `json:"data,omitempty"` // Flexible data for notifications and other events
 	Timestamp      time.Time              `json:"timestamp"`
+
+	// origin is the connection the message arrived on (not serialized)
+	origin *Client
 }
 
 // Table name overrides for GORM
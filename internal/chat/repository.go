@@ -336,3 +336,32 @@ func (r *Repository) GetConversationParticipantIDs(conversationID uuid.UUID) ([]
 		Pluck("user_id", &userIDs).Error
 	return userIDs, err
 }
+
+// --- Email Digest Operations ---
+
+// UnreadDigestRow is a participant's unread conversation claimed for an email digest
+type UnreadDigestRow struct {
+	UserID         uuid.UUID
+	ConversationID uuid.UUID
+	UnreadCount    int
+	CarTitle       string
+}
+
+// ClaimUnreadDigests marks and returns participants with unread messages in
+// conversations that have been quiet since before `quietSince`, skipping those
+// already emailed about the latest message. Claiming in one UPDATE means each
+// digest is sent once even with several API replicas.
+func (r *Repository) ClaimUnreadDigests(quietSince, now time.Time) ([]UnreadDigestRow, error) {
+	var rows []UnreadDigestRow
+	err := r.db.Raw(`
+		UPDATE conversation_participants cp
+		SET digest_emailed_at = ?
+		FROM conversations c
+		WHERE c.id = cp.conversation_id
+			AND cp.unread_count > 0
+			AND c.last_message_at <= ?
+			AND (cp.digest_emailed_at IS NULL OR cp.digest_emailed_at < c.last_message_at)
+		RETURNING cp.user_id, cp.conversation_id, cp.unread_count, COALESCE(c.car_title, '') AS car_title`,
+		now, quietSince).Scan(&rows).Error
+	return rows, err
+}
@@ -1,16 +1,36 @@
 package chat
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"log"
 	"time"
 
 	"github.com/google/uuid"
+
+	"github.com/yourusername/car-reselling-backend/internal/audit"
+	"github.com/yourusername/car-reselling-backend/internal/email"
+)
+
+const (
+	// emailDigestDelay is how long messages stay unread before they are emailed
+	emailDigestDelay = time.Hour
+
+	// emailDigestInterval is how often the email digest worker runs
+	emailDigestInterval = 15 * time.Minute
 )
 
+// ErrMessageTypeNotAllowed is returned when a client sends a message type only the API may post
+var ErrMessageTypeNotAllowed = errors.New("message type cannot be sent by clients")
+
 // Service handles business logic for chat
 type Service struct {
 	repo         *Repository
 	notification NotificationSender
+	mailer       DigestMailer
+	auditLog     AuditLogger
 }
 
 // NotificationSender interface for sending push notifications
@@ -18,6 +38,16 @@ type NotificationSender interface {
 	SendToUsers(userIDs []uuid.UUID, title, body string, data map[string]string) error
 }
 
+// DigestMailer emails users about unread messages
+type DigestMailer interface {
+	SendChatDigest(ctx context.Context, userID uuid.UUID, conversations []email.ChatDigestItem) error
+}
+
+// AuditLogger records device registrations
+type AuditLogger interface {
+	Record(ctx context.Context, event audit.Event)
+}
+
 // NewService creates a new chat service
 func NewService(repo *Repository, notification NotificationSender) *Service {
 	return &Service{
@@ -104,13 +134,26 @@ func formatTimePtr(t *time.Time) string {
 
 // --- Message Operations ---
 
-// SaveMessage persists a WebSocket message to the database
+// SaveMessage persists a message sent by a client to the database
 func (s *Service) SaveMessage(wsMsg *WSMessage) error {
+	switch wsMsg.MessageType {
+	case "":
+		wsMsg.MessageType = MessageTypeText
+	case MessageTypeText, MessageTypeImage, MessageTypeFile:
+	default:
+		return ErrMessageTypeNotAllowed
+	}
+	return s.saveMessage(wsMsg, nil)
+}
+
+// saveMessage persists a message with optional structured metadata
+func (s *Service) saveMessage(wsMsg *WSMessage, metadata Metadata) error {
 	msg := &Message{
 		ConversationID: wsMsg.ConversationID,
 		SenderID:       wsMsg.SenderID,
 		Content:        wsMsg.Content,
 		MessageType:    wsMsg.MessageType,
+		Metadata:       metadata,
 		CreatedAt:      wsMsg.Timestamp,
 	}
 
@@ -153,6 +196,7 @@ func (s *Service) GetChatHistory(conversationID uuid.UUID, page, pageSize int) (
 			Content:        msg.Content,
 			MessageType:    msg.MessageType,
 			MediaURL:       mediaURL,
+			Metadata:       msg.Metadata,
 			IsRead:         msg.IsRead,
 			Status:         msg.Status,
 			DeliveredAt:    formatTimePtr(msg.DeliveredAt),
@@ -211,19 +255,50 @@ func (s *Service) SendPushNotifications(userIDs []uuid.UUID, msg *WSMessage) {
 
 // --- Device Operations ---
 
+// SetAuditLogger sets the audit log for device registrations
+func (s *Service) SetAuditLogger(l AuditLogger) {
+	s.auditLog = l
+}
+
 // RegisterDevice stores FCM token for a user
-func (s *Service) RegisterDevice(userID uuid.UUID, fcmToken, deviceType string) error {
+func (s *Service) RegisterDevice(ctx context.Context, userID uuid.UUID, fcmToken, deviceType string) error {
 	device := &UserDevice{
 		UserID:     userID,
 		FCMToken:   fcmToken,
 		DeviceType: deviceType,
 	}
-	return s.repo.SaveUserDevice(device)
+	if err := s.repo.SaveUserDevice(device); err != nil {
+		return err
+	}
+
+	s.auditDevice(ctx, audit.ActionDeviceRegistered, userID, fcmToken, audit.Metadata{"device_type": deviceType})
+	return nil
 }
 
 // UnregisterDevice removes FCM token for a user
-func (s *Service) UnregisterDevice(userID uuid.UUID, fcmToken string) error {
-	return s.repo.DeleteUserDevice(userID, fcmToken)
+func (s *Service) UnregisterDevice(ctx context.Context, userID uuid.UUID, fcmToken string) error {
+	if err := s.repo.DeleteUserDevice(userID, fcmToken); err != nil {
+		return err
+	}
+
+	s.auditDevice(ctx, audit.ActionDeviceUnregistered, userID, fcmToken, nil)
+	return nil
+}
+
+// auditDevice records a device change. Devices are identified by a hash of
+// their FCM token; the token itself is a credential and stays out of the log.
+func (s *Service) auditDevice(ctx context.Context, action string, userID uuid.UUID, fcmToken string, metadata audit.Metadata) {
+	if s.auditLog == nil {
+		return
+	}
+	sum := sha256.Sum256([]byte(fcmToken))
+	s.auditLog.Record(ctx, audit.Event{
+		ActorID:    audit.ActorID(userID),
+		Action:     action,
+		TargetType: audit.TargetDevice,
+		TargetID:   hex.EncodeToString(sum[:8]),
+		Metadata:   metadata,
+	})
 }
 
 // --- Message Status Operations ---
@@ -259,3 +334,50 @@ func (s *Service) GetTotalUnreadCount(userID uuid.UUID) (int64, error) {
 func (s *Service) ResetUnreadCount(conversationID, userID uuid.UUID) error {
 	return s.repo.ResetUnreadCount(conversationID, userID)
 }
+
+// --- Email Digests ---
+
+// SetMailer sets the service used for unread message emails
+func (s *Service) SetMailer(m DigestMailer) {
+	s.mailer = m
+}
+
+// RunEmailDigestWorker periodically emails users about messages they haven't read
+func (s *Service) RunEmailDigestWorker() {
+	ticker := time.NewTicker(emailDigestInterval)
+	defer ticker.Stop()
+
+	for {
+		s.SendEmailDigests(context.Background())
+		<-ticker.C
+	}
+}
+
+// SendEmailDigests sends one email per user listing their conversations with
+// messages unread for longer than emailDigestDelay
+func (s *Service) SendEmailDigests(ctx context.Context) {
+	if s.mailer == nil {
+		return
+	}
+
+	now := time.Now()
+	rows, err := s.repo.ClaimUnreadDigests(now.Add(-emailDigestDelay), now)
+	if err != nil {
+		log.Printf("Failed to claim unread message digests: %v", err)
+		return
+	}
+
+	byUser := make(map[uuid.UUID][]email.ChatDigestItem)
+	for _, row := range rows {
+		byUser[row.UserID] = append(byUser[row.UserID], email.ChatDigestItem{
+			CarTitle:    row.CarTitle,
+			UnreadCount: row.UnreadCount,
+		})
+	}
+
+	for userID, conversations := range byUser {
+		if err := s.mailer.SendChatDigest(ctx, userID, conversations); err != nil {
+			log.Printf("Failed to email unread message digest to %s: %v", userID, err)
+		}
+	}
+}
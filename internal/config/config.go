@@ -1,13 +1,35 @@
 package config
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 
 	"github.com/joho/godotenv"
+	"golang.org/x/crypto/hkdf"
 )
 
+// Labels for keys derived from JWT_SECRET, so no two uses share a key
+const (
+	totpKeyLabel       = "car-reselling/totp-secret-encryption"
+	emailTokenKeyLabel = "car-reselling/email-verification-token"
+)
+
+// paymentCurrencies are the currencies PAYMENT_CURRENCY accepts. Amounts are
+// charged, refunded and booked in hundredths, so only currencies with two
+// decimals are listed; JPY (none) or KWD (three) would be charged 100x off.
+var paymentCurrencies = map[string]bool{
+	"USD": true, "EUR": true, "GBP": true, "CAD": true, "AUD": true, "NZD": true,
+	"CHF": true, "SEK": true, "NOK": true, "DKK": true, "PLN": true, "CZK": true,
+	"INR": true, "SGD": true, "HKD": true, "MXN": true, "BRL": true, "ZAR": true,
+	"AED": true,
+}
+
 // Config holds all configuration for the application
 type Config struct {
 	ServerPort       string
@@ -17,21 +39,56 @@ type Config struct {
 	JWTRefreshSecret string
 	Environment      string
 
+	// Two-factor authentication. Encrypts stored TOTP secrets; when unset it is
+	// derived from JWT_SECRET. Never change it, or JWT_SECRET while it is
+	// derived, without re-encrypting the stored secrets: 2FA users get locked out.
+	TOTPEncryptionKey string
+
 	// Twilio (optional)
 	TwilioAccountSID  string
 	TwilioAuthToken   string
 	TwilioPhoneNumber string
 
+	// OTP delivery
+	OTPProvider      string // twilio, webhook or console (default: twilio if configured, else console)
+	OTPWebhookURL    string // Endpoint for the webhook provider
+	OTPWebhookSecret string // Optional HMAC-SHA256 signing secret for the webhook provider
+	OTPDevFile       string // Optional file for the console provider (default: log)
+
+	// Email (SMTP). Without SMTP_HOST, emails are only logged.
+	SMTPHost         string
+	SMTPPort         string
+	SMTPUsername     string
+	SMTPPassword     string
+	EmailFrom        string // e.g. "Car Reselling <no-reply@example.com>"
+	EmailTokenSecret string // Signs email verification links (default: derived from JWT_SECRET)
+	PublicURL        string // Public base URL of this API, used in email links
+
 	// Cloudflare R2 Storage
-	R2AccountID       string
-	R2AccessKeyID     string
-	R2SecretAccessKey string
-	R2BucketName      string
-	R2PublicURL       string // Optional custom domain
+	R2AccountID         string
+	R2AccessKeyID       string
+	R2SecretAccessKey   string
+	R2BucketName        string
+	R2PrivateBucketName string // Bucket without public access, for documents such as dealer verification scans
+	R2PublicURL         string // Optional custom domain
 
 	// Firebase Cloud Messaging
 	FirebaseCredentialsJSON string // JSON string of service account credentials
 	FirebaseCredentialsPath string // Path to service account JSON file
+
+	// Listing boosts
+	BoostDailyPrice float64 // Price of featuring a listing for one day
+	PaymentCurrency string  // ISO 4217 code charged in, e.g. USD; must have two decimals
+
+	// Payments (Stripe or a Stripe-compatible API). Without STRIPE_SECRET_KEY, payments are disabled.
+	StripeSecretKey     string
+	StripeWebhookSecret string // Signing secret of the webhook endpoint
+	StripeAPIURL        string // Base URL of the API, e.g. a local mock server (default: https://api.stripe.com)
+	PaymentSuccessURL   string // Where checkout sends the buyer after paying
+	PaymentCancelURL    string // Where checkout sends the buyer after giving up
+
+	// Chat cluster
+	NodeID string // Identifier of this API replica for cross-node chat delivery (random if empty)
 }
 
 // Load reads configuration from environment variables
@@ -63,20 +120,61 @@ func Load() (*Config, error) {
 		JWTSecret:         getEnv("JWT_SECRET", ""),
 		JWTRefreshSecret:  getEnv("JWT_REFRESH_SECRET", ""),
 		Environment:       getEnv("ENVIRONMENT", "development"),
+		TOTPEncryptionKey: getEnv("TOTP_ENCRYPTION_KEY", ""),
 		TwilioAccountSID:  getEnv("TWILIO_ACCOUNT_SID", ""),
 		TwilioAuthToken:   getEnv("TWILIO_AUTH_TOKEN", ""),
 		TwilioPhoneNumber: getEnv("TWILIO_PHONE_NUMBER", ""),
 
+		// OTP delivery
+		OTPProvider:      getEnv("OTP_PROVIDER", ""),
+		OTPWebhookURL:    getEnv("OTP_WEBHOOK_URL", ""),
+		OTPWebhookSecret: getEnv("OTP_WEBHOOK_SECRET", ""),
+		OTPDevFile:       getEnv("OTP_DEV_FILE", ""),
+
+		// Email
+		SMTPHost:         getEnv("SMTP_HOST", ""),
+		SMTPPort:         getEnv("SMTP_PORT", "587"),
+		SMTPUsername:     getEnv("SMTP_USERNAME", ""),
+		SMTPPassword:     getEnv("SMTP_PASSWORD", ""),
+		EmailFrom:        getEnv("EMAIL_FROM", "Car Reselling <no-reply@localhost>"),
+		EmailTokenSecret: getEnv("EMAIL_TOKEN_SECRET", ""),
+		PublicURL:        getEnv("PUBLIC_URL", ""),
+
 		// R2 Configuration
-		R2AccountID:       getEnv("R2_ACCOUNT_ID", ""),
-		R2AccessKeyID:     getEnv("R2_ACCESS_KEY_ID", ""),
-		R2SecretAccessKey: getEnv("R2_SECRET_ACCESS_KEY", ""),
-		R2BucketName:      getEnv("R2_BUCKET_NAME", ""),
-		R2PublicURL:       getEnv("R2_PUBLIC_URL", ""),
+		R2AccountID:         getEnv("R2_ACCOUNT_ID", ""),
+		R2AccessKeyID:       getEnv("R2_ACCESS_KEY_ID", ""),
+		R2SecretAccessKey:   getEnv("R2_SECRET_ACCESS_KEY", ""),
+		R2BucketName:        getEnv("R2_BUCKET_NAME", ""),
+		R2PrivateBucketName: getEnv("R2_PRIVATE_BUCKET_NAME", ""),
+		R2PublicURL:         getEnv("R2_PUBLIC_URL", ""),
 
 		// Firebase Configuration
 		FirebaseCredentialsJSON: getEnv("FIREBASE_CREDENTIALS_JSON", ""),
 		FirebaseCredentialsPath: getEnv("FIREBASE_CREDENTIALS_PATH", ""),
+
+		// Listing boosts
+		PaymentCurrency: getEnv("PAYMENT_CURRENCY", "USD"),
+
+		// Payments
+		StripeSecretKey:     getEnv("STRIPE_SECRET_KEY", ""),
+		StripeWebhookSecret: getEnv("STRIPE_WEBHOOK_SECRET", ""),
+		StripeAPIURL:        getEnv("STRIPE_API_URL", "https://api.stripe.com"),
+		PaymentSuccessURL:   getEnv("PAYMENT_SUCCESS_URL", ""),
+		PaymentCancelURL:    getEnv("PAYMENT_CANCEL_URL", ""),
+
+		// Chat cluster
+		NodeID: getEnv("NODE_ID", ""),
+	}
+
+	boostPrice, err := strconv.ParseFloat(getEnv("BOOST_DAILY_PRICE", "5"), 64)
+	if err != nil || boostPrice <= 0 {
+		return nil, fmt.Errorf("BOOST_DAILY_PRICE must be a positive number")
+	}
+	cfg.BoostDailyPrice = boostPrice
+
+	cfg.PaymentCurrency = strings.ToUpper(cfg.PaymentCurrency)
+	if !paymentCurrencies[cfg.PaymentCurrency] {
+		return nil, fmt.Errorf("PAYMENT_CURRENCY %s is not supported; use a currency with two decimals, e.g. USD or EUR", cfg.PaymentCurrency)
 	}
 
 	// Validate required fields
@@ -90,6 +188,22 @@ func Load() (*Config, error) {
 		return nil, fmt.Errorf("JWT_REFRESH_SECRET is required")
 	}
 
+	if cfg.TOTPEncryptionKey == "" {
+		cfg.TOTPEncryptionKey = deriveKey(cfg.JWTSecret, totpKeyLabel)
+	}
+	if cfg.EmailTokenSecret == "" {
+		cfg.EmailTokenSecret = deriveKey(cfg.JWTSecret, emailTokenKeyLabel)
+	}
+	if cfg.PublicURL == "" {
+		cfg.PublicURL = "http://localhost:" + cfg.ServerPort
+	}
+	if cfg.PaymentSuccessURL == "" {
+		cfg.PaymentSuccessURL = cfg.PublicURL + "/payments/success"
+	}
+	if cfg.PaymentCancelURL == "" {
+		cfg.PaymentCancelURL = cfg.PublicURL + "/payments/cancel"
+	}
+
 	// Debug: Print R2 config (hide sensitive data)
 	cfg.PrintR2Config()
 
@@ -103,6 +217,7 @@ func (c *Config) PrintR2Config() {
 	fmt.Printf("Access Key ID:  %s\n", maskString(c.R2AccessKeyID))
 	fmt.Printf("Secret Key:     %s\n", maskString(c.R2SecretAccessKey))
 	fmt.Printf("Bucket Name:    %s\n", c.R2BucketName)
+	fmt.Printf("Private Bucket: %s\n", c.R2PrivateBucketName)
 	fmt.Printf("Public URL:     %s\n", c.R2PublicURL)
 	fmt.Printf("Endpoint:       %s\n", c.GetR2Endpoint())
 	fmt.Println("========================")
@@ -142,6 +257,16 @@ func (c *Config) ValidateR2Config() error {
 	return nil
 }
 
+// deriveKey derives a key for one use from a secret with HKDF-SHA256. Keys
+// derived with different labels are independent of each other and of the secret.
+func deriveKey(secret, label string) string {
+	key := make([]byte, 32)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, []byte(secret), nil, []byte(label)), key); err != nil {
+		panic(fmt.Sprintf("hkdf: %v", err)) // Only fails when reading more than 255 hashes
+	}
+	return hex.EncodeToString(key)
+}
+
 // getEnv retrieves an environment variable or returns a default value
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
@@ -0,0 +1,61 @@
+package dealer
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/yourusername/car-reselling-backend/internal/listing"
+)
+
+// StorefrontRequest is the payload for creating or updating a storefront
+// @Description Dealer storefront details. Changing the business name or address of a verified storefront requires verifying it again.
+type StorefrontRequest struct {
+	BusinessName string       `json:"business_name" binding:"required,min=2,max=150" example:"Sunrise Motors"`
+	Description  string       `json:"description" binding:"omitempty,max=2000" example:"Family-run used car dealer since 1998"`
+	Address      string       `json:"address" binding:"omitempty,max=255" example:"12 Harbor Road"`
+	City         string       `json:"city" binding:"omitempty,max=100" example:"Mumbai"`
+	State        string       `json:"state" binding:"omitempty,max=100" example:"Maharashtra"`
+	Website      string       `json:"website" binding:"omitempty,url,max=255" example:"https://sunrisemotors.example"`
+	OpeningHours OpeningHours `json:"opening_hours" swaggertype:"object,string"` // {"mon": "09:00-18:00", "sun": "closed"}
+}
+
+// ReviewRequest is the payload for reviewing a dealer's verification
+// @Description Admin decision on a dealer verification request
+type ReviewRequest struct {
+	Action string `json:"action" binding:"required,oneof=approve reject" example:"approve"`
+	Note   string `json:"note" binding:"required_if=Action reject,max=500" example:"Registration certificate is unreadable"` // Required when rejecting; shown to the dealer
+}
+
+// StorefrontResponse is a dealer's public storefront
+// @Description Public dealer profile with stats and active listings
+type StorefrontResponse struct {
+	DealerID     uuid.UUID    `json:"dealer_id"`
+	BusinessName string       `json:"business_name"`
+	Description  string       `json:"description"`
+	LogoURL      *string      `json:"logo_url"`
+	Address      string       `json:"address"`
+	City         string       `json:"city"`
+	State        string       `json:"state"`
+	Website      string       `json:"website"`
+	Phone        string       `json:"phone"`
+	OpeningHours OpeningHours `json:"opening_hours" swaggertype:"object,string"`
+	Verified     bool         `json:"verified"`
+	VerifiedAt   *time.Time   `json:"verified_at,omitempty"`
+	MemberSince  time.Time    `json:"member_since"`
+	Stats        Stats        `json:"stats"`
+
+	Listings []listing.Car `json:"listings"`
+	Total    int64         `json:"total"` // Active listings
+	Page     int           `json:"page"`
+	Limit    int           `json:"limit"`
+}
+
+// VerificationQueueResponse is the paginated verification queue
+// @Description Dealers waiting for verification, oldest request first
+type VerificationQueueResponse struct {
+	Items []VerificationRequest `json:"items"`
+	Total int64                 `json:"total"`
+	Page  int                   `json:"page"`
+	Limit int                   `json:"limit"`
+}
@@ -0,0 +1,301 @@
+package dealer
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	appErrors "github.com/yourusername/car-reselling-backend/pkg/errors"
+)
+
+// Handler handles HTTP requests for dealer storefronts
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a new dealer handler
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// RegisterRoutes registers the public storefront and dealer self-service routes
+func (h *Handler) RegisterRoutes(router *gin.RouterGroup, authMiddleware gin.HandlerFunc) {
+	me := router.Group("/dealers/me")
+	me.Use(authMiddleware)
+	{
+		me.GET("", h.GetProfile)
+		me.PUT("", h.SaveProfile)
+		me.POST("/logo", h.UploadLogo)
+		me.POST("/verification", h.SubmitVerification)
+	}
+
+	router.GET("/dealers/:id", h.GetStorefront)
+}
+
+// RegisterAdminRoutes registers the verification review routes on an admin-only group
+func (h *Handler) RegisterAdminRoutes(admin *gin.RouterGroup) {
+	dealers := admin.Group("/dealers")
+	{
+		dealers.GET("/verifications", h.GetVerificationQueue)
+		dealers.POST("/:id/verification", h.Review)
+	}
+}
+
+// GetStorefront returns a dealer's public storefront
+// @Summary Get dealer storefront
+// @Description Public dealer profile with opening hours, verification badge, listing stats and active listings
+// @Tags dealers
+// @Produce json
+// @Param id path string true "Dealer user ID"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Listings per page" default(20)
+// @Success 200 {object} StorefrontResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/dealers/{id} [get]
+func (h *Handler) GetStorefront(c *gin.Context) {
+	dealerID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid dealer ID"})
+		return
+	}
+	page, limit := pagination(c)
+
+	storefront, err := h.service.GetStorefront(c.Request.Context(), dealerID, page, limit)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, storefront)
+}
+
+// GetProfile returns the current dealer's storefront
+// @Summary Get my storefront
+// @Description The current dealer's storefront including verification status and documents
+// @Tags dealers
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} Profile
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/dealers/me [get]
+func (h *Handler) GetProfile(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	profile, err := h.service.GetProfile(c.Request.Context(), userID)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+// SaveProfile creates or updates the current dealer's storefront
+// @Summary Create or update my storefront
+// @Description Dealer accounts only. Changing the business name or address of a verified storefront removes the verified badge until it is verified again.
+// @Tags dealers
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body StorefrontRequest true "Storefront details"
+// @Success 200 {object} Profile
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /api/dealers/me [put]
+func (h *Handler) SaveProfile(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	var req StorefrontRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	profile, err := h.service.SaveProfile(c.Request.Context(), userID, &req)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+// UploadLogo replaces the storefront logo
+// @Summary Upload storefront logo
+// @Tags dealers
+// @Security BearerAuth
+// @Accept multipart/form-data
+// @Produce json
+// @Param logo formData file true "Logo image (jpg or png, max 10MB)"
+// @Success 200 {object} Profile
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/dealers/me/logo [post]
+func (h *Handler) UploadLogo(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	file, err := c.FormFile("logo")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No logo file provided"})
+		return
+	}
+
+	profile, err := h.service.UploadLogo(c.Request.Context(), userID, file)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+// SubmitVerification requests verification of the current dealer's storefront
+// @Summary Request dealer verification
+// @Description Upload business documents (trade licence, registration certificate) as PDFs, scans or photos for review by an admin. Documents are kept in private storage; the dealer and admins get links that expire after 15 minutes.
+// @Tags dealers
+// @Security BearerAuth
+// @Accept multipart/form-data
+// @Produce json
+// @Param documents formData file true "Documents (1-5 pdf, jpg or png files, max 10MB each)"
+// @Success 202 {object} Profile
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/dealers/me/verification [post]
+func (h *Handler) SubmitVerification(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	form, err := c.MultipartForm()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to parse multipart form"})
+		return
+	}
+
+	profile, err := h.service.SubmitVerification(c.Request.Context(), userID, form.File["documents"])
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, profile)
+}
+
+// GetVerificationQueue returns dealers waiting for verification
+// @Summary Get dealer verification queue
+// @Description Storefronts with a pending verification request, oldest first, with signed links to their documents (valid for 15 minutes) and owner contact details
+// @Tags dealers
+// @Security BearerAuth
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} VerificationQueueResponse
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /api/admin/dealers/verifications [get]
+func (h *Handler) GetVerificationQueue(c *gin.Context) {
+	page, limit := pagination(c)
+
+	queue, err := h.service.GetVerificationQueue(c.Request.Context(), page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, queue)
+}
+
+// Review approves or rejects a dealer's verification request
+// @Summary Review dealer verification
+// @Description Approve to show the verified dealer badge, or reject with a reason. The dealer is notified either way.
+// @Tags dealers
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Dealer user ID"
+// @Param request body ReviewRequest true "Admin decision"
+// @Success 200 {object} Profile
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/admin/dealers/{id}/verification [post]
+func (h *Handler) Review(c *gin.Context) {
+	adminID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	dealerID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid dealer ID"})
+		return
+	}
+
+	var req ReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	profile, err := h.service.Review(c.Request.Context(), dealerID, adminID, &req)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+func handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, appErrors.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Dealer storefront not found"})
+	case errors.Is(err, ErrNotDealer):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrVerificationInProgress), errors.Is(err, ErrNotPendingReview):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
+
+func pagination(c *gin.Context) (int, int) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	return page, limit
+}
+
+func getUserID(c *gin.Context) (uuid.UUID, bool) {
+	userID, err := uuid.Parse(c.GetString("userID"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return uuid.Nil, false
+	}
+	return userID, true
+}
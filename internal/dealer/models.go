@@ -0,0 +1,154 @@
+package dealer
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// Verification statuses
+const (
+	VerificationUnverified = "unverified"
+	VerificationPending    = "pending"
+	VerificationVerified   = "verified"
+	VerificationRejected   = "rejected"
+)
+
+// Admin decisions on a verification request
+const (
+	ActionApprove = "approve"
+	ActionReject  = "reject"
+)
+
+// Notification types sent to dealers
+const (
+	NotificationTypeDealerVerified = "dealer_verified"
+	NotificationTypeDealerRejected = "dealer_rejected"
+)
+
+// Profile is a dealer's storefront
+type Profile struct {
+	UserID                uuid.UUID      `json:"user_id" gorm:"type:uuid;primaryKey"`
+	BusinessName          string         `json:"business_name" gorm:"not null"`
+	Description           string         `json:"description"`
+	LogoURL               *string        `json:"logo_url"`
+	Address               string         `json:"address"`
+	City                  string         `json:"city"`
+	State                 string         `json:"state"`
+	Website               string         `json:"website"`
+	OpeningHours          OpeningHours   `json:"opening_hours" gorm:"type:jsonb" swaggertype:"object,string"`
+	VerificationStatus    string         `json:"verification_status" gorm:"type:varchar(20);default:unverified"`
+	VerificationDocuments pq.StringArray `json:"-" gorm:"type:text[]"`                      // Keys in private storage
+	DocumentURLs          []string       `json:"verification_documents,omitempty" gorm:"-"` // Signed links to the documents, shown to the dealer and admins
+	VerificationNote      string         `json:"verification_note,omitempty"`               // Reason given when rejected
+	SubmittedAt           *time.Time     `json:"submitted_at,omitempty"`
+	ReviewedBy            *uuid.UUID     `json:"reviewed_by,omitempty" gorm:"type:uuid"`
+	ReviewedAt            *time.Time     `json:"reviewed_at,omitempty"`
+	VerifiedAt            *time.Time     `json:"verified_at,omitempty"`
+	CreatedAt             time.Time      `json:"created_at"`
+	UpdatedAt             time.Time      `json:"updated_at"`
+}
+
+// TableName overrides the default table name
+func (Profile) TableName() string { return "dealer_profiles" }
+
+// Weekdays are the keys of OpeningHours
+var Weekdays = []string{"mon", "tue", "wed", "thu", "fri", "sat", "sun"}
+
+// OpeningHours maps weekdays to "HH:MM-HH:MM" or "closed". Days left out are unknown.
+type OpeningHours map[string]string
+
+var openingHoursPattern = regexp.MustCompile(`^([01]\d|2[0-3]):[0-5]\d-([01]\d|2[0-3]):[0-5]\d$`)
+
+// Validate checks the day keys and time ranges
+func (h OpeningHours) Validate() error {
+	for day, hours := range h {
+		known := false
+		for _, d := range Weekdays {
+			if day == d {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return fmt.Errorf("invalid opening hours day %q (use mon, tue, wed, thu, fri, sat, sun)", day)
+		}
+		if hours == "closed" {
+			continue
+		}
+		if !openingHoursPattern.MatchString(hours) || hours[:5] >= hours[6:] {
+			return fmt.Errorf("invalid opening hours for %s: %q (use HH:MM-HH:MM or closed)", day, hours)
+		}
+	}
+	return nil
+}
+
+// Value implements driver.Valuer interface for GORM
+func (h OpeningHours) Value() (driver.Value, error) {
+	if h == nil {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(h)
+}
+
+// Scan implements sql.Scanner interface for GORM
+func (h *OpeningHours) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("failed to unmarshal JSONB value: expected []byte")
+	}
+	return json.Unmarshal(bytes, h)
+}
+
+// Stats summarises a dealer's listings
+type Stats struct {
+	ActiveListings int64   `json:"active_listings"`
+	SoldListings   int64   `json:"sold_listings"`
+	TotalViews     int64   `json:"total_views"`
+	AveragePrice   float64 `json:"average_price"` // Of active listings
+}
+
+// maxDocumentSize caps the size of one verification document
+const maxDocumentSize = 10 * 1024 * 1024
+
+// documentExtensions are the accepted verification document types
+var documentExtensions = map[string]bool{".pdf": true, ".jpg": true, ".jpeg": true, ".png": true}
+
+// validateDocuments checks the number, size and type of verification documents
+func validateDocuments(files []*multipart.FileHeader) error {
+	if len(files) == 0 {
+		return errors.New("at least 1 document is required")
+	}
+	if len(files) > MaxVerificationDocuments {
+		return fmt.Errorf("maximum %d documents allowed", MaxVerificationDocuments)
+	}
+	for _, file := range files {
+		if file.Size > maxDocumentSize {
+			return fmt.Errorf("document %s exceeds 10MB limit", file.Filename)
+		}
+		if !documentExtensions[strings.ToLower(filepath.Ext(file.Filename))] {
+			return fmt.Errorf("document %s has invalid type (allowed: pdf, jpg, jpeg, png)", file.Filename)
+		}
+	}
+	return nil
+}
+
+// VerificationRequest is a dealer awaiting verification in the admin queue
+type VerificationRequest struct {
+	Profile
+	OwnerName  string `json:"owner_name"`
+	OwnerEmail string `json:"owner_email"`
+	OwnerPhone string `json:"owner_phone"`
+}
@@ -0,0 +1,57 @@
+package dealer
+
+import (
+	"mime/multipart"
+	"testing"
+)
+
+func TestOpeningHoursValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		hours   OpeningHours
+		wantErr bool
+	}{
+		{"empty", OpeningHours{}, false},
+		{"full week", OpeningHours{"mon": "09:00-18:00", "sat": "10:00-14:00", "sun": "closed"}, false},
+		{"unknown day", OpeningHours{"monday": "09:00-18:00"}, true},
+		{"bad format", OpeningHours{"mon": "9-18"}, true},
+		{"invalid hour", OpeningHours{"mon": "09:00-24:00"}, true},
+		{"closes before opening", OpeningHours{"tue": "18:00-09:00"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.hours.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateDocuments(t *testing.T) {
+	doc := func(name string, size int64) *multipart.FileHeader {
+		return &multipart.FileHeader{Filename: name, Size: size}
+	}
+
+	tests := []struct {
+		name    string
+		files   []*multipart.FileHeader
+		wantErr bool
+	}{
+		{"pdf and photos", []*multipart.FileHeader{doc("licence.pdf", 1024), doc("scan.JPG", 2048), doc("page.png", 512)}, false},
+		{"none", nil, true},
+		{"too many", []*multipart.FileHeader{doc("1.pdf", 1), doc("2.pdf", 1), doc("3.pdf", 1), doc("4.pdf", 1), doc("5.pdf", 1), doc("6.pdf", 1)}, true},
+		{"too large", []*multipart.FileHeader{doc("licence.pdf", maxDocumentSize+1)}, true},
+		{"wrong type", []*multipart.FileHeader{doc("licence.docx", 1024)}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateDocuments(tt.files)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateDocuments() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,189 @@
+package dealer
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+
+	"github.com/yourusername/car-reselling-backend/internal/listing"
+	"github.com/yourusername/car-reselling-backend/internal/models"
+	appErrors "github.com/yourusername/car-reselling-backend/pkg/errors"
+)
+
+// Repository handles database operations for dealer storefronts
+type Repository struct {
+	db *gorm.DB
+}
+
+// NewRepository creates a new dealer repository
+func NewRepository(db *gorm.DB) *Repository {
+	return &Repository{db: db}
+}
+
+// FindUser retrieves an active user that has not been deleted
+func (r *Repository) FindUser(ctx context.Context, userID uuid.UUID) (*models.User, error) {
+	var user models.User
+	err := r.db.WithContext(ctx).
+		Where("id = ? AND is_active = TRUE AND deleted_at IS NULL", userID).
+		First(&user).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, appErrors.ErrNotFound
+	}
+	return &user, err
+}
+
+// --- Profile Operations ---
+
+// FindProfile retrieves a dealer's storefront
+func (r *Repository) FindProfile(ctx context.Context, userID uuid.UUID) (*Profile, error) {
+	var profile Profile
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).First(&profile).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, appErrors.ErrNotFound
+	}
+	return &profile, err
+}
+
+// CreateProfile stores a new storefront
+func (r *Repository) CreateProfile(ctx context.Context, profile *Profile) error {
+	return r.db.WithContext(ctx).Create(profile).Error
+}
+
+// UpdateProfile saves the editable storefront fields and verification status
+func (r *Repository) UpdateProfile(ctx context.Context, profile *Profile) error {
+	return r.db.WithContext(ctx).Model(&Profile{}).
+		Where("user_id = ?", profile.UserID).
+		Updates(map[string]interface{}{
+			"business_name":       profile.BusinessName,
+			"description":         profile.Description,
+			"address":             profile.Address,
+			"city":                profile.City,
+			"state":               profile.State,
+			"website":             profile.Website,
+			"opening_hours":       profile.OpeningHours,
+			"verification_status": profile.VerificationStatus,
+			"verified_at":         profile.VerifiedAt,
+			"updated_at":          time.Now(),
+		}).Error
+}
+
+// UpdateLogo sets the storefront logo
+func (r *Repository) UpdateLogo(ctx context.Context, userID uuid.UUID, logoURL string) error {
+	return r.db.WithContext(ctx).Exec(
+		"UPDATE dealer_profiles SET logo_url = ?, updated_at = NOW() WHERE user_id = ?",
+		logoURL, userID,
+	).Error
+}
+
+// --- Verification ---
+
+// SubmitVerification replaces the verification documents and queues the
+// storefront for review. Returns false if it is already pending or verified.
+func (r *Repository) SubmitVerification(ctx context.Context, userID uuid.UUID, documents []string) (bool, error) {
+	result := r.db.WithContext(ctx).Exec(`
+		UPDATE dealer_profiles SET
+			verification_status = ?, verification_documents = ?, verification_note = '',
+			submitted_at = NOW(), reviewed_by = NULL, reviewed_at = NULL, updated_at = NOW()
+		WHERE user_id = ? AND verification_status IN (?, ?)`,
+		VerificationPending, pq.StringArray(documents),
+		userID, VerificationUnverified, VerificationRejected,
+	)
+	return result.RowsAffected > 0, result.Error
+}
+
+// Review records an admin decision on a pending verification. Returns false if
+// the storefront is not pending.
+func (r *Repository) Review(ctx context.Context, userID, adminID uuid.UUID, status, note string) (bool, error) {
+	now := time.Now()
+	var verifiedAt *time.Time
+	if status == VerificationVerified {
+		verifiedAt = &now
+	}
+
+	result := r.db.WithContext(ctx).Exec(`
+		UPDATE dealer_profiles SET
+			verification_status = ?, verification_note = ?,
+			reviewed_by = ?, reviewed_at = ?, verified_at = ?, updated_at = ?
+		WHERE user_id = ? AND verification_status = ?`,
+		status, note, adminID, now, verifiedAt, now,
+		userID, VerificationPending,
+	)
+	return result.RowsAffected > 0, result.Error
+}
+
+// FindPending retrieves storefronts awaiting verification, oldest request first
+func (r *Repository) FindPending(ctx context.Context, page, limit int) ([]VerificationRequest, int64, error) {
+	var total int64
+	if err := r.db.WithContext(ctx).Model(&Profile{}).
+		Where("verification_status = ?", VerificationPending).
+		Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var items []VerificationRequest
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT dp.*,
+			   u.full_name as owner_name,
+			   u.email as owner_email,
+			   u.phone as owner_phone
+		FROM dealer_profiles dp
+		JOIN users u ON u.id = dp.user_id
+		WHERE dp.verification_status = ?
+		ORDER BY dp.submitted_at ASC
+		LIMIT ? OFFSET ?
+	`, VerificationPending, limit, (page-1)*limit).Scan(&items).Error
+
+	return items, total, err
+}
+
+// --- Listings ---
+
+// FindActiveListings retrieves a dealer's active listings, newest first
+func (r *Repository) FindActiveListings(ctx context.Context, sellerID uuid.UUID, page, limit int) ([]listing.Car, int64, error) {
+	var total int64
+	if err := r.db.WithContext(ctx).Model(&listing.Car{}).
+		Where("seller_id = ? AND status = ?", sellerID, listing.CarStatusActive).
+		Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var cars []listing.Car
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT c.*
+		FROM cars c
+		WHERE c.seller_id = ? AND c.status = ?
+		ORDER BY c.created_at DESC
+		LIMIT ? OFFSET ?
+	`, sellerID, listing.CarStatusActive, limit, (page-1)*limit).Scan(&cars).Error
+
+	return cars, total, err
+}
+
+// FindActiveListingIDs retrieves the IDs of a dealer's active listings
+func (r *Repository) FindActiveListingIDs(ctx context.Context, sellerID uuid.UUID) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.db.WithContext(ctx).Raw(
+		"SELECT id FROM cars WHERE seller_id = ? AND status = ?",
+		sellerID, listing.CarStatusActive,
+	).Scan(&ids).Error
+	return ids, err
+}
+
+// GetStats summarises a dealer's listings
+func (r *Repository) GetStats(ctx context.Context, sellerID uuid.UUID) (*Stats, error) {
+	var stats Stats
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT COUNT(*) FILTER (WHERE status = ?) as active_listings,
+			   COUNT(*) FILTER (WHERE status = ?) as sold_listings,
+			   COALESCE(SUM(views_count), 0) as total_views,
+			   COALESCE(AVG(price) FILTER (WHERE status = ?), 0) as average_price
+		FROM cars
+		WHERE seller_id = ? AND status != ?
+	`, listing.CarStatusActive, listing.CarStatusSold, listing.CarStatusActive,
+		sellerID, listing.CarStatusDeleted).Scan(&stats).Error
+	return &stats, err
+}
@@ -0,0 +1,394 @@
+package dealer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"mime/multipart"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/yourusername/car-reselling-backend/internal/audit"
+	"github.com/yourusername/car-reselling-backend/internal/listing"
+	"github.com/yourusername/car-reselling-backend/internal/models"
+	"github.com/yourusername/car-reselling-backend/internal/notification"
+	appErrors "github.com/yourusername/car-reselling-backend/pkg/errors"
+)
+
+const (
+	// MaxVerificationDocuments caps the documents sent with a verification request
+	MaxVerificationDocuments = 5
+
+	// documentURLLifetime is how long a signed link to a verification document works
+	documentURLLifetime = 15 * time.Minute
+)
+
+var (
+	// ErrNotDealer is returned when a user without the dealer role manages a storefront
+	ErrNotDealer = errors.New("only dealer accounts can have a storefront")
+	// ErrVerificationInProgress is returned when verification is requested while pending or verified
+	ErrVerificationInProgress = errors.New("storefront is already verified or under review")
+	// ErrNotPendingReview is returned when reviewing a storefront that did not request verification
+	ErrNotPendingReview = errors.New("storefront has no pending verification request")
+)
+
+// NotificationSender is the subset of the notification service used to inform dealers
+type NotificationSender interface {
+	CreateAndSend(ctx context.Context, userID uuid.UUID, title, message, notifType, imageURL string, data map[string]interface{}) (*notification.Notification, error)
+}
+
+// AuditLogger records verification decisions
+type AuditLogger interface {
+	Record(ctx context.Context, event audit.Event)
+}
+
+// DocumentStorage keeps verification documents out of public reach. Files
+// are stored by key and opened through short-lived signed URLs.
+type DocumentStorage interface {
+	UploadDocument(ctx context.Context, file *multipart.FileHeader, folder string) (string, error)
+	DocumentURL(ctx context.Context, key string, expires time.Duration) (string, error)
+	DeleteDocuments(ctx context.Context, keys []string) error
+}
+
+// Service handles dealer storefronts and their verification
+type Service struct {
+	repo         *Repository
+	storage      listing.StorageService
+	documents    DocumentStorage
+	cache        *redis.Client
+	notification NotificationSender
+	auditLog     AuditLogger
+}
+
+// NewService creates a new dealer service
+func NewService(repo *Repository, storage listing.StorageService, documents DocumentStorage, cache *redis.Client, notification NotificationSender) *Service {
+	return &Service{
+		repo:         repo,
+		storage:      storage,
+		documents:    documents,
+		cache:        cache,
+		notification: notification,
+	}
+}
+
+// SetAuditLogger sets the audit log for verification decisions
+func (s *Service) SetAuditLogger(l AuditLogger) {
+	s.auditLog = l
+}
+
+// GetStorefront returns a dealer's public storefront with a page of their active listings
+func (s *Service) GetStorefront(ctx context.Context, dealerID uuid.UUID, page, limit int) (*StorefrontResponse, error) {
+	user, err := s.repo.FindUser(ctx, dealerID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Role != models.RoleDealer {
+		return nil, appErrors.ErrNotFound
+	}
+	profile, err := s.repo.FindProfile(ctx, dealerID)
+	if err != nil {
+		return nil, err
+	}
+
+	stats, err := s.repo.GetStats(ctx, dealerID)
+	if err != nil {
+		return nil, err
+	}
+	cars, total, err := s.repo.FindActiveListings(ctx, dealerID, page, limit)
+	if err != nil {
+		return nil, err
+	}
+	if cars == nil {
+		cars = []listing.Car{}
+	}
+
+	resp := &StorefrontResponse{
+		DealerID:     dealerID,
+		BusinessName: profile.BusinessName,
+		Description:  profile.Description,
+		LogoURL:      profile.LogoURL,
+		Address:      profile.Address,
+		City:         profile.City,
+		State:        profile.State,
+		Website:      profile.Website,
+		Phone:        user.Phone,
+		OpeningHours: profile.OpeningHours,
+		Verified:     profile.VerificationStatus == VerificationVerified,
+		MemberSince:  user.CreatedAt,
+		Stats:        *stats,
+		Listings:     cars,
+		Total:        total,
+		Page:         page,
+		Limit:        limit,
+	}
+	if resp.Verified {
+		resp.VerifiedAt = profile.VerifiedAt
+	}
+	if resp.OpeningHours == nil {
+		resp.OpeningHours = OpeningHours{}
+	}
+	return resp, nil
+}
+
+// GetProfile returns the current dealer's storefront, including verification details
+func (s *Service) GetProfile(ctx context.Context, userID uuid.UUID) (*Profile, error) {
+	profile, err := s.repo.FindProfile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	s.signDocuments(ctx, profile)
+	return profile, nil
+}
+
+// SaveProfile creates or updates the current dealer's storefront. Changing the
+// business name or address of a verified storefront withdraws the verification.
+func (s *Service) SaveProfile(ctx context.Context, userID uuid.UUID, req *StorefrontRequest) (*Profile, error) {
+	if err := s.requireDealer(ctx, userID); err != nil {
+		return nil, err
+	}
+	if err := req.OpeningHours.Validate(); err != nil {
+		return nil, err
+	}
+
+	profile, err := s.repo.FindProfile(ctx, userID)
+	if errors.Is(err, appErrors.ErrNotFound) {
+		profile = &Profile{
+			UserID:             userID,
+			VerificationStatus: VerificationUnverified,
+		}
+		applyStorefront(profile, req)
+		if err := s.repo.CreateProfile(ctx, profile); err != nil {
+			return nil, err
+		}
+		return profile, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	reverify := profile.VerificationStatus == VerificationVerified &&
+		(profile.BusinessName != req.BusinessName || profile.Address != req.Address ||
+			profile.City != req.City || profile.State != req.State)
+
+	applyStorefront(profile, req)
+	if reverify {
+		profile.VerificationStatus = VerificationUnverified
+		profile.VerifiedAt = nil
+	}
+	if err := s.repo.UpdateProfile(ctx, profile); err != nil {
+		return nil, err
+	}
+
+	if reverify {
+		s.invalidateListings(ctx, userID)
+	}
+	return profile, nil
+}
+
+// UploadLogo replaces the storefront logo
+func (s *Service) UploadLogo(ctx context.Context, userID uuid.UUID, file *multipart.FileHeader) (*Profile, error) {
+	if err := s.requireDealer(ctx, userID); err != nil {
+		return nil, err
+	}
+	profile, err := s.repo.FindProfile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	files := []*multipart.FileHeader{file}
+	if err := listing.ValidateImages(files); err != nil {
+		return nil, err
+	}
+	urls, err := s.storage.UploadMultipleImages(ctx, files, storageFolder(userID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload logo: %v", err)
+	}
+
+	if err := s.repo.UpdateLogo(ctx, userID, urls[0]); err != nil {
+		go s.storage.DeleteMultipleImages(context.Background(), urls)
+		return nil, err
+	}
+	if profile.LogoURL != nil && *profile.LogoURL != "" {
+		go s.storage.DeleteImage(context.Background(), *profile.LogoURL)
+	}
+
+	profile.LogoURL = &urls[0]
+	return profile, nil
+}
+
+// SubmitVerification stores business documents (PDFs, scans or photos) in
+// private storage and queues the storefront for review by an admin
+func (s *Service) SubmitVerification(ctx context.Context, userID uuid.UUID, files []*multipart.FileHeader) (*Profile, error) {
+	if err := s.requireDealer(ctx, userID); err != nil {
+		return nil, err
+	}
+	profile, err := s.repo.FindProfile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if profile.VerificationStatus == VerificationPending || profile.VerificationStatus == VerificationVerified {
+		return nil, ErrVerificationInProgress
+	}
+
+	if err := validateDocuments(files); err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0, len(files))
+	for _, file := range files {
+		key, err := s.documents.UploadDocument(ctx, file, storageFolder(userID))
+		if err != nil {
+			go s.documents.DeleteDocuments(context.Background(), keys)
+			return nil, fmt.Errorf("failed to upload documents: %v", err)
+		}
+		keys = append(keys, key)
+	}
+
+	submitted, err := s.repo.SubmitVerification(ctx, userID, keys)
+	if err != nil || !submitted {
+		go s.documents.DeleteDocuments(context.Background(), keys)
+		if err != nil {
+			return nil, err
+		}
+		return nil, ErrVerificationInProgress
+	}
+	// Documents of an earlier, rejected request are no longer needed
+	if len(profile.VerificationDocuments) > 0 {
+		go s.documents.DeleteDocuments(context.Background(), profile.VerificationDocuments)
+	}
+
+	return s.GetProfile(ctx, userID)
+}
+
+// GetVerificationQueue returns storefronts awaiting verification
+func (s *Service) GetVerificationQueue(ctx context.Context, page, limit int) (*VerificationQueueResponse, error) {
+	items, total, err := s.repo.FindPending(ctx, page, limit)
+	if err != nil {
+		return nil, err
+	}
+	if items == nil {
+		items = []VerificationRequest{}
+	}
+	for i := range items {
+		s.signDocuments(ctx, &items[i].Profile)
+	}
+	return &VerificationQueueResponse{Items: items, Total: total, Page: page, Limit: limit}, nil
+}
+
+// Review approves or rejects a dealer's verification request and notifies the dealer
+func (s *Service) Review(ctx context.Context, dealerID, adminID uuid.UUID, req *ReviewRequest) (*Profile, error) {
+	status := VerificationVerified
+	if req.Action == ActionReject {
+		status = VerificationRejected
+	}
+
+	reviewed, err := s.repo.Review(ctx, dealerID, adminID, status, req.Note)
+	if err != nil {
+		return nil, err
+	}
+	if !reviewed {
+		if _, err := s.repo.FindProfile(ctx, dealerID); err != nil {
+			return nil, err
+		}
+		return nil, ErrNotPendingReview
+	}
+
+	if status == VerificationVerified {
+		s.invalidateListings(ctx, dealerID)
+	}
+
+	if s.auditLog != nil {
+		s.auditLog.Record(ctx, audit.Event{
+			ActorID:    audit.ActorID(adminID),
+			Action:     audit.ActionDealerVerificationReviewed,
+			TargetType: audit.TargetUser,
+			TargetID:   dealerID.String(),
+			Metadata:   audit.Metadata{"decision": req.Action, "note": req.Note},
+		})
+	}
+
+	title, body, notifType := "Your dealership is verified",
+		"Your storefront now shows the verified dealer badge.", NotificationTypeDealerVerified
+	if status == VerificationRejected {
+		title, body, notifType = "Dealer verification was not approved",
+			"Please check the reason and submit new documents.", NotificationTypeDealerRejected
+		if req.Note != "" {
+			body = "Reason: " + req.Note + " " + body
+		}
+	}
+	go s.notifyDealer(dealerID, title, body, notifType)
+
+	return s.repo.FindProfile(ctx, dealerID)
+}
+
+// Helpers
+
+// requireDealer returns ErrNotDealer unless the user has the dealer role
+func (s *Service) requireDealer(ctx context.Context, userID uuid.UUID) error {
+	user, err := s.repo.FindUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user.Role != models.RoleDealer {
+		return ErrNotDealer
+	}
+	return nil
+}
+
+// invalidateListings drops cached listings of a dealer so their badge is current
+func (s *Service) invalidateListings(ctx context.Context, dealerID uuid.UUID) {
+	if s.cache == nil {
+		return
+	}
+	ids, err := s.repo.FindActiveListingIDs(ctx, dealerID)
+	if err != nil {
+		log.Printf("Failed to find listings of dealer %s: %v", dealerID, err)
+		return
+	}
+	for _, id := range ids {
+		s.cache.Del(ctx, fmt.Sprintf("cache:car:%s", id))
+	}
+}
+
+func (s *Service) notifyDealer(dealerID uuid.UUID, title, body, notifType string) {
+	if s.notification == nil {
+		return
+	}
+	data := map[string]interface{}{"dealer_id": dealerID.String()}
+	if _, err := s.notification.CreateAndSend(context.Background(), dealerID, title, body, notifType, "", data); err != nil {
+		log.Printf("Failed to send %s notification to dealer %s: %v", notifType, dealerID, err)
+	}
+}
+
+func applyStorefront(profile *Profile, req *StorefrontRequest) {
+	profile.BusinessName = req.BusinessName
+	profile.Description = req.Description
+	profile.Address = req.Address
+	profile.City = req.City
+	profile.State = req.State
+	profile.Website = req.Website
+	profile.OpeningHours = req.OpeningHours
+	if profile.OpeningHours == nil {
+		profile.OpeningHours = OpeningHours{}
+	}
+}
+
+// signDocuments fills in short-lived links to the profile's verification documents
+func (s *Service) signDocuments(ctx context.Context, profile *Profile) {
+	profile.DocumentURLs = make([]string, 0, len(profile.VerificationDocuments))
+	for _, key := range profile.VerificationDocuments {
+		url, err := s.documents.DocumentURL(ctx, key, documentURLLifetime)
+		if err != nil {
+			log.Printf("Failed to sign verification document %s: %v", key, err)
+			continue
+		}
+		profile.DocumentURLs = append(profile.DocumentURLs, url)
+	}
+}
+
+// storageFolder keeps dealer files apart from listing images
+func storageFolder(userID uuid.UUID) string {
+	return "dealer-" + userID.String()
+}
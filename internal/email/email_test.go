@@ -0,0 +1,141 @@
+package email
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTemplatesRender(t *testing.T) {
+	templates, err := loadTemplates()
+	if err != nil {
+		t.Fatalf("loadTemplates() error = %v", err)
+	}
+
+	tests := []struct {
+		template    string
+		data        interface{}
+		wantSubject string
+		wantInBody  string
+	}{
+		{TemplateVerifyEmail, VerifyEmailData{Name: "Jane", Link: "http://localhost:3000/api/auth/verify-email?token=a&b", ExpiresIn: "2 days"}, "Verify your email address", "token=a&b"},
+		{TemplateWelcome, WelcomeData{Name: "Jane"}, "Welcome to Car Reselling", "Hi Jane,"},
+		{TemplateListingExpiring, ListingExpiringData{Name: "Jane", Title: "Toyota Camry 2020", ExpiresAt: time.Date(2024, 3, 9, 0, 0, 0, 0, time.UTC), ExpiresIn: "7 days"}, "Your listing expires in 7 days", "Mar 9, 2024"},
+		{TemplateAccountLocked, AccountLockedData{Name: "Jane", IPAddress: "203.0.113.7", LockedFor: "15 minutes"}, "Your account was temporarily locked", "203.0.113.7"},
+		{TemplatePasswordReset, PasswordResetData{Name: "Jane", Code: "482913", ExpiresIn: "10 minutes"}, "Your password reset code", "482913"},
+		{TemplateChatDigest, ChatDigestData{Name: "Jane", TotalUnread: 1, Conversations: []ChatDigestItem{{CarTitle: "Honda Civic", UnreadCount: 1}}}, "You have 1 unread message", "- Honda Civic: 1 new message"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.template, func(t *testing.T) {
+			msg, err := templates[tt.template].render("jane@example.com", tt.data)
+			if err != nil {
+				t.Fatalf("render() error = %v", err)
+			}
+			if msg.Subject != tt.wantSubject {
+				t.Errorf("Subject = %q, want %q", msg.Subject, tt.wantSubject)
+			}
+			if !strings.Contains(msg.Text, tt.wantInBody) {
+				t.Errorf("Text = %q, want it to contain %q", msg.Text, tt.wantInBody)
+			}
+			if !strings.Contains(msg.HTML, "<!DOCTYPE html>") {
+				t.Errorf("HTML is missing the layout: %q", msg.HTML)
+			}
+		})
+	}
+
+	// HTML bodies are escaped
+	msg, _ := templates[TemplateWelcome].render("jane@example.com", WelcomeData{Name: "<b>Jane</b>"})
+	if strings.Contains(msg.HTML, "<b>Jane</b>") {
+		t.Error("HTML body contains unescaped user input")
+	}
+}
+
+func TestSMTPSender(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer ln.Close()
+
+	received := make(chan string, 1)
+	go serveSMTPSink(ln, received)
+
+	host, port, _ := net.SplitHostPort(ln.Addr().String())
+	sender, err := NewSMTPSender(host, port, "", "", "Car Reselling <no-reply@example.com>")
+	if err != nil {
+		t.Fatalf("NewSMTPSender() error = %v", err)
+	}
+
+	err = sender.Send(context.Background(), Message{
+		To:      "jane@example.com",
+		Subject: "Vérifiez votre email",
+		Text:    "Hello Jane",
+		HTML:    "<p>Hello Jane</p>",
+	})
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	data := <-received
+	for _, want := range []string{
+		"From: \"Car Reselling\" <no-reply@example.com>",
+		"To: <jane@example.com>",
+		"Subject: =?utf-8?q?",
+		"multipart/alternative",
+		"Hello Jane",
+		"<p>Hello Jane</p>",
+	} {
+		if !strings.Contains(data, want) {
+			t.Errorf("message is missing %q:\n%s", want, data)
+		}
+	}
+}
+
+// serveSMTPSink accepts one SMTP session and sends the DATA it received
+func serveSMTPSink(ln net.Listener, received chan<- string) {
+	conn, err := ln.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	r := bufio.NewReader(conn)
+	reply := func(s string) { conn.Write([]byte(s + "\r\n")) }
+
+	reply("220 sink ready")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		cmd := strings.ToUpper(strings.TrimSpace(line))
+		switch {
+		case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+			reply("250 sink")
+		case strings.HasPrefix(cmd, "DATA"):
+			reply("354 go ahead")
+			var data strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				data.WriteString(l)
+			}
+			received <- data.String()
+			reply("250 queued")
+		case strings.HasPrefix(cmd, "QUIT"):
+			reply("221 bye")
+			return
+		default:
+			reply("250 ok")
+		}
+	}
+}
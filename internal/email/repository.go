@@ -0,0 +1,44 @@
+package email
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	appErrors "github.com/yourusername/car-reselling-backend/pkg/errors"
+)
+
+// Recipient is the part of a user an email is addressed with
+type Recipient struct {
+	Email         string
+	FullName      string
+	EmailVerified bool
+	IsActive      bool
+}
+
+// Repository looks up email recipients
+type Repository struct {
+	db *gorm.DB
+}
+
+// NewRepository creates a new email repository
+func NewRepository(db *gorm.DB) *Repository {
+	return &Repository{db: db}
+}
+
+// FindRecipient retrieves the email address and name of a user
+func (r *Repository) FindRecipient(ctx context.Context, userID uuid.UUID) (*Recipient, error) {
+	var rec Recipient
+	result := r.db.WithContext(ctx).Raw(`
+		SELECT email, full_name, email_verified, is_active
+		FROM users
+		WHERE id = ?`, userID).Scan(&rec)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, appErrors.ErrNotFound
+	}
+	return &rec, nil
+}
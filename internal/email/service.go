@@ -0,0 +1,147 @@
+package email
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Service renders and sends transactional email
+type Service struct {
+	sender    Sender
+	repo      *Repository
+	templates map[string]*emailTemplate
+}
+
+// NewService creates a new email service
+func NewService(sender Sender, repo *Repository) (*Service, error) {
+	templates, err := loadTemplates()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Service{
+		sender:    sender,
+		repo:      repo,
+		templates: templates,
+	}, nil
+}
+
+// Send renders a template and sends it to an address
+func (s *Service) Send(ctx context.Context, to, template string, data interface{}) error {
+	tmpl, ok := s.templates[template]
+	if !ok {
+		return fmt.Errorf("unknown email template %q", template)
+	}
+
+	msg, err := tmpl.render(to, data)
+	if err != nil {
+		return fmt.Errorf("render %s email: %w", template, err)
+	}
+
+	if err := s.sender.Send(ctx, msg); err != nil {
+		return fmt.Errorf("send %s email: %w", template, err)
+	}
+	return nil
+}
+
+// --- Account ---
+
+// SendVerification sends an email verification link
+func (s *Service) SendVerification(ctx context.Context, to, name, link string, ttl time.Duration) error {
+	return s.Send(ctx, to, TemplateVerifyEmail, VerifyEmailData{
+		Name:      name,
+		Link:      link,
+		ExpiresIn: formatDuration(ttl),
+	})
+}
+
+// SendWelcome sends the welcome email
+func (s *Service) SendWelcome(ctx context.Context, to, name string) error {
+	return s.Send(ctx, to, TemplateWelcome, WelcomeData{Name: name})
+}
+
+// SendAccountLocked warns a user that their account was locked after failed logins
+func (s *Service) SendAccountLocked(ctx context.Context, to, name, ipAddress string, lockedFor time.Duration) error {
+	return s.Send(ctx, to, TemplateAccountLocked, AccountLockedData{
+		Name:      name,
+		IPAddress: ipAddress,
+		LockedFor: formatDuration(lockedFor),
+	})
+}
+
+// SendPasswordReset sends a password reset code
+func (s *Service) SendPasswordReset(ctx context.Context, to, name, code string, ttl time.Duration) error {
+	return s.Send(ctx, to, TemplatePasswordReset, PasswordResetData{
+		Name:      name,
+		Code:      code,
+		ExpiresIn: formatDuration(ttl),
+	})
+}
+
+// --- Notifications ---
+// These go only to active users with a verified email; others are skipped.
+
+// SendListingExpiring tells a seller their listing is about to expire
+func (s *Service) SendListingExpiring(ctx context.Context, sellerID uuid.UUID, title string, expiresAt time.Time) error {
+	rec, ok, err := s.verifiedRecipient(ctx, sellerID)
+	if err != nil || !ok {
+		return err
+	}
+
+	return s.Send(ctx, rec.Email, TemplateListingExpiring, ListingExpiringData{
+		Name:      rec.FullName,
+		Title:     title,
+		ExpiresAt: expiresAt,
+		ExpiresIn: formatDuration(time.Until(expiresAt)),
+	})
+}
+
+// SendChatDigest sends a summary of unread chat messages
+func (s *Service) SendChatDigest(ctx context.Context, userID uuid.UUID, conversations []ChatDigestItem) error {
+	if len(conversations) == 0 {
+		return nil
+	}
+
+	rec, ok, err := s.verifiedRecipient(ctx, userID)
+	if err != nil || !ok {
+		return err
+	}
+
+	total := 0
+	for _, c := range conversations {
+		total += c.UnreadCount
+	}
+
+	return s.Send(ctx, rec.Email, TemplateChatDigest, ChatDigestData{
+		Name:          rec.FullName,
+		TotalUnread:   total,
+		Conversations: conversations,
+	})
+}
+
+// verifiedRecipient looks up a user and reports whether they can be emailed
+func (s *Service) verifiedRecipient(ctx context.Context, userID uuid.UUID) (*Recipient, bool, error) {
+	rec, err := s.repo.FindRecipient(ctx, userID)
+	if err != nil {
+		return nil, false, err
+	}
+	return rec, rec.IsActive && rec.EmailVerified, nil
+}
+
+// formatDuration renders a duration as whole days, or hours under two days
+func formatDuration(d time.Duration) string {
+	hours := int(d.Round(time.Hour).Hours())
+	switch {
+	case hours >= 48:
+		return fmt.Sprintf("%d days", hours/24)
+	case hours == 1:
+		return "1 hour"
+	case hours < 1:
+		return "less than an hour"
+	default:
+		return fmt.Sprintf("%d hours", hours)
+	}
+}
@@ -0,0 +1,126 @@
+package email
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+	"time"
+)
+
+// appName is shown in email subjects and the HTML layout
+const appName = "Car Reselling"
+
+// Email templates. Each has templates/<name>.html (the "content" block of
+// layout.html) and templates/<name>.txt (the "subject" and "body" blocks).
+const (
+	TemplateVerifyEmail     = "verify_email"
+	TemplateWelcome         = "welcome"
+	TemplateListingExpiring = "listing_expiring"
+	TemplateChatDigest      = "chat_digest"
+	TemplateAccountLocked   = "account_locked"
+	TemplatePasswordReset   = "password_reset"
+)
+
+//go:embed templates
+var templateFS embed.FS
+
+// VerifyEmailData is the data for TemplateVerifyEmail
+type VerifyEmailData struct {
+	Name      string
+	Link      string
+	ExpiresIn string
+}
+
+// WelcomeData is the data for TemplateWelcome
+type WelcomeData struct {
+	Name string
+}
+
+// AccountLockedData is the data for TemplateAccountLocked
+type AccountLockedData struct {
+	Name      string
+	IPAddress string
+	LockedFor string
+}
+
+// PasswordResetData is the data for TemplatePasswordReset
+type PasswordResetData struct {
+	Name      string
+	Code      string
+	ExpiresIn string
+}
+
+// ListingExpiringData is the data for TemplateListingExpiring
+type ListingExpiringData struct {
+	Name      string
+	Title     string
+	ExpiresAt time.Time
+	ExpiresIn string
+}
+
+// ChatDigestItem is one conversation with unread messages
+type ChatDigestItem struct {
+	CarTitle    string
+	UnreadCount int
+}
+
+// ChatDigestData is the data for TemplateChatDigest
+type ChatDigestData struct {
+	Name          string
+	TotalUnread   int
+	Conversations []ChatDigestItem
+}
+
+var templateFuncs = map[string]interface{}{
+	"appName": func() string { return appName },
+}
+
+type emailTemplate struct {
+	html *htmltemplate.Template
+	text *texttemplate.Template
+}
+
+// loadTemplates parses all email templates
+func loadTemplates() (map[string]*emailTemplate, error) {
+	names := []string{TemplateVerifyEmail, TemplateWelcome, TemplateListingExpiring, TemplateChatDigest, TemplateAccountLocked, TemplatePasswordReset}
+
+	templates := make(map[string]*emailTemplate, len(names))
+	for _, name := range names {
+		html, err := htmltemplate.New(name).Funcs(templateFuncs).
+			ParseFS(templateFS, "templates/layout.html", "templates/"+name+".html")
+		if err != nil {
+			return nil, fmt.Errorf("parse %s.html: %w", name, err)
+		}
+		text, err := texttemplate.New(name).Funcs(templateFuncs).
+			ParseFS(templateFS, "templates/"+name+".txt")
+		if err != nil {
+			return nil, fmt.Errorf("parse %s.txt: %w", name, err)
+		}
+		templates[name] = &emailTemplate{html: html, text: text}
+	}
+	return templates, nil
+}
+
+// render executes the template for one recipient
+func (t *emailTemplate) render(to string, data interface{}) (Message, error) {
+	var subject, text, html bytes.Buffer
+	if err := t.text.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return Message{}, err
+	}
+	if err := t.text.ExecuteTemplate(&text, "body", data); err != nil {
+		return Message{}, err
+	}
+	if err := t.html.ExecuteTemplate(&html, "layout", data); err != nil {
+		return Message{}, err
+	}
+
+	return Message{
+		To:      to,
+		Subject: strings.TrimSpace(subject.String()),
+		Text:    strings.TrimSpace(text.String()) + "\n",
+		HTML:    html.String(),
+	}, nil
+}
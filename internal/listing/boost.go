@@ -0,0 +1,249 @@
+package listing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/yourusername/car-reselling-backend/internal/audit"
+	"github.com/yourusername/car-reselling-backend/internal/payments"
+)
+
+const (
+	// FeaturedPerPage is how many featured listings are pinned above the other results
+	FeaturedPerPage = 3
+
+	// boostInterval is how often the boost worker starts and ends boosts
+	boostInterval = time.Minute
+
+	// boostCheckoutLifetime is how long a seller has to pay for a boost before
+	// its featured slot is released. Stripe accepts 30 minutes to 24 hours.
+	boostCheckoutLifetime = time.Hour
+)
+
+var (
+	// ErrBoostOverlap is returned when boosting a listing for a time it is already boosted
+	ErrBoostOverlap = errors.New("listing is already boosted during this time")
+
+	// errFeaturedSlotsFull is returned by the repository when the seller's
+	// overlapping boosts use all their featured slots
+	errFeaturedSlotsFull = errors.New("featured slots full")
+)
+
+// BoostListing reserves a boost featuring a listing for req.Days days from
+// req.StartsAt (or now) and starts a checkout for it. The boost is scheduled
+// once the payment succeeds, and starts when its window begins.
+func (s *ListingService) BoostListing(ctx context.Context, carID, userID uuid.UUID, req *BoostRequest) (*BoostCheckoutResponse, error) {
+	if s.payments == nil {
+		return nil, ErrPaymentsUnavailable
+	}
+
+	car, err := s.repo.FindByID(ctx, carID)
+	if err != nil {
+		return nil, err
+	}
+	if car.SellerID != userID {
+		return nil, errors.New("unauthorized: you do not own this listing")
+	}
+	if car.Status != CarStatusActive {
+		return nil, fmt.Errorf("cannot boost a %s listing", car.Status)
+	}
+
+	now := time.Now()
+	startsAt, endsAt, err := boostWindow(now, req.StartsAt, req.Days)
+	if err != nil {
+		return nil, err
+	}
+	if endsAt.After(car.ExpiresAt) {
+		return nil, fmt.Errorf("boost would end after the listing expires on %s, renew it first", car.ExpiresAt.Format("2006-01-02"))
+	}
+
+	boost := &Boost{
+		ID:        uuid.New(),
+		CarID:     carID,
+		SellerID:  userID,
+		StartsAt:  startsAt,
+		EndsAt:    endsAt,
+		Amount:    s.boostPricing.Price(req.Days),
+		Currency:  s.boostPricing.Currency,
+		Status:    BoostStatusPending,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	// Featured slots count the seller's boosts overlapping the new window,
+	// including ones still awaiting payment
+	quota := s.quotaFor(ctx, userID)
+	if err := s.repo.CreateBoost(ctx, boost, quota.FeaturedSlots); err != nil {
+		if errors.Is(err, errFeaturedSlotsFull) {
+			return nil, &QuotaExceededError{Quota: QuotaFeaturedSlots, Limit: quota.FeaturedSlots, Plan: quota.Plan}
+		}
+		return nil, err
+	}
+
+	checkout, err := s.payments.CreateCheckout(ctx, userID, payments.CheckoutOptions{
+		Purpose:     payments.PurposeBoost,
+		ReferenceID: boost.ID.String(),
+		Description: fmt.Sprintf("Featured listing for %d days: %s", req.Days, car.Title),
+		Amount:      boost.Amount,
+		Currency:    boost.Currency,
+		ExpiresAt:   now.Add(boostCheckoutLifetime),
+	})
+	if err != nil {
+		if _, markErr := s.repo.SetBoostPayment(ctx, boost.ID, BoostStatusFailed, ""); markErr != nil {
+			log.Printf("Failed to mark boost %s as failed: %v", boost.ID, markErr)
+		}
+		if errors.Is(err, payments.ErrNotConfigured) {
+			return nil, ErrPaymentsUnavailable
+		}
+		return nil, fmt.Errorf("payment failed: %w", err)
+	}
+
+	reference := checkout.PaymentID.String()
+	if _, err := s.repo.SetBoostPayment(ctx, boost.ID, BoostStatusPending, reference); err != nil {
+		log.Printf("Failed to store payment %s of boost %s: %v", reference, boost.ID, err)
+	}
+	boost.PaymentReference = reference
+
+	return &BoostCheckoutResponse{
+		Boost:       *boost,
+		PaymentID:   checkout.PaymentID,
+		CheckoutURL: checkout.CheckoutURL,
+	}, nil
+}
+
+// PaymentSucceeded schedules the boost a payment was for (payments.Fulfiller).
+// Boosts whose window has already begun start right away. A boost this
+// payment already scheduled is left as it is.
+func (s *ListingService) PaymentSucceeded(ctx context.Context, payment *payments.Payment) error {
+	boostID, err := uuid.Parse(payment.ReferenceID)
+	if err != nil {
+		return fmt.Errorf("invalid boost reference %q", payment.ReferenceID)
+	}
+
+	boost, err := s.repo.SetBoostPayment(ctx, boostID, BoostStatusScheduled, payment.ID.String())
+	if err != nil {
+		return err
+	}
+	if boost == nil {
+		existing, err := s.repo.FindBoost(ctx, boostID)
+		if err != nil {
+			return err
+		}
+		if existing.PaymentReference == payment.ID.String() && existing.Status != BoostStatusFailed {
+			return nil
+		}
+		return fmt.Errorf("boost %s is no longer awaiting payment", boostID)
+	}
+
+	// Start right away instead of waiting for the worker
+	now := time.Now()
+	if !boost.StartsAt.After(now) {
+		s.startBoosts(ctx, now)
+	}
+
+	s.audit(ctx, audit.ActionListingBoosted, boost.SellerID, boost.CarID, nil, audit.Metadata{
+		"boost_id":   boost.ID,
+		"payment_id": payment.ID,
+		"starts_at":  boost.StartsAt,
+		"ends_at":    boost.EndsAt,
+		"amount":     boost.Amount,
+		"currency":   boost.Currency,
+	})
+	return nil
+}
+
+// PaymentFailed releases the featured slot held by a boost whose checkout
+// failed or expired (payments.Fulfiller)
+func (s *ListingService) PaymentFailed(ctx context.Context, payment *payments.Payment) error {
+	boostID, err := uuid.Parse(payment.ReferenceID)
+	if err != nil {
+		return fmt.Errorf("invalid boost reference %q", payment.ReferenceID)
+	}
+	_, err = s.repo.SetBoostPayment(ctx, boostID, BoostStatusFailed, payment.ID.String())
+	return err
+}
+
+// GetBoosts returns a listing's boosts, newest first, to its seller
+func (s *ListingService) GetBoosts(ctx context.Context, carID, userID uuid.UUID) ([]Boost, error) {
+	car, err := s.repo.FindByID(ctx, carID)
+	if err != nil {
+		return nil, err
+	}
+	if car.SellerID != userID {
+		return nil, errors.New("unauthorized: you do not own this listing")
+	}
+
+	boosts, err := s.repo.FindBoosts(ctx, carID)
+	if err != nil {
+		return nil, err
+	}
+	if boosts == nil {
+		boosts = []Boost{}
+	}
+	return boosts, nil
+}
+
+// RunBoostWorker periodically starts boosts whose window has begun and
+// un-features listings whose window has ended
+func (s *ListingService) RunBoostWorker() {
+	ticker := time.NewTicker(boostInterval)
+	defer ticker.Stop()
+
+	for {
+		s.ProcessBoosts(context.Background())
+		<-ticker.C
+	}
+}
+
+// ProcessBoosts runs one boost pass: start due boosts, then end finished ones
+func (s *ListingService) ProcessBoosts(ctx context.Context) {
+	now := time.Now()
+	s.startBoosts(ctx, now)
+
+	ended, err := s.repo.EndExpiredBoosts(ctx, now)
+	if err != nil {
+		log.Printf("Failed to end expired boosts: %v", err)
+		return
+	}
+	s.invalidateCars(ctx, ended)
+	if len(ended) > 0 {
+		log.Printf("Un-featured %d listings", len(ended))
+	}
+}
+
+// startBoosts activates paid boosts whose window has begun
+func (s *ListingService) startBoosts(ctx context.Context, now time.Time) {
+	started, err := s.repo.ActivateDueBoosts(ctx, now)
+	if err != nil {
+		log.Printf("Failed to start due boosts: %v", err)
+		return
+	}
+	s.invalidateCars(ctx, started)
+}
+
+// invalidateCars drops the cached copies of the given listings
+func (s *ListingService) invalidateCars(ctx context.Context, carIDs []uuid.UUID) {
+	for _, id := range carIDs {
+		s.cache.Del(ctx, fmt.Sprintf("cache:car:%s", id))
+	}
+}
+
+// boostWindow returns the start and end of a boost of the given length. A
+// missing or just-passed start means now.
+func boostWindow(now time.Time, startsAt *time.Time, days int) (time.Time, time.Time, error) {
+	start := now
+	if startsAt != nil {
+		// Allow for clock skew and request latency
+		if startsAt.Before(now.Add(-time.Minute)) {
+			return time.Time{}, time.Time{}, errors.New("starts_at must not be in the past")
+		}
+		if startsAt.After(now) {
+			start = *startsAt
+		}
+	}
+	return start, start.AddDate(0, 0, days), nil
+}
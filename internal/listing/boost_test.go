@@ -0,0 +1,41 @@
+package listing
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBoostWindow(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	later := now.Add(48 * time.Hour)
+	skewed := now.Add(-30 * time.Second)
+	past := now.Add(-time.Hour)
+
+	tests := []struct {
+		name      string
+		startsAt  *time.Time
+		wantStart time.Time
+		wantErr   bool
+	}{
+		{"no start", nil, now, false},
+		{"future start", &later, later, false},
+		{"just passed", &skewed, now, false},
+		{"past start", &past, time.Time{}, true},
+	}
+
+	for _, tt := range tests {
+		start, end, err := boostWindow(now, tt.startsAt, 7)
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("%s: unexpected error %v", tt.name, err)
+		}
+		if tt.wantErr {
+			continue
+		}
+		if !start.Equal(tt.wantStart) {
+			t.Errorf("%s: start = %v, want %v", tt.name, start, tt.wantStart)
+		}
+		if want := tt.wantStart.AddDate(0, 0, 7); !end.Equal(want) {
+			t.Errorf("%s: end = %v, want %v", tt.name, end, want)
+		}
+	}
+}
@@ -1,5 +1,11 @@
 package listing
 
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
 // CreateCarRequest represents the payload for creating a listing
 // @Description Request payload for creating a new car listing
 type CreateCarRequest struct {
@@ -46,11 +52,27 @@ type UpdateCarRequest struct {
 	ExistingImages []string `form:"existing_images" binding:"omitempty"`
 }
 
+// BoostRequest represents the payload for boosting a listing
+// @Description Request payload for featuring a listing for a number of days
+type BoostRequest struct {
+	Days     int        `json:"days" binding:"required,min=1,max=30" example:"7"`
+	StartsAt *time.Time `json:"starts_at" binding:"omitempty" example:"2026-01-01T09:00:00Z"` // Defaults to now
+}
+
+// BoostCheckoutResponse is a boost awaiting payment
+// @Description The boost is scheduled once the seller pays at checkout_url; unpaid boosts are released after an hour
+type BoostCheckoutResponse struct {
+	Boost       Boost     `json:"boost"`
+	PaymentID   uuid.UUID `json:"payment_id"`
+	CheckoutURL string    `json:"checkout_url"`
+}
+
 // ListCarsQuery represents the query parameters for listing cars
 // @Description Query parameters for filtering and searching cars
 type ListCarsQuery struct {
 	Page      int     `form:"page,default=1" binding:"min=1" example:"1"`
 	Limit     int     `form:"limit,default=20" binding:"min=1,max=100" example:"20"`
+	Query     string  `form:"q" binding:"omitempty,max=200" example:"camry hybrid low mileage"`
 	Make      string  `form:"make" example:"Toyota"`
 	Model     string  `form:"model" example:"Camry"`
 	MinPrice  float64 `form:"min_price" binding:"omitempty,min=0" example:"10000"`
@@ -58,13 +80,75 @@ type ListCarsQuery struct {
 	City      string  `form:"city" example:"New York"`
 	State     string  `form:"state" example:"NY"`
 	Condition string  `form:"condition" example:"excellent"`
-	SortBy    string  `form:"sort_by,default=created_at_desc" binding:"oneof=created_at_desc price_asc price_desc year_desc year_asc" example:"created_at_desc"`
+	SortBy    string  `form:"sort_by,default=created_at_desc" binding:"oneof=created_at_desc price_asc price_desc year_desc year_asc relevance distance_asc" example:"created_at_desc"`
+
+	// Geo search: distance from lat/lng (optionally limited to radius_km) and/or a bounding box
+	Lat      *float64 `form:"lat" binding:"omitempty,latitude" example:"40.7128"`
+	Lng      *float64 `form:"lng" binding:"omitempty,longitude" example:"-74.0060"`
+	RadiusKm float64  `form:"radius_km" binding:"omitempty,gt=0" example:"25"`
+	BBox     string   `form:"bbox" example:"-74.26,40.49,-73.70,40.92"` // min_lng,min_lat,max_lng,max_lat
 }
 
 // CarResponse represents the API response for a car
 // @Description Detailed car information response
 type CarResponse struct {
 	Car
-	IsFavorited bool `json:"is_favorited" example:"false"`
-	IsOwner     bool `json:"is_owner" example:"true"`
+	IsFavorited  bool          `json:"is_favorited" example:"false"`
+	IsOwner      bool          `json:"is_owner" example:"true"`
+	PriceSummary *PriceSummary `json:"price_summary,omitempty"`
+}
+
+// PriceSummary summarises how a listing's price has moved since it was posted
+// @Description Original and lowest price, number of drops and last change date
+type PriceSummary struct {
+	OriginalPrice float64    `json:"original_price" example:"27000"`
+	LowestPrice   float64    `json:"lowest_price" example:"24000"`
+	PriceDrops    int        `json:"price_drops" example:"2"`
+	LastChangedAt *time.Time `json:"last_changed_at,omitempty"` // nil if the price never changed
+}
+
+// PriceHistoryResponse is the response for a listing's price history
+// @Description Price summary and every price change, oldest first
+type PriceHistoryResponse struct {
+	CarID   uuid.UUID     `json:"car_id"`
+	Summary PriceSummary  `json:"summary"`
+	History []PriceChange `json:"history"`
+}
+
+// MapClustersQuery represents the query parameters for map clustering
+// @Description Viewport, zoom level and the same filters as listing search
+type MapClustersQuery struct {
+	ListCarsQuery
+	Zoom int `form:"zoom" binding:"min=0,max=22" example:"10"`
+}
+
+// MapCluster is an aggregated group of listings in one grid cell
+// @Description Listings grouped into one map cell
+type MapCluster struct {
+	Count     int64      `json:"count" example:"42"`
+	Latitude  float64    `json:"latitude" example:"40.7128"`
+	Longitude float64    `json:"longitude" example:"-74.0060"`
+	MinPrice  float64    `json:"min_price" example:"8000"`
+	MaxPrice  float64    `json:"max_price" example:"65000"`
+	CarID     *uuid.UUID `json:"car_id,omitempty"` // Set when the cell holds a single listing
+}
+
+// MapMarker is a single listing pin on the map
+// @Description Individual listing marker
+type MapMarker struct {
+	ID        uuid.UUID `json:"id"`
+	Title     string    `json:"title" example:"Toyota Camry 2020"`
+	Price     float64   `json:"price" example:"25000"`
+	Latitude  float64   `json:"latitude" example:"40.7128"`
+	Longitude float64   `json:"longitude" example:"-74.0060"`
+	ImageURL  string    `json:"image_url,omitempty"`
+}
+
+// MapClustersResponse is the response for the map clustering endpoint
+// @Description Clusters when zoomed out, individual listings when zoomed in
+type MapClustersResponse struct {
+	Zoom     int          `json:"zoom" example:"10"`
+	CellSize float64      `json:"cell_size" example:"0.0879"` // Grid cell size in degrees (0 when listings are returned)
+	Clusters []MapCluster `json:"clusters"`
+	Listings []MapMarker  `json:"listings"`
 }
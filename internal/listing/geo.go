@@ -0,0 +1,107 @@
+package listing
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const (
+	// earthRadiusKm is the mean Earth radius used for distance calculations
+	earthRadiusKm = 6371.0
+
+	// kmPerDegreeLat is the approximate distance covered by one degree of latitude
+	kmPerDegreeLat = 111.045
+
+	// MaxRadiusKm caps the radius of a "near me" search
+	MaxRadiusKm = 500
+
+	// SortByDistance orders listings by distance from lat/lng (nearest first)
+	SortByDistance = "distance_asc"
+
+	// MaxClusterZoom is the zoom level from which individual listings are returned instead of clusters
+	MaxClusterZoom = 15
+
+	// clusterCellsPerTile is how many grid cells span one map tile horizontally
+	clusterCellsPerTile = 4
+
+	// maxMapClusters caps the number of cells returned for one viewport
+	maxMapClusters = 1000
+
+	// maxMapMarkers caps the number of individual listings returned for one viewport
+	maxMapMarkers = 500
+)
+
+// BoundingBox is a rectangular area in degrees
+type BoundingBox struct {
+	MinLat float64
+	MinLng float64
+	MaxLat float64
+	MaxLng float64
+}
+
+// ParseBoundingBox parses a "min_lng,min_lat,max_lng,max_lat" string
+func ParseBoundingBox(s string) (*BoundingBox, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != 4 {
+		return nil, fmt.Errorf("bbox must be min_lng,min_lat,max_lng,max_lat")
+	}
+
+	values := make([]float64, 4)
+	for i, part := range parts {
+		v, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return nil, fmt.Errorf("bbox contains an invalid number: %q", part)
+		}
+		values[i] = v
+	}
+
+	box := &BoundingBox{MinLng: values[0], MinLat: values[1], MaxLng: values[2], MaxLat: values[3]}
+	if box.MinLat < -90 || box.MaxLat > 90 || box.MinLng < -180 || box.MaxLng > 180 {
+		return nil, fmt.Errorf("bbox is out of range")
+	}
+	if box.MinLat > box.MaxLat || box.MinLng > box.MaxLng {
+		return nil, fmt.Errorf("bbox min values must not exceed max values")
+	}
+	return box, nil
+}
+
+// radiusBounds returns the bounding box enclosing a circle around a point,
+// used as an index-friendly prefilter before the exact distance check
+func radiusBounds(lat, lng, radiusKm float64) BoundingBox {
+	latDelta := radiusKm / kmPerDegreeLat
+	lngDelta := 180.0
+	if cos := math.Cos(lat * math.Pi / 180); cos > 0.000001 {
+		lngDelta = math.Min(radiusKm/(kmPerDegreeLat*cos), 180)
+	}
+	box := BoundingBox{
+		MinLat: math.Max(lat-latDelta, -90),
+		MaxLat: math.Min(lat+latDelta, 90),
+		MinLng: lng - lngDelta,
+		MaxLng: lng + lngDelta,
+	}
+	// The circle wraps around the antimeridian, fall back to every longitude
+	if box.MinLng < -180 || box.MaxLng > 180 {
+		box.MinLng, box.MaxLng = -180, 180
+	}
+	return box
+}
+
+// distanceSQL returns a haversine expression (in km) between c.latitude/c.longitude
+// and a point, along with its args
+func distanceSQL(lat, lng float64) (string, []interface{}) {
+	expr := fmt.Sprintf(`(%g * 2 * ASIN(SQRT(
+		POWER(SIN(RADIANS(c.latitude - ?) / 2), 2) +
+		COS(RADIANS(?)) * COS(RADIANS(c.latitude)) *
+		POWER(SIN(RADIANS(c.longitude - ?) / 2), 2)
+	)))`, earthRadiusKm)
+	return expr, []interface{}{lat, lat, lng}
+}
+
+// clusterCellSize returns the grid cell size in degrees for a map zoom level.
+// A web map tile spans 360/2^zoom degrees of longitude; each tile is split into
+// clusterCellsPerTile cells so clusters stay a roughly constant size on screen.
+func clusterCellSize(zoom int) float64 {
+	return 360.0 / (math.Pow(2, float64(zoom)) * clusterCellsPerTile)
+}
@@ -0,0 +1,39 @@
+package listing
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParseBoundingBox(t *testing.T) {
+	box, err := ParseBoundingBox("-74.26, 40.49, -73.70, 40.92")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if box.MinLng != -74.26 || box.MinLat != 40.49 || box.MaxLng != -73.70 || box.MaxLat != 40.92 {
+		t.Errorf("unexpected box: %+v", box)
+	}
+
+	invalid := []string{"", "1,2,3", "a,b,c,d", "0,0,200,10", "10,10,0,0"}
+	for _, s := range invalid {
+		if _, err := ParseBoundingBox(s); err == nil {
+			t.Errorf("ParseBoundingBox(%q) expected error", s)
+		}
+	}
+}
+
+func TestRadiusBounds(t *testing.T) {
+	box := radiusBounds(0, 0, kmPerDegreeLat)
+	if math.Abs(box.MaxLat-1) > 1e-9 || math.Abs(box.MinLat+1) > 1e-9 {
+		t.Errorf("expected ±1 degree latitude at the equator, got %+v", box)
+	}
+	if math.Abs(box.MaxLng-1) > 1e-9 || math.Abs(box.MinLng+1) > 1e-9 {
+		t.Errorf("expected ±1 degree longitude at the equator, got %+v", box)
+	}
+
+	// Near the poles the longitude span widens up to the full range
+	polar := radiusBounds(89.99, 10, 50)
+	if polar.MaxLat != 90 || polar.MinLng != -180 || polar.MaxLng != 180 {
+		t.Errorf("expected clamped polar box, got %+v", polar)
+	}
+}
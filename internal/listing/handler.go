@@ -1,6 +1,7 @@
 package listing
 
 import (
+	"errors"
 	"mime/multipart"
 	"net/http"
 	"strconv"
@@ -21,7 +22,7 @@ func NewHandler(service *ListingService) *ListingHandler {
 
 // CreateListing handles creating a new listing
 // @Summary Create a new car listing
-// @Description Create a new car listing with images
+// @Description Create a new car listing with images. Daily posts, active listings and images per listing are limited by the seller's plan (403 with the exceeded quota).
 // @Tags listings
 // @Security BearerAuth
 // @Accept multipart/form-data
@@ -46,6 +47,7 @@ func NewHandler(service *ListingService) *ListingHandler {
 // @Success 201 {object} Car
 // @Failure 400 {object} map[string]string
 // @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]interface{} "Plan quota exceeded"
 // @Router /api/cars [post]
 func (h *ListingHandler) CreateListing(c *gin.Context) {
 	userIDStr := c.GetString("userID")
@@ -77,7 +79,7 @@ func (h *ListingHandler) CreateListing(c *gin.Context) {
 
 	car, err := h.service.CreateListing(c.Request.Context(), userID, req, files)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		writeListingError(c, err)
 		return
 	}
 
@@ -119,6 +121,33 @@ func (h *ListingHandler) GetListing(c *gin.Context) {
 	c.JSON(http.StatusOK, car)
 }
 
+// GetPriceHistory handles getting a listing's price history
+// @Summary Get listing price history
+// @Description Get every price change of a car listing, oldest first, with a summary (original price, lowest price, number of drops, last change)
+// @Tags listings
+// @Accept json
+// @Produce json
+// @Param id path string true "Car ID"
+// @Success 200 {object} PriceHistoryResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/cars/{id}/price-history [get]
+func (h *ListingHandler) GetPriceHistory(c *gin.Context) {
+	carID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid car ID"})
+		return
+	}
+
+	history, err := h.service.GetPriceHistory(c.Request.Context(), carID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Listing not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
 // ListListings handles searching and filtering listings
 // @Summary List car listings
 // @Description Search and filter car listings
@@ -127,6 +156,7 @@ func (h *ListingHandler) GetListing(c *gin.Context) {
 // @Produce json
 // @Param page query int false "Page number"
 // @Param limit query int false "Items per page"
+// @Param q query string false "Free-text search over title, description, make, model and color"
 // @Param make query string false "Make"
 // @Param model query string false "Model"
 // @Param min_price query number false "Min Price"
@@ -134,7 +164,11 @@ func (h *ListingHandler) GetListing(c *gin.Context) {
 // @Param city query string false "City"
 // @Param state query string false "State"
 // @Param condition query string false "Condition"
-// @Param sort_by query string false "Sort By"
+// @Param lat query number false "Latitude of the reference point"
+// @Param lng query number false "Longitude of the reference point"
+// @Param radius_km query number false "Only listings within this distance of lat/lng"
+// @Param bbox query string false "Bounding box: min_lng,min_lat,max_lng,max_lat"
+// @Param sort_by query string false "Sort By (created_at_desc, price_asc, price_desc, year_desc, year_asc, relevance, distance_asc)"
 // @Success 200 {object} map[string]interface{}
 // @Failure 400 {object} map[string]string
 // @Router /api/cars [get]
@@ -144,6 +178,10 @@ func (h *ListingHandler) ListListings(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	if err := ValidateListCarsQuery(query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
 	if query.Limit == 0 {
 		query.Limit = 20
@@ -174,6 +212,43 @@ func (h *ListingHandler) ListListings(c *gin.Context) {
 	})
 }
 
+// GetMapClusters handles map marker clustering
+// @Summary Cluster listings for the map
+// @Description Aggregate active listings inside a bounding box into grid cells sized for the zoom level. From zoom 15 individual listings are returned instead. Accepts the same filters as listing search.
+// @Tags listings
+// @Produce json
+// @Param bbox query string true "Bounding box: min_lng,min_lat,max_lng,max_lat"
+// @Param zoom query int true "Map zoom level (0-22)"
+// @Param q query string false "Free-text search"
+// @Param make query string false "Make"
+// @Param model query string false "Model"
+// @Param min_price query number false "Min Price"
+// @Param max_price query number false "Max Price"
+// @Param city query string false "City"
+// @Param condition query string false "Condition"
+// @Success 200 {object} MapClustersResponse
+// @Failure 400 {object} map[string]string
+// @Router /api/cars/map [get]
+func (h *ListingHandler) GetMapClusters(c *gin.Context) {
+	var query MapClustersQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := ValidateMapClustersQuery(query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := h.service.GetMapClusters(c.Request.Context(), query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
 // UpdateListing handles updating a listing
 // @Summary Update a car listing
 // @Description Update an existing car listing details and images
@@ -189,6 +264,8 @@ func (h *ListingHandler) ListListings(c *gin.Context) {
 // @Success 200 {object} Car
 // @Failure 400 {object} map[string]string
 // @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]interface{} "Plan quota exceeded"
+// @Failure 409 {object} map[string]string "Status changed during the edit"
 // @Router /api/cars/{id} [put]
 func (h *ListingHandler) UpdateListing(c *gin.Context) {
 	idStr := c.Param("id")
@@ -219,7 +296,7 @@ func (h *ListingHandler) UpdateListing(c *gin.Context) {
 
 	car, err := h.service.UpdateListing(c.Request.Context(), carID, userID, req, files)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		writeListingError(c, err)
 		return
 	}
 
@@ -297,6 +374,123 @@ func (h *ListingHandler) GetMyListings(c *gin.Context) {
 	})
 }
 
+// RenewListing handles renewing a listing
+// @Summary Renew a car listing
+// @Description Extend an active listing that expires within 7 days, or re-activate an expired one, for another 90 days. Max 3 renewals per listing.
+// @Tags listings
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Car ID"
+// @Success 200 {object} Car
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]interface{} "Plan quota exceeded"
+// @Router /api/cars/{id}/renew [post]
+func (h *ListingHandler) RenewListing(c *gin.Context) {
+	idStr := c.Param("id")
+	carID, err := uuid.Parse(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid car ID"})
+		return
+	}
+
+	userIDStr := c.GetString("userID")
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	car, err := h.service.RenewListing(c.Request.Context(), carID, userID)
+	if err != nil {
+		writeListingError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, car)
+}
+
+// BoostListing handles boosting a listing
+// @Summary Boost a car listing
+// @Description Feature an active listing for 1-30 days, starting now or at starts_at. Returns a checkout to pay at; the boost is scheduled once payment succeeds and released if it is not paid within an hour. Featured listings are pinned to the top of matching search results. The boost must end before the listing expires, and the plan's featured slots limit how many boosts can overlap.
+// @Tags listings
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Car ID"
+// @Param request body BoostRequest true "Boost length and start"
+// @Success 201 {object} BoostCheckoutResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]interface{} "Plan quota exceeded"
+// @Failure 503 {object} map[string]string "Payments not available"
+// @Router /api/cars/{id}/boost [post]
+func (h *ListingHandler) BoostListing(c *gin.Context) {
+	idStr := c.Param("id")
+	carID, err := uuid.Parse(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid car ID"})
+		return
+	}
+
+	userIDStr := c.GetString("userID")
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var req BoostRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	boost, err := h.service.BoostListing(c.Request.Context(), carID, userID, &req)
+	if err != nil {
+		writeListingError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, boost)
+}
+
+// GetBoosts handles listing a car's boosts
+// @Summary Get a listing's boosts
+// @Description Past, current and scheduled boosts of one of your listings, newest first
+// @Tags listings
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Car ID"
+// @Success 200 {array} Boost
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /api/cars/{id}/boosts [get]
+func (h *ListingHandler) GetBoosts(c *gin.Context) {
+	idStr := c.Param("id")
+	carID, err := uuid.Parse(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid car ID"})
+		return
+	}
+
+	userIDStr := c.GetString("userID")
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	boosts, err := h.service.GetBoosts(c.Request.Context(), carID, userID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, boosts)
+}
+
 // ToggleFavorite handles toggling favorite status
 // @Summary Toggle favorite status
 // @Description Add or remove a car from favorites
@@ -506,3 +700,25 @@ func (h *ListingHandler) UploadImage(c *gin.Context) {
 		"filename": file.Filename,
 	})
 }
+
+// writeListingError responds 403 with the quota details when a plan quota was
+// exceeded, 409 when the listing changed during an edit, 503 when boosts
+// can't be paid for, and 400 otherwise
+func writeListingError(c *gin.Context, err error) {
+	var quotaErr *QuotaExceededError
+	switch {
+	case errors.As(err, &quotaErr):
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": quotaErr.Error(),
+			"quota": quotaErr.Quota,
+			"limit": quotaErr.Limit,
+			"plan":  quotaErr.Plan,
+		})
+	case errors.Is(err, ErrListingChanged):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrPaymentsUnavailable):
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
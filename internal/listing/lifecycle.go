@@ -0,0 +1,182 @@
+package listing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/yourusername/car-reselling-backend/internal/audit"
+)
+
+const (
+	// ListingLifetime is how long a listing stays active after posting or renewal
+	ListingLifetime = 90 * 24 * time.Hour
+
+	// MaxRenewals caps how many times a seller can renew one listing
+	MaxRenewals = 3
+
+	// RenewalWindow is how long before expiry a listing may be renewed
+	RenewalWindow = 7 * 24 * time.Hour
+
+	// lifecycleInterval is how often the lifecycle worker runs
+	lifecycleInterval = 15 * time.Minute
+
+	NotificationTypeListingExpiring = "listing_expiring"
+	NotificationTypeListingExpired  = "listing_expired"
+)
+
+// expiryWarningDays are the "days before expiry" warnings sent to sellers, closest first
+var expiryWarningDays = []int{1, 7}
+
+// RunLifecycleWorker periodically expires overdue listings and warns sellers
+// about upcoming expiry
+func (s *ListingService) RunLifecycleWorker() {
+	ticker := time.NewTicker(lifecycleInterval)
+	defer ticker.Stop()
+
+	for {
+		s.ProcessExpiries(context.Background())
+		<-ticker.C
+	}
+}
+
+// ProcessExpiries runs one lifecycle pass: expiry warnings, then expiry
+func (s *ListingService) ProcessExpiries(ctx context.Context) {
+	now := time.Now()
+
+	// Closest warning first, so a listing inside both windows only gets the 1 day warning
+	for _, days := range expiryWarningDays {
+		cars, err := s.repo.ClaimExpiryWarnings(ctx, days, now)
+		if err != nil {
+			log.Printf("Failed to claim %d-day expiry warnings: %v", days, err)
+			continue
+		}
+		for _, car := range cars {
+			title := "Your listing is expiring soon ⏳"
+			body := fmt.Sprintf("%s expires in %s. Renew it to keep it visible.", car.Title, pluralDays(days))
+			s.notifySeller(ctx, car, title, body, NotificationTypeListingExpiring)
+
+			if s.mailer != nil {
+				if err := s.mailer.SendListingExpiring(ctx, car.SellerID, car.Title, car.ExpiresAt); err != nil {
+					log.Printf("Failed to email expiry warning for car %s: %v", car.ID, err)
+				}
+			}
+		}
+	}
+
+	expired, err := s.repo.ExpireOverdue(ctx, now)
+	if err != nil {
+		log.Printf("Failed to expire overdue listings: %v", err)
+		return
+	}
+	for _, car := range expired {
+		s.cache.Del(ctx, fmt.Sprintf("cache:car:%s", car.ID))
+
+		title := "Your listing has expired"
+		body := fmt.Sprintf("%s is no longer visible to buyers. Renew it to re-activate.", car.Title)
+		s.notifySeller(ctx, car, title, body, NotificationTypeListingExpired)
+	}
+	if len(expired) > 0 {
+		log.Printf("Expired %d listings", len(expired))
+	}
+}
+
+// RenewListing extends an active or expired listing by ListingLifetime and re-activates it
+func (s *ListingService) RenewListing(ctx context.Context, carID, userID uuid.UUID) (*Car, error) {
+	car, err := s.repo.FindByID(ctx, carID)
+	if err != nil {
+		return nil, err
+	}
+
+	if car.SellerID != userID {
+		return nil, errors.New("unauthorized: you do not own this listing")
+	}
+	if car.Status != CarStatusActive && car.Status != CarStatusExpired {
+		return nil, fmt.Errorf("cannot renew a %s listing", car.Status)
+	}
+	if car.RenewalCount >= MaxRenewals {
+		return nil, fmt.Errorf("renewal limit reached: max %d renewals per listing", MaxRenewals)
+	}
+	if car.Status == CarStatusActive && time.Until(car.ExpiresAt) > RenewalWindow {
+		return nil, fmt.Errorf("listing can only be renewed within %s of expiry", pluralDays(int(RenewalWindow.Hours()/24)))
+	}
+	// An expired listing becomes active again
+	if car.Status == CarStatusExpired {
+		if err := s.checkActiveQuota(ctx, userID, s.quotaFor(ctx, userID)); err != nil {
+			return nil, err
+		}
+	}
+
+	expiresAt := time.Now().Add(ListingLifetime)
+	renewed, err := s.repo.Renew(ctx, carID, expiresAt, MaxRenewals)
+	if err != nil {
+		return nil, err
+	}
+	if !renewed {
+		return nil, errors.New("listing could not be renewed")
+	}
+
+	s.cache.Del(ctx, fmt.Sprintf("cache:car:%s", carID))
+
+	wasExpired := car.Status == CarStatusExpired
+	car.Status = CarStatusActive
+	car.ExpiresAt = expiresAt
+	car.RenewalCount++
+
+	s.audit(ctx, audit.ActionListingRenewed, userID, carID, nil, audit.Metadata{
+		"was_expired":   wasExpired,
+		"expires_at":    expiresAt,
+		"renewal_count": car.RenewalCount,
+	})
+
+	// A re-activated listing is new to saved searches again
+	if wasExpired {
+		s.matchListing(car)
+	}
+
+	return car, nil
+}
+
+// notifySeller sends a lifecycle notification about a listing to its seller
+func (s *ListingService) notifySeller(ctx context.Context, car Car, title, body, notifType string) {
+	var carImage string
+	if len(car.Images) > 0 {
+		carImage = car.Images[0]
+	}
+
+	if s.notificationService != nil {
+		data := map[string]interface{}{
+			"car_id":     car.ID.String(),
+			"car_title":  car.Title,
+			"expires_at": car.ExpiresAt,
+		}
+		if _, err := s.notificationService.CreateAndSend(ctx, car.SellerID, title, body, notifType, carImage, data); err != nil {
+			log.Printf("Failed to send %s notification for car %s: %v", notifType, car.ID, err)
+		}
+		return
+	}
+
+	// Fallback to legacy notifier (FCM only)
+	if s.notifier != nil {
+		data := map[string]string{
+			"type":         notifType,
+			"car_id":       car.ID.String(),
+			"car_image":    carImage,
+			"click_action": "FLUTTER_NOTIFICATION_CLICK",
+		}
+		if err := s.notifier.SendToUsers([]uuid.UUID{car.SellerID}, title, body, data); err != nil {
+			log.Printf("Failed to send %s notification for car %s: %v", notifType, car.ID, err)
+		}
+	}
+}
+
+func pluralDays(days int) string {
+	if days == 1 {
+		return "1 day"
+	}
+	return fmt.Sprintf("%d days", days)
+}
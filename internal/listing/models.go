@@ -10,11 +10,12 @@ import (
 
 // Constants for enums
 const (
-	CarStatusActive  = "active"
-	CarStatusSold    = "sold"
-	CarStatusExpired = "expired"
-	CarStatusFlagged = "flagged"
-	CarStatusDeleted = "deleted"
+	CarStatusActive   = "active"
+	CarStatusReserved = "reserved" // An offer was accepted; hidden from search until sold or the offer is cancelled
+	CarStatusSold     = "sold"
+	CarStatusExpired  = "expired"
+	CarStatusFlagged  = "flagged"
+	CarStatusDeleted  = "deleted"
 
 	CarConditionExcellent = "excellent"
 	CarConditionGood      = "good"
@@ -35,37 +36,89 @@ type SellerInfo struct {
 	Name         string    `json:"name" gorm:"-"`
 	ProfilePhoto string    `json:"profile_photo" gorm:"-"`
 	Phone        string    `json:"phone" gorm:"-"`
+
+	// Dealer badge
+	IsDealer       bool   `json:"is_dealer" gorm:"-"`
+	DealerVerified bool   `json:"dealer_verified" gorm:"-"`
+	BusinessName   string `json:"business_name,omitempty" gorm:"-"`
 }
 
 // Car represents the car listing model in the database
 type Car struct {
-	ID           uuid.UUID      `json:"id" db:"id"`
-	SellerID     uuid.UUID      `json:"seller_id" db:"seller_id"`
-	Title        string         `json:"title" db:"title"`
-	Description  string         `json:"description" db:"description"`
-	Make         string         `json:"make" db:"make"`
-	Model        string         `json:"model" db:"model"`
-	Year         int            `json:"year" db:"year"`
-	Mileage      int            `json:"mileage" db:"mileage"`
-	Price        float64        `json:"price" db:"price"`
-	Condition    string         `json:"condition" db:"condition"`
-	Transmission string         `json:"transmission" db:"transmission"`
-	FuelType     string         `json:"fuel_type" db:"fuel_type"`
-	Color        string         `json:"color" db:"color"`
-	VIN          string         `json:"vin" db:"vin"`
-	Images       pq.StringArray `json:"images" gorm:"type:text[]" swaggertype:"array,string"`
-	City         string         `json:"city" gorm:"column:city"`
-	State        string         `json:"state" gorm:"column:state"`
-	Latitude     float64        `json:"latitude" gorm:"-"`  // Computed from PostGIS, not stored
-	Longitude    float64        `json:"longitude" gorm:"-"` // Computed from PostGIS, not stored
-	Status       string         `json:"status" gorm:"column:status"`
-	IsFeatured   bool           `json:"is_featured" gorm:"column:is_featured"`
-	ChatOnly     bool           `json:"chat_only" gorm:"column:chat_only"`
-	ViewsCount   int            `json:"views_count" gorm:"column:views_count"`
-	CreatedAt    time.Time      `json:"created_at" gorm:"column:created_at"`
-	UpdatedAt    time.Time      `json:"updated_at" gorm:"column:updated_at"`
-	ExpiresAt    time.Time      `json:"expires_at" gorm:"column:expires_at"`
+	ID            uuid.UUID      `json:"id" db:"id"`
+	SellerID      uuid.UUID      `json:"seller_id" db:"seller_id"`
+	Title         string         `json:"title" db:"title"`
+	Description   string         `json:"description" db:"description"`
+	Make          string         `json:"make" db:"make"`
+	Model         string         `json:"model" db:"model"`
+	Year          int            `json:"year" db:"year"`
+	Mileage       int            `json:"mileage" db:"mileage"`
+	Price         float64        `json:"price" db:"price"`
+	Condition     string         `json:"condition" db:"condition"`
+	Transmission  string         `json:"transmission" db:"transmission"`
+	FuelType      string         `json:"fuel_type" db:"fuel_type"`
+	Color         string         `json:"color" db:"color"`
+	VIN           string         `json:"vin" db:"vin"`
+	Images        pq.StringArray `json:"images" gorm:"type:text[]" swaggertype:"array,string"`
+	City          string         `json:"city" gorm:"column:city"`
+	State         string         `json:"state" gorm:"column:state"`
+	Latitude      float64        `json:"latitude" gorm:"-"`  // Computed from PostGIS, not stored
+	Longitude     float64        `json:"longitude" gorm:"-"` // Computed from PostGIS, not stored
+	Status        string         `json:"status" gorm:"column:status"`
+	IsFeatured    bool           `json:"is_featured" gorm:"column:is_featured"`
+	FeaturedUntil *time.Time     `json:"featured_until,omitempty" gorm:"column:featured_until"` // End of the current boost
+	ChatOnly      bool           `json:"chat_only" gorm:"column:chat_only"`
+	ViewsCount    int            `json:"views_count" gorm:"column:views_count"`
+	CreatedAt     time.Time      `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt     time.Time      `json:"updated_at" gorm:"column:updated_at"`
+	ExpiresAt     time.Time      `json:"expires_at" gorm:"column:expires_at"`
+	RenewalCount  int            `json:"renewal_count" gorm:"column:renewal_count"`
 
 	// Joins/Extras - populated via JOIN queries, not stored in cars table
-	Seller *SellerInfo `json:"seller,omitempty" gorm:"-"`
+	Seller     *SellerInfo `json:"seller,omitempty" gorm:"-"`
+	DistanceKm *float64    `json:"distance_km,omitempty" gorm:"-"` // Set when searching around a point
+	Pinned     bool        `json:"pinned,omitempty" gorm:"-"`      // Featured listing pinned to the top of search results
+}
+
+// Boost statuses
+const (
+	BoostStatusPending   = "pending"   // Created, awaiting payment at checkout
+	BoostStatusScheduled = "scheduled" // Paid, window not started
+	BoostStatusActive    = "active"    // Listing is featured
+	BoostStatusEnded     = "ended"
+	BoostStatusFailed    = "failed" // Payment failed or checkout expired
+)
+
+// Boost is a paid window during which a listing is featured
+type Boost struct {
+	ID               uuid.UUID `json:"id" gorm:"column:id"`
+	CarID            uuid.UUID `json:"car_id" gorm:"column:car_id"`
+	SellerID         uuid.UUID `json:"seller_id" gorm:"column:seller_id"`
+	StartsAt         time.Time `json:"starts_at" gorm:"column:starts_at"`
+	EndsAt           time.Time `json:"ends_at" gorm:"column:ends_at"`
+	Amount           float64   `json:"amount" gorm:"column:amount"`
+	Currency         string    `json:"currency" gorm:"column:currency"`
+	PaymentReference string    `json:"payment_reference" gorm:"column:payment_reference"`
+	Status           string    `json:"status" gorm:"column:status"`
+	CreatedAt        time.Time `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt        time.Time `json:"updated_at" gorm:"column:updated_at"`
+}
+
+// TableName overrides the default table name
+func (Boost) TableName() string {
+	return "listing_boosts"
+}
+
+// PriceChange is one entry in a listing's price history
+type PriceChange struct {
+	ID        uuid.UUID `json:"id" gorm:"column:id"`
+	CarID     uuid.UUID `json:"car_id" gorm:"column:car_id"`
+	OldPrice  *float64  `json:"old_price" gorm:"column:old_price"` // nil for the original asking price
+	NewPrice  float64   `json:"new_price" gorm:"column:new_price"`
+	ChangedAt time.Time `json:"changed_at" gorm:"column:changed_at"`
+}
+
+// TableName overrides the default table name
+func (PriceChange) TableName() string {
+	return "car_price_history"
 }
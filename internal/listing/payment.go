@@ -0,0 +1,37 @@
+package listing
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+
+	"github.com/yourusername/car-reselling-backend/internal/payments"
+)
+
+// ErrPaymentsUnavailable is returned when boosting without a payment provider
+var ErrPaymentsUnavailable = errors.New("payments are not available")
+
+// PaymentService takes payments for boosts through a hosted checkout. The
+// boost is scheduled when the payment's webhook arrives (see PaymentSucceeded).
+type PaymentService interface {
+	CreateCheckout(ctx context.Context, userID uuid.UUID, opts payments.CheckoutOptions) (*payments.CheckoutResponse, error)
+}
+
+// BoostPricing is what a boost costs
+type BoostPricing struct {
+	DailyPrice float64
+	Currency   string
+}
+
+// Price returns the cost of a boost of the given length in days
+func (p BoostPricing) Price(days int) float64 {
+	return p.DailyPrice * float64(days)
+}
+
+// SetPaymentService sets how boosts are paid for and what they cost. Without
+// one, boosting fails with ErrPaymentsUnavailable.
+func (s *ListingService) SetPaymentService(p PaymentService, pricing BoostPricing) {
+	s.payments = p
+	s.boostPricing = pricing
+}
@@ -0,0 +1,110 @@
+package listing
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/google/uuid"
+)
+
+// Quotas limited by a seller's plan
+const (
+	QuotaDailyPosts       = "daily_posts"
+	QuotaActiveListings   = "active_listings"
+	QuotaImagesPerListing = "images_per_listing"
+	QuotaFeaturedSlots    = "featured_slots"
+)
+
+// ListingQuota is what a seller's plan allows
+type ListingQuota struct {
+	Plan             string `json:"plan"`
+	DailyPosts       int    `json:"daily_posts"`
+	ActiveListings   int    `json:"active_listings"`
+	ImagesPerListing int    `json:"images_per_listing"`
+	FeaturedSlots    int    `json:"featured_slots"`
+}
+
+// DefaultQuota applies when no QuotaProvider is set or it fails
+var DefaultQuota = ListingQuota{
+	Plan:             "free",
+	DailyPosts:       5,
+	ActiveListings:   20,
+	ImagesPerListing: 10,
+	FeaturedSlots:    0,
+}
+
+// QuotaProvider looks up the quota of a seller's current plan
+type QuotaProvider interface {
+	QuotaFor(ctx context.Context, userID uuid.UUID) (*ListingQuota, error)
+}
+
+// QuotaExceededError is returned when an action would go over a plan quota
+type QuotaExceededError struct {
+	Quota string // One of the Quota* constants
+	Limit int
+	Plan  string
+}
+
+func (e *QuotaExceededError) Error() string {
+	switch e.Quota {
+	case QuotaDailyPosts:
+		return fmt.Sprintf("daily post limit reached: your %s plan allows %d new listings per day", e.Plan, e.Limit)
+	case QuotaActiveListings:
+		return fmt.Sprintf("active listing limit reached: your %s plan allows %d active listings at a time", e.Plan, e.Limit)
+	case QuotaImagesPerListing:
+		return fmt.Sprintf("image limit exceeded: your %s plan allows %d images per listing", e.Plan, e.Limit)
+	case QuotaFeaturedSlots:
+		return fmt.Sprintf("featured slot limit reached: your %s plan allows %d featured listings at a time", e.Plan, e.Limit)
+	}
+	return fmt.Sprintf("%s quota of your %s plan exceeded (limit %d)", e.Quota, e.Plan, e.Limit)
+}
+
+// SetQuotaProvider sets the source of seller plan quotas
+func (s *ListingService) SetQuotaProvider(p QuotaProvider) {
+	s.quotas = p
+}
+
+// quotaFor returns the seller's quota, falling back to DefaultQuota
+func (s *ListingService) quotaFor(ctx context.Context, userID uuid.UUID) ListingQuota {
+	if s.quotas != nil {
+		quota, err := s.quotas.QuotaFor(ctx, userID)
+		if err == nil {
+			return *quota
+		}
+		log.Printf("Failed to look up quota of user %s, using default: %v", userID, err)
+	}
+	return DefaultQuota
+}
+
+// checkPostingQuota checks the daily post and active listing quotas before a new listing
+func (s *ListingService) checkPostingQuota(ctx context.Context, userID uuid.UUID, quota ListingQuota) error {
+	posts, err := s.repo.CountDailyPosts(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if posts >= int64(quota.DailyPosts) {
+		return &QuotaExceededError{Quota: QuotaDailyPosts, Limit: quota.DailyPosts, Plan: quota.Plan}
+	}
+	return s.checkActiveQuota(ctx, userID, quota)
+}
+
+// checkActiveQuota checks that one more listing may become active
+func (s *ListingService) checkActiveQuota(ctx context.Context, userID uuid.UUID, quota ListingQuota) error {
+	active, err := s.repo.CountActiveListings(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if active >= int64(quota.ActiveListings) {
+		return &QuotaExceededError{Quota: QuotaActiveListings, Limit: quota.ActiveListings, Plan: quota.Plan}
+	}
+	return nil
+}
+
+// checkImageQuota checks the number of images a listing would have
+func checkImageQuota(images int, quota ListingQuota) error {
+	if images > quota.ImagesPerListing {
+		return &QuotaExceededError{Quota: QuotaImagesPerListing, Limit: quota.ImagesPerListing, Plan: quota.Plan}
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+package listing
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCheckImageQuota(t *testing.T) {
+	quota := ListingQuota{Plan: "free", ImagesPerListing: 10}
+
+	if err := checkImageQuota(10, quota); err != nil {
+		t.Fatalf("10 images should be allowed, got %v", err)
+	}
+
+	err := checkImageQuota(11, quota)
+	var quotaErr *QuotaExceededError
+	if !errors.As(err, &quotaErr) {
+		t.Fatalf("expected QuotaExceededError, got %v", err)
+	}
+	if quotaErr.Quota != QuotaImagesPerListing || quotaErr.Limit != 10 {
+		t.Errorf("unexpected quota error: %+v", quotaErr)
+	}
+	if !strings.Contains(err.Error(), "free plan allows 10 images") {
+		t.Errorf("error should name the plan and limit, got %q", err.Error())
+	}
+}
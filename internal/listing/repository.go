@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -14,11 +15,32 @@ type ListingRepository interface {
 	Create(ctx context.Context, car *Car) error
 	FindByID(ctx context.Context, id uuid.UUID) (*Car, error)
 	FindAll(ctx context.Context, query ListCarsQuery) ([]Car, int64, error)
-	Update(ctx context.Context, car *Car) error
+	Update(ctx context.Context, car *Car, oldStatus string) error
 	Delete(ctx context.Context, id uuid.UUID) error
 	FindBySellerID(ctx context.Context, sellerID uuid.UUID, page, limit int) ([]Car, int64, error)
 	IncrementViews(ctx context.Context, carID uuid.UUID) error
 
+	// Price history
+	FindPriceHistory(ctx context.Context, carID uuid.UUID) ([]PriceChange, error)
+	FindPriceSummaries(ctx context.Context, carIDs []uuid.UUID) (map[uuid.UUID]PriceSummary, error)
+
+	// Lifecycle
+	ExpireOverdue(ctx context.Context, now time.Time) ([]Car, error)
+	ClaimExpiryWarnings(ctx context.Context, days int, now time.Time) ([]Car, error)
+	Renew(ctx context.Context, carID uuid.UUID, expiresAt time.Time, maxRenewals int) (bool, error)
+
+	// Boosts
+	CreateBoost(ctx context.Context, boost *Boost, featuredSlots int) error
+	SetBoostPayment(ctx context.Context, boostID uuid.UUID, status, reference string) (*Boost, error)
+	FindBoost(ctx context.Context, boostID uuid.UUID) (*Boost, error)
+	FindBoosts(ctx context.Context, carID uuid.UUID) ([]Boost, error)
+	ActivateDueBoosts(ctx context.Context, now time.Time) ([]uuid.UUID, error)
+	EndExpiredBoosts(ctx context.Context, now time.Time) ([]uuid.UUID, error)
+
+	// Map
+	FindClusters(ctx context.Context, query ListCarsQuery, cellSize float64) ([]MapCluster, error)
+	FindMarkers(ctx context.Context, query ListCarsQuery, limit int) ([]MapMarker, error)
+
 	// Favorites
 	AddToFavorites(ctx context.Context, userID, carID uuid.UUID) error
 	RemoveFromFavorites(ctx context.Context, userID, carID uuid.UUID) error
@@ -28,6 +50,7 @@ type ListingRepository interface {
 
 	// Limits
 	CountDailyPosts(ctx context.Context, userID uuid.UUID) (int64, error)
+	CountActiveListings(ctx context.Context, userID uuid.UUID) (int64, error)
 }
 
 type postgresRepository struct {
@@ -58,33 +81,49 @@ func (r *postgresRepository) Create(ctx context.Context, car *Car) error {
 			$20::car_status, $21, $22, $23, $24, $25, $26
 		)
 	`
-	return r.db.WithContext(ctx).Exec(query,
-		car.ID, car.SellerID, car.Title, car.Description,
-		car.Make, car.Model, car.Year, car.Mileage, car.Price,
-		car.Condition, car.Transmission, car.FuelType, car.Color, car.VIN,
-		car.Images, car.City, car.State,
-		car.Latitude, car.Longitude,
-		car.Status, car.IsFeatured, car.ViewsCount,
-		car.CreatedAt, car.UpdatedAt, car.ExpiresAt, car.ChatOnly,
-	).Error
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec(query,
+			car.ID, car.SellerID, car.Title, car.Description,
+			car.Make, car.Model, car.Year, car.Mileage, car.Price,
+			car.Condition, car.Transmission, car.FuelType, car.Color, car.VIN,
+			car.Images, car.City, car.State,
+			car.Latitude, car.Longitude,
+			car.Status, car.IsFeatured, car.ViewsCount,
+			car.CreatedAt, car.UpdatedAt, car.ExpiresAt, car.ChatOnly,
+		).Error
+		if err != nil {
+			return err
+		}
+
+		// Original asking price starts the price history
+		return tx.Exec(
+			"INSERT INTO car_price_history (car_id, old_price, new_price, changed_at) VALUES (?, NULL, ?, ?)",
+			car.ID, car.Price, car.CreatedAt,
+		).Error
+	})
 }
 
 func (r *postgresRepository) FindByID(ctx context.Context, id uuid.UUID) (*Car, error) {
 	var result struct {
 		Car
-		SellerName   string  `gorm:"column:seller_name"`
-		SellerPhoto  string  `gorm:"column:seller_photo"`
-		SellerPhone  string  `gorm:"column:seller_phone"`
-		SellerRating float64 `gorm:"column:seller_rating"`
+		SellerName           string  `gorm:"column:seller_name"`
+		SellerPhoto          string  `gorm:"column:seller_photo"`
+		SellerPhone          string  `gorm:"column:seller_phone"`
+		SellerRating         float64 `gorm:"column:seller_rating"`
+		SellerIsDealer       bool    `gorm:"column:seller_is_dealer"`
+		SellerDealerVerified bool    `gorm:"column:seller_dealer_verified"`
+		SellerBusinessName   *string `gorm:"column:seller_business_name"`
 	}
 
 	query := `
 		SELECT c.*,
 			   u.full_name as seller_name,
 			   u.profile_photo_url as seller_photo,
-			   u.phone as seller_phone
+			   u.phone as seller_phone,
+			   ` + sellerDealerColumns + `
 		FROM cars c
 		LEFT JOIN users u ON c.seller_id = u.id
+		LEFT JOIN dealer_profiles dp ON dp.user_id = c.seller_id
 		WHERE c.id = ? AND c.status != 'deleted'
 	`
 	err := r.db.WithContext(ctx).Raw(query, id.String()).Scan(&result).Error
@@ -101,58 +140,158 @@ func (r *postgresRepository) FindByID(ctx context.Context, id uuid.UUID) (*Car,
 		ProfilePhoto: result.SellerPhoto,
 		Phone:        result.SellerPhone,
 	}
+	setDealerBadge(result.Car.Seller, result.SellerIsDealer, result.SellerDealerVerified, result.SellerBusinessName)
 
 	return &result.Car, nil
 }
 
 func (r *postgresRepository) FindAll(ctx context.Context, q ListCarsQuery) ([]Car, int64, error) {
-	var cars []Car
-	var total int64
+	cars, total, err := r.findAll(ctx, q, false)
+	if err != nil || total > 0 || q.Query == "" {
+		return cars, total, err
+	}
 
-	// Build base query - Note: seller_id is TEXT, users.id is UUID
-	baseQuery := `
-		FROM cars c
-		JOIN users u ON c.seller_id = u.id
-		WHERE c.status = 'active'
-	`
-	var args []interface{}
-	var conditions []string
+	// Nothing matched the full-text search, retry with typo-tolerant trigram matching
+	return r.findAll(ctx, q, true)
+}
+
+// sellerDealerColumns selects the dealer badge of a listing's seller. Queries
+// using it join users as u and dealer_profiles as dp.
+const sellerDealerColumns = `COALESCE(u.role = 'dealer', FALSE) as seller_is_dealer,
+			   COALESCE(u.role = 'dealer' AND dp.verification_status = 'verified', FALSE) as seller_dealer_verified,
+			   CASE WHEN u.role = 'dealer' THEN dp.business_name ELSE '' END as seller_business_name`
+
+// featuredNow is true for listings inside a boost window
+const featuredNow = "(c.is_featured AND c.featured_until > NOW())"
+
+// pinnedColumn pins up to FeaturedPerPage featured listings matching a query
+// to the top of the results. Which ones rotates every hour so all boosted
+// listings get a turn.
+var pinnedColumn = fmt.Sprintf(`CASE WHEN %[1]s AND ROW_NUMBER() OVER (
+				PARTITION BY %[1]s ORDER BY md5(c.id::text || to_char(NOW(), 'YYYYMMDDHH24'))
+			   ) <= %[2]d THEN TRUE ELSE FALSE END`, featuredNow, FeaturedPerPage)
+
+// setDealerBadge copies the columns of sellerDealerColumns to the seller
+func setDealerBadge(seller *SellerInfo, isDealer, verified bool, businessName *string) {
+	seller.IsDealer = isDealer
+	seller.DealerVerified = verified
+	if businessName != nil {
+		seller.BusinessName = *businessName
+	}
+}
+
+// listFilter holds the SQL fragments shared by listing queries
+type listFilter struct {
+	conditions []string
+	args       []interface{}
+
+	// Relevance expression, set when a search term is present
+	rank     string
+	rankArgs []interface{}
+
+	// Distance expression in km, set when a reference point is present
+	distance     string
+	distanceArgs []interface{}
+}
+
+// where returns the combined conditions, prefixed with " AND " (empty when there are none)
+func (f listFilter) where() string {
+	if len(f.conditions) == 0 {
+		return ""
+	}
+	return " AND " + strings.Join(f.conditions, " AND ")
+}
+
+// listFilters builds the WHERE conditions shared by listing queries, plus the
+// relevance and distance expressions when a search term or reference point is present
+func listFilters(q ListCarsQuery, fuzzy bool) listFilter {
+	var f listFilter
 
 	if q.Make != "" {
-		conditions = append(conditions, "c.make = ?")
-		args = append(args, q.Make)
+		f.conditions = append(f.conditions, "c.make = ?")
+		f.args = append(f.args, q.Make)
 	}
 	if q.Model != "" {
-		conditions = append(conditions, "c.model = ?")
-		args = append(args, q.Model)
+		f.conditions = append(f.conditions, "c.model = ?")
+		f.args = append(f.args, q.Model)
 	}
 	if q.MinPrice > 0 {
-		conditions = append(conditions, "c.price >= ?")
-		args = append(args, q.MinPrice)
+		f.conditions = append(f.conditions, "c.price >= ?")
+		f.args = append(f.args, q.MinPrice)
 	}
 	if q.MaxPrice > 0 {
-		conditions = append(conditions, "c.price <= ?")
-		args = append(args, q.MaxPrice)
+		f.conditions = append(f.conditions, "c.price <= ?")
+		f.args = append(f.args, q.MaxPrice)
 	}
 	if q.City != "" {
-		conditions = append(conditions, "c.city = ?")
-		args = append(args, q.City)
+		f.conditions = append(f.conditions, "c.city = ?")
+		f.args = append(f.args, q.City)
 	}
 	if q.Condition != "" {
-		conditions = append(conditions, "c.condition = ?")
-		args = append(args, q.Condition)
+		f.conditions = append(f.conditions, "c.condition = ?")
+		f.args = append(f.args, q.Condition)
+	}
+	if q.Query != "" {
+		cond, searchArgs, rank, rankArgs := searchFilter(q.Query, fuzzy)
+		if cond != "" {
+			f.conditions = append(f.conditions, cond)
+			f.args = append(f.args, searchArgs...)
+			f.rank, f.rankArgs = rank, rankArgs
+		}
 	}
 
-	if len(conditions) > 0 {
-		baseQuery += " AND " + strings.Join(conditions, " AND ")
+	// Geo filters
+	if q.BBox != "" {
+		if box, err := ParseBoundingBox(q.BBox); err == nil {
+			f.conditions = append(f.conditions, "c.latitude BETWEEN ? AND ? AND c.longitude BETWEEN ? AND ?")
+			f.args = append(f.args, box.MinLat, box.MaxLat, box.MinLng, box.MaxLng)
+		}
+	}
+	if q.Lat != nil && q.Lng != nil {
+		f.distance, f.distanceArgs = distanceSQL(*q.Lat, *q.Lng)
+		if q.RadiusKm > 0 {
+			// Cheap bounding box prefilter (uses idx_cars_location), then the exact distance
+			box := radiusBounds(*q.Lat, *q.Lng, q.RadiusKm)
+			f.conditions = append(f.conditions, "c.latitude BETWEEN ? AND ? AND c.longitude BETWEEN ? AND ?")
+			f.args = append(f.args, box.MinLat, box.MaxLat, box.MinLng, box.MaxLng)
+			f.conditions = append(f.conditions, f.distance+" <= ?")
+			f.args = append(f.args, f.distanceArgs...)
+			f.args = append(f.args, q.RadiusKm)
+		}
 	}
 
+	return f
+}
+
+func (r *postgresRepository) findAll(ctx context.Context, q ListCarsQuery, fuzzy bool) ([]Car, int64, error) {
+	var cars []Car
+	var total int64
+
+	// Build base query - Note: seller_id is TEXT, users.id is UUID
+	baseQuery := `
+		FROM cars c
+		JOIN users u ON c.seller_id = u.id
+		LEFT JOIN dealer_profiles dp ON dp.user_id = c.seller_id
+		WHERE c.status = 'active'
+	`
+	filter := listFilters(q, fuzzy)
+	baseQuery += filter.where()
+
 	// Count total
 	countQuery := "SELECT count(*) " + baseQuery
-	if err := r.db.WithContext(ctx).Raw(countQuery, args...).Scan(&total).Error; err != nil {
+	if err := r.db.WithContext(ctx).Raw(countQuery, filter.args...).Scan(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
+	// Distance column (NULL when no reference point is given)
+	var args []interface{}
+	distanceColumn := "NULL::double precision"
+	if filter.distance != "" {
+		distanceColumn = filter.distance
+		args = append(args, filter.distanceArgs...)
+	}
+	args = append(args, filter.args...)
+
 	// Sorting
 	order := "c.created_at DESC"
 	switch q.SortBy {
@@ -164,6 +303,15 @@ func (r *postgresRepository) FindAll(ctx context.Context, q ListCarsQuery) ([]Ca
 		order = "c.year ASC"
 	case "year_desc":
 		order = "c.year DESC"
+	case SortByRelevance:
+		if filter.rank != "" {
+			order = filter.rank + " DESC, c.created_at DESC"
+			args = append(args, filter.rankArgs...)
+		}
+	case SortByDistance:
+		if filter.distance != "" {
+			order = "distance_km ASC NULLS LAST"
+		}
 	}
 
 	// Pagination
@@ -172,19 +320,31 @@ func (r *postgresRepository) FindAll(ctx context.Context, q ListCarsQuery) ([]Ca
 	// Final Select - Extract lat/long from coordinates
 	selectQuery := `
 		SELECT c.*,
+			   c.latitude as lat,
+			   c.longitude as lng,
+			   ` + distanceColumn + ` as distance_km,
+			   ` + pinnedColumn + ` as pinned,
 			   u.full_name as seller_name,
 			   u.profile_photo_url as seller_photo,
-			   u.phone as seller_phone
-	` + baseQuery + fmt.Sprintf(" ORDER BY %s, c.id DESC LIMIT ? OFFSET ?", order)
+			   u.phone as seller_phone,
+			   ` + sellerDealerColumns + `
+	` + baseQuery + fmt.Sprintf(" ORDER BY pinned DESC, %s, c.id DESC LIMIT ? OFFSET ?", order)
 
 	args = append(args, q.Limit, offset)
 
 	// Use anonymous struct slice to scan
 	var results []struct {
 		Car
-		SellerName  string `gorm:"column:seller_name"`
-		SellerPhoto string `gorm:"column:seller_photo"`
-		SellerPhone string `gorm:"column:seller_phone"`
+		Lat                  *float64 `gorm:"column:lat"`
+		Lng                  *float64 `gorm:"column:lng"`
+		DistanceKm           *float64 `gorm:"column:distance_km"`
+		Pinned               bool     `gorm:"column:pinned"`
+		SellerName           string   `gorm:"column:seller_name"`
+		SellerPhoto          string   `gorm:"column:seller_photo"`
+		SellerPhone          string   `gorm:"column:seller_phone"`
+		SellerIsDealer       bool     `gorm:"column:seller_is_dealer"`
+		SellerDealerVerified bool     `gorm:"column:seller_dealer_verified"`
+		SellerBusinessName   *string  `gorm:"column:seller_business_name"`
 	}
 
 	if err := r.db.WithContext(ctx).Raw(selectQuery, args...).Scan(&results).Error; err != nil {
@@ -195,19 +355,151 @@ func (r *postgresRepository) FindAll(ctx context.Context, q ListCarsQuery) ([]Ca
 	cars = make([]Car, len(results))
 	for i, res := range results {
 		cars[i] = res.Car
+		if res.Lat != nil && res.Lng != nil {
+			cars[i].Latitude = *res.Lat
+			cars[i].Longitude = *res.Lng
+		}
+		cars[i].DistanceKm = res.DistanceKm
+		cars[i].Pinned = res.Pinned
 		cars[i].Seller = &SellerInfo{
 			ID:           res.Car.SellerID,
 			Name:         res.SellerName,
 			ProfilePhoto: res.SellerPhoto,
 			Phone:        res.SellerPhone,
 		}
+		setDealerBadge(cars[i].Seller, res.SellerIsDealer, res.SellerDealerVerified, res.SellerBusinessName)
 	}
 
 	return cars, total, nil
 }
 
-func (r *postgresRepository) Update(ctx context.Context, car *Car) error {
-	return r.db.WithContext(ctx).Save(car).Error
+func (r *postgresRepository) FindClusters(ctx context.Context, q ListCarsQuery, cellSize float64) ([]MapCluster, error) {
+	filter := listFilters(q, false)
+
+	query := `
+		SELECT COUNT(*) as count,
+			   AVG(c.latitude) as latitude,
+			   AVG(c.longitude) as longitude,
+			   MIN(c.price) as min_price,
+			   MAX(c.price) as max_price,
+			   CASE WHEN COUNT(*) = 1 THEN (array_agg(c.id))[1] END as car_id
+		FROM cars c
+		JOIN users u ON c.seller_id = u.id
+		WHERE c.status = 'active'
+		  AND c.latitude IS NOT NULL AND c.longitude IS NOT NULL
+	` + filter.where() + `
+		GROUP BY FLOOR(c.longitude / ?), FLOOR(c.latitude / ?)
+		ORDER BY count DESC
+		LIMIT ?
+	`
+	args := append(filter.args, cellSize, cellSize, maxMapClusters)
+
+	var clusters []MapCluster
+	err := r.db.WithContext(ctx).Raw(query, args...).Scan(&clusters).Error
+	return clusters, err
+}
+
+func (r *postgresRepository) FindMarkers(ctx context.Context, q ListCarsQuery, limit int) ([]MapMarker, error) {
+	filter := listFilters(q, false)
+
+	query := `
+		SELECT c.id, c.title, c.price, c.latitude, c.longitude,
+			   c.images[1] as image_url
+		FROM cars c
+		JOIN users u ON c.seller_id = u.id
+		WHERE c.status = 'active'
+		  AND c.latitude IS NOT NULL AND c.longitude IS NOT NULL
+	` + filter.where() + `
+		ORDER BY c.created_at DESC, c.id DESC
+		LIMIT ?
+	`
+	args := append(filter.args, limit)
+
+	var markers []MapMarker
+	err := r.db.WithContext(ctx).Raw(query, args...).Scan(&markers).Error
+	return markers, err
+}
+
+// editableColumns are the columns a seller changes through UpdateListing.
+// Featuring, expiry, renewals and views belong to workers and other flows, so
+// an edit never writes back the values it read.
+var editableColumns = []string{
+	"title", "description", "make", "model", "year", "mileage", "price", "condition",
+	"transmission", "fuel_type", "color", "images", "city", "state", "updated_at",
+}
+
+// Update saves a seller's edit. The status is only written when the edit
+// changed it, and only while it is still oldStatus: if moderation or an
+// accepted offer moved the listing meanwhile, ErrListingChanged is returned.
+func (r *postgresRepository) Update(ctx context.Context, car *Car, oldStatus string) error {
+	columns := editableColumns
+	statusChanged := car.Status != oldStatus
+	if statusChanged {
+		columns = append(append([]string{}, editableColumns...), "status")
+	}
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Record a price change against the stored price before overwriting it
+		err := tx.Exec(`
+			INSERT INTO car_price_history (car_id, old_price, new_price, changed_at)
+			SELECT id, price, ?, ? FROM cars WHERE id = ? AND price <> ?
+		`, car.Price, car.UpdatedAt, car.ID, car.Price).Error
+		if err != nil {
+			return err
+		}
+		query := tx.Model(car).Select(columns)
+		if statusChanged {
+			query = query.Where("status = ?", oldStatus)
+		}
+		res := query.Updates(car)
+		if res.Error != nil {
+			return res.Error
+		}
+		if statusChanged && res.RowsAffected == 0 {
+			return ErrListingChanged
+		}
+		return nil
+	})
+}
+
+func (r *postgresRepository) FindPriceHistory(ctx context.Context, carID uuid.UUID) ([]PriceChange, error) {
+	var history []PriceChange
+	err := r.db.WithContext(ctx).
+		Where("car_id = ?", carID.String()).
+		Order("changed_at ASC").
+		Find(&history).Error
+	return history, err
+}
+
+func (r *postgresRepository) FindPriceSummaries(ctx context.Context, carIDs []uuid.UUID) (map[uuid.UUID]PriceSummary, error) {
+	summaries := make(map[uuid.UUID]PriceSummary, len(carIDs))
+	if len(carIDs) == 0 {
+		return summaries, nil
+	}
+
+	var rows []struct {
+		CarID uuid.UUID `gorm:"column:car_id"`
+		PriceSummary
+	}
+
+	query := `
+		SELECT car_id,
+			   (ARRAY_AGG(COALESCE(old_price, new_price) ORDER BY changed_at ASC))[1] as original_price,
+			   LEAST(MIN(new_price), MIN(old_price)) as lowest_price,
+			   COUNT(*) FILTER (WHERE new_price < old_price) as price_drops,
+			   MAX(changed_at) FILTER (WHERE old_price IS NOT NULL) as last_changed_at
+		FROM car_price_history
+		WHERE car_id IN ?
+		GROUP BY car_id
+	`
+	if err := r.db.WithContext(ctx).Raw(query, carIDs).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		summaries[row.CarID] = row.PriceSummary
+	}
+	return summaries, nil
 }
 
 func (r *postgresRepository) Delete(ctx context.Context, id uuid.UUID) error {
@@ -215,6 +507,160 @@ func (r *postgresRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	return r.db.WithContext(ctx).Exec("UPDATE cars SET status = 'deleted' WHERE id = ?", id.String()).Error
 }
 
+func (r *postgresRepository) ExpireOverdue(ctx context.Context, now time.Time) ([]Car, error) {
+	var cars []Car
+	query := `
+		UPDATE cars SET status = 'expired', updated_at = ?
+		WHERE status = 'active' AND expires_at <= ?
+		RETURNING id, seller_id, title, images, expires_at
+	`
+	err := r.db.WithContext(ctx).Raw(query, now, now).Scan(&cars).Error
+	return cars, err
+}
+
+func (r *postgresRepository) ClaimExpiryWarnings(ctx context.Context, days int, now time.Time) ([]Car, error) {
+	// Mark and return active listings expiring within `days` that haven't had this
+	// (or a closer) warning yet, so each warning is sent once even with several replicas
+	var cars []Car
+	query := `
+		UPDATE cars SET expiry_warning_days = ?
+		WHERE status = 'active'
+		  AND expires_at > ? AND expires_at <= ?
+		  AND (expiry_warning_days IS NULL OR expiry_warning_days > ?)
+		RETURNING id, seller_id, title, images, expires_at
+	`
+	err := r.db.WithContext(ctx).Raw(query, days, now, now.AddDate(0, 0, days), days).Scan(&cars).Error
+	return cars, err
+}
+
+func (r *postgresRepository) Renew(ctx context.Context, carID uuid.UUID, expiresAt time.Time, maxRenewals int) (bool, error) {
+	result := r.db.WithContext(ctx).Exec(`
+		UPDATE cars
+		SET status = 'active', expires_at = ?, renewal_count = renewal_count + 1,
+			expiry_warning_days = NULL, updated_at = NOW()
+		WHERE id = ? AND status IN ('active', 'expired') AND renewal_count < ?
+	`, expiresAt, carID.String(), maxRenewals)
+	return result.RowsAffected > 0, result.Error
+}
+
+// CreateBoost stores a pending boost unless the car is already boosted during
+// its window or the seller's overlapping boosts fill their featured slots.
+// Boosts of one seller are created one at a time so concurrent requests can't
+// both pass the checks.
+func (r *postgresRepository) CreateBoost(ctx context.Context, boost *Boost, featuredSlots int) error {
+	open := []string{BoostStatusPending, BoostStatusScheduled, BoostStatusActive}
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext('boosts:' || ?))", boost.SellerID.String()).Error; err != nil {
+			return err
+		}
+
+		var overlapping int64
+		if err := tx.Model(&Boost{}).
+			Where("car_id = ? AND status IN ? AND starts_at < ? AND ends_at > ?", boost.CarID, open, boost.EndsAt, boost.StartsAt).
+			Count(&overlapping).Error; err != nil {
+			return err
+		}
+		if overlapping > 0 {
+			return ErrBoostOverlap
+		}
+
+		var seller int64
+		if err := tx.Model(&Boost{}).
+			Where("seller_id = ? AND status IN ? AND starts_at < ? AND ends_at > ?", boost.SellerID, open, boost.EndsAt, boost.StartsAt).
+			Count(&seller).Error; err != nil {
+			return err
+		}
+		if seller >= int64(featuredSlots) {
+			return errFeaturedSlotsFull
+		}
+
+		return tx.Create(boost).Error
+	})
+}
+
+// SetBoostPayment records the payment of a boost that is awaiting it and
+// moves it to status. It returns nil if the boost was no longer pending.
+func (r *postgresRepository) SetBoostPayment(ctx context.Context, boostID uuid.UUID, status, reference string) (*Boost, error) {
+	var boosts []Boost
+	err := r.db.WithContext(ctx).Raw(`
+		UPDATE listing_boosts SET status = ?, payment_reference = ?, updated_at = NOW()
+		WHERE id = ? AND status = ?
+		RETURNING *
+	`, status, reference, boostID, BoostStatusPending).Scan(&boosts).Error
+	if err != nil || len(boosts) == 0 {
+		return nil, err
+	}
+	return &boosts[0], nil
+}
+
+func (r *postgresRepository) FindBoost(ctx context.Context, boostID uuid.UUID) (*Boost, error) {
+	var boost Boost
+	if err := r.db.WithContext(ctx).Where("id = ?", boostID).First(&boost).Error; err != nil {
+		return nil, err
+	}
+	return &boost, nil
+}
+
+func (r *postgresRepository) FindBoosts(ctx context.Context, carID uuid.UUID) ([]Boost, error) {
+	var boosts []Boost
+	err := r.db.WithContext(ctx).
+		Where("car_id = ?", carID).
+		Order("starts_at DESC").
+		Find(&boosts).Error
+	return boosts, err
+}
+
+func (r *postgresRepository) ActivateDueBoosts(ctx context.Context, now time.Time) ([]uuid.UUID, error) {
+	// Start paid boosts whose window has begun and feature their cars until the
+	// latest end, so back-to-back boosts don't drop the flag in between
+	query := `
+		WITH due AS (
+			UPDATE listing_boosts SET status = 'active', updated_at = ?
+			WHERE status = 'scheduled' AND starts_at <= ? AND ends_at > ?
+			RETURNING car_id, ends_at
+		), windows AS (
+			SELECT car_id, MAX(ends_at) as ends_at FROM due GROUP BY car_id
+		)
+		UPDATE cars c
+		SET is_featured = TRUE, featured_until = GREATEST(COALESCE(c.featured_until, w.ends_at), w.ends_at)
+		FROM windows w
+		WHERE c.id = w.car_id
+		RETURNING c.id
+	`
+	return r.scanIDs(ctx, query, now, now, now)
+}
+
+func (r *postgresRepository) EndExpiredBoosts(ctx context.Context, now time.Time) ([]uuid.UUID, error) {
+	// Close finished boosts (including ones whose whole window passed while the
+	// worker was down), then un-feature cars whose featured window is over
+	query := `
+		WITH ended AS (
+			UPDATE listing_boosts SET status = 'ended', updated_at = ?
+			WHERE status IN ('scheduled', 'active') AND ends_at <= ?
+		)
+		UPDATE cars SET is_featured = FALSE, featured_until = NULL
+		WHERE is_featured AND featured_until <= ?
+		RETURNING id
+	`
+	return r.scanIDs(ctx, query, now, now, now)
+}
+
+// scanIDs runs a query returning car IDs
+func (r *postgresRepository) scanIDs(ctx context.Context, query string, args ...interface{}) ([]uuid.UUID, error) {
+	var rows []struct {
+		ID uuid.UUID `gorm:"column:id"`
+	}
+	if err := r.db.WithContext(ctx).Raw(query, args...).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	ids := make([]uuid.UUID, len(rows))
+	for i, row := range rows {
+		ids[i] = row.ID
+	}
+	return ids, nil
+}
+
 func (r *postgresRepository) FindBySellerID(ctx context.Context, sellerID uuid.UUID, page, limit int) ([]Car, int64, error) {
 	var cars []Car
 	var total int64
@@ -312,3 +758,11 @@ func (r *postgresRepository) GetUsersFavoritedCar(ctx context.Context, carID uui
 	}
 	return userIDs, nil
 }
+
+func (r *postgresRepository) CountActiveListings(ctx context.Context, userID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&Car{}).
+		Where("seller_id = ? AND status = ?", userID.String(), CarStatusActive).
+		Count(&count).Error
+	return count, err
+}
@@ -0,0 +1,64 @@
+package listing
+
+import (
+	"regexp"
+	"strings"
+)
+
+const (
+	// SortByRelevance orders search results by match quality (only meaningful with a search term)
+	SortByRelevance = "relevance"
+
+	// fuzzySimilarityThreshold is the minimum trigram word similarity for the typo-tolerant fallback
+	fuzzySimilarityThreshold = 0.3
+
+	// maxSearchTerms caps how many words of a search phrase are used
+	maxSearchTerms = 8
+)
+
+var searchTermRegex = regexp.MustCompile(`[\p{L}\p{N}]+`)
+
+// searchTerms splits a free-text search into lowercase alphanumeric words
+func searchTerms(q string) []string {
+	terms := searchTermRegex.FindAllString(strings.ToLower(q), -1)
+	if len(terms) > maxSearchTerms {
+		terms = terms[:maxSearchTerms]
+	}
+	return terms
+}
+
+// buildPrefixTSQuery converts a search phrase into a to_tsquery expression where
+// every word must match as a prefix, e.g. "camry hyb" -> "camry:* & hyb:*".
+// Returns an empty string when the phrase has no searchable words.
+func buildPrefixTSQuery(q string) string {
+	terms := searchTerms(q)
+	if len(terms) == 0 {
+		return ""
+	}
+	for i, term := range terms {
+		terms[i] = term + ":*"
+	}
+	return strings.Join(terms, " & ")
+}
+
+// searchFilter returns the WHERE condition, its args, the relevance ORDER BY
+// expression and its args for a search phrase. In fuzzy mode the match uses
+// trigram similarity on title and make/model instead of the tsvector.
+func searchFilter(q string, fuzzy bool) (cond string, args []interface{}, rank string, rankArgs []interface{}) {
+	if fuzzy {
+		phrase := strings.Join(searchTerms(q), " ")
+		if phrase == "" {
+			return "", nil, "", nil
+		}
+		similarity := "GREATEST(word_similarity(?, c.title), word_similarity(?, c.make || ' ' || c.model))"
+		return similarity + " >= ?", []interface{}{phrase, phrase, fuzzySimilarityThreshold},
+			similarity, []interface{}{phrase, phrase}
+	}
+
+	tsQuery := buildPrefixTSQuery(q)
+	if tsQuery == "" {
+		return "", nil, "", nil
+	}
+	return "c.search_vector @@ to_tsquery('english', ?)", []interface{}{tsQuery},
+		"ts_rank_cd(c.search_vector, to_tsquery('english', ?))", []interface{}{tsQuery}
+}
@@ -0,0 +1,30 @@
+package listing
+
+import "testing"
+
+func TestBuildPrefixTSQuery(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"camry hybrid low mileage", "camry:* & hybrid:* & low:* & mileage:*"},
+		{"  Toyota   CAMRY ", "toyota:* & camry:*"},
+		{"bmw x5!!", "bmw:* & x5:*"},
+		{"'; DROP TABLE cars; --", "drop:* & table:* & cars:*"},
+		{"", ""},
+		{"&&& |||", ""},
+	}
+
+	for _, tt := range tests {
+		if got := buildPrefixTSQuery(tt.in); got != tt.want {
+			t.Errorf("buildPrefixTSQuery(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSearchTermsLimit(t *testing.T) {
+	terms := searchTerms("a b c d e f g h i j k")
+	if len(terms) != maxSearchTerms {
+		t.Errorf("expected %d terms, got %d", maxSearchTerms, len(terms))
+	}
+}
@@ -12,9 +12,14 @@ import (
 	"github.com/google/uuid"
 	"github.com/lib/pq"
 	"github.com/redis/go-redis/v9"
+	"github.com/yourusername/car-reselling-backend/internal/audit"
 	"github.com/yourusername/car-reselling-backend/internal/notification"
 )
 
+// ErrListingChanged is returned when a listing's status changed while the
+// seller was editing it (flagged by moderation or reserved by an offer)
+var ErrListingChanged = errors.New("listing status changed while it was being edited, reload and try again")
+
 // NotifierService is an interface for sending push notifications
 type NotifierService interface {
 	SendToUsers(userIDs []uuid.UUID, title, body string, data map[string]string) error
@@ -26,6 +31,21 @@ type NotificationService interface {
 	CreateAndSendBulk(ctx context.Context, userIDs []uuid.UUID, title, message, notifType, imageURL string, data map[string]interface{}) error
 }
 
+// ListingMatcher is notified when a listing becomes active (e.g. saved search alerts)
+type ListingMatcher interface {
+	MatchNewListing(ctx context.Context, car *Car)
+}
+
+// ListingMailer sends listing emails to sellers
+type ListingMailer interface {
+	SendListingExpiring(ctx context.Context, sellerID uuid.UUID, title string, expiresAt time.Time) error
+}
+
+// AuditLogger records listing changes made by sellers
+type AuditLogger interface {
+	Record(ctx context.Context, event audit.Event)
+}
+
 // ListingService struct
 type ListingService struct {
 	repo                ListingRepository
@@ -33,6 +53,12 @@ type ListingService struct {
 	cache               *redis.Client
 	notifier            NotifierService
 	notificationService NotificationService
+	matcher             ListingMatcher
+	mailer              ListingMailer
+	auditLog            AuditLogger
+	quotas              QuotaProvider
+	payments            PaymentService
+	boostPricing        BoostPricing
 }
 
 // NewService creates a new ListingService
@@ -54,6 +80,21 @@ func (s *ListingService) SetNotificationService(ns NotificationService) {
 	s.notificationService = ns
 }
 
+// SetListingMatcher sets the matcher run for newly active listings
+func (s *ListingService) SetListingMatcher(m ListingMatcher) {
+	s.matcher = m
+}
+
+// SetMailer sets the service used to email sellers
+func (s *ListingService) SetMailer(m ListingMailer) {
+	s.mailer = m
+}
+
+// SetAuditLogger sets the audit log for listing changes
+func (s *ListingService) SetAuditLogger(l AuditLogger) {
+	s.auditLog = l
+}
+
 // CreateListing handles creating a new car listing
 func (s *ListingService) CreateListing(ctx context.Context, userID uuid.UUID, req CreateCarRequest, files []*multipart.FileHeader) (*Car, error) {
 	// 1. Validate request
@@ -61,8 +102,12 @@ func (s *ListingService) CreateListing(ctx context.Context, userID uuid.UUID, re
 		return nil, err
 	}
 
-	// 2. Check daily rate limit (5 posts per day)
-	if err := s.checkRateLimit(ctx, userID); err != nil {
+	// 2. Check the seller's plan quotas (posts per day, active listings, images)
+	quota := s.quotaFor(ctx, userID)
+	if err := s.checkPostingQuota(ctx, userID, quota); err != nil {
+		return nil, err
+	}
+	if err := checkImageQuota(len(files), quota); err != nil {
 		return nil, err
 	}
 
@@ -114,7 +159,7 @@ func (s *ListingService) CreateListing(ctx context.Context, userID uuid.UUID, re
 		ChatOnly:     req.ChatOnly,
 		CreatedAt:    now,
 		UpdatedAt:    now,
-		ExpiresAt:    now.AddDate(0, 0, 90), // 90 days expiry
+		ExpiresAt:    now.Add(ListingLifetime),
 	}
 
 	// 5. Save to DB
@@ -122,6 +167,11 @@ func (s *ListingService) CreateListing(ctx context.Context, userID uuid.UUID, re
 		return nil, err
 	}
 
+	s.audit(ctx, audit.ActionListingCreated, userID, car.ID, nil, audit.Metadata{"title": car.Title, "price": car.Price})
+
+	// 6. Alert matching saved searches (async, don't block response)
+	s.matchListing(car)
+
 	return car, nil
 }
 
@@ -169,6 +219,12 @@ func (s *ListingService) GetListing(ctx context.Context, carID uuid.UUID, userID
 		resp.IsOwner = (car.SellerID == userID)
 	}
 
+	if summaries, err := s.repo.FindPriceSummaries(ctx, []uuid.UUID{carID}); err == nil {
+		if summary, ok := summaries[carID]; ok {
+			resp.PriceSummary = &summary
+		}
+	}
+
 	// 5. Cache result (base car data only really, but here we cache the struct.
 	// Ideally we cache only the car data and overlay user-specifics.
 	// For simplicity, we cache the object but re-check user flags if needed.
@@ -194,12 +250,25 @@ func (s *ListingService) ListListings(ctx context.Context, query ListCarsQuery,
 		return nil, 0, err
 	}
 
+	// Price summaries for the whole page in one query
+	carIDs := make([]uuid.UUID, len(cars))
+	for i, car := range cars {
+		carIDs[i] = car.ID
+	}
+	summaries, err := s.repo.FindPriceSummaries(ctx, carIDs)
+	if err != nil {
+		log.Printf("Failed to load price summaries: %v", err)
+	}
+
 	// Batch check favorites if user is logged in
 	// Optimization: Get all favorite IDs for this user
 
 	var responses []CarResponse
 	for _, car := range cars {
 		resp := CarResponse{Car: car}
+		if summary, ok := summaries[car.ID]; ok {
+			resp.PriceSummary = &summary
+		}
 		if userID != uuid.Nil {
 			// N+1 query here, but optimized in repo could be better.
 			// For 20 items it's acceptable, or implement repo.GetFavoriteIDs(userID) and map locally.
@@ -214,6 +283,63 @@ func (s *ListingService) ListListings(ctx context.Context, query ListCarsQuery,
 	return responses, total, nil
 }
 
+// GetPriceHistory returns every price a listing has had along with a summary
+func (s *ListingService) GetPriceHistory(ctx context.Context, carID uuid.UUID) (*PriceHistoryResponse, error) {
+	if _, err := s.repo.FindByID(ctx, carID); err != nil {
+		return nil, err
+	}
+
+	history, err := s.repo.FindPriceHistory(ctx, carID)
+	if err != nil {
+		return nil, err
+	}
+	summaries, err := s.repo.FindPriceSummaries(ctx, []uuid.UUID{carID})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &PriceHistoryResponse{
+		CarID:   carID,
+		Summary: summaries[carID],
+		History: history,
+	}
+	if resp.History == nil {
+		resp.History = []PriceChange{}
+	}
+	return resp, nil
+}
+
+// GetMapClusters aggregates active listings in a viewport into grid cells, or
+// returns individual listings once the map is zoomed in far enough
+func (s *ListingService) GetMapClusters(ctx context.Context, query MapClustersQuery) (*MapClustersResponse, error) {
+	resp := &MapClustersResponse{
+		Zoom:     query.Zoom,
+		Clusters: []MapCluster{},
+		Listings: []MapMarker{},
+	}
+
+	if query.Zoom >= MaxClusterZoom {
+		markers, err := s.repo.FindMarkers(ctx, query.ListCarsQuery, maxMapMarkers)
+		if err != nil {
+			return nil, err
+		}
+		if markers != nil {
+			resp.Listings = markers
+		}
+		return resp, nil
+	}
+
+	resp.CellSize = clusterCellSize(query.Zoom)
+	clusters, err := s.repo.FindClusters(ctx, query.ListCarsQuery, resp.CellSize)
+	if err != nil {
+		return nil, err
+	}
+	if clusters != nil {
+		resp.Clusters = clusters
+	}
+	return resp, nil
+}
+
 // UpdateListing updates an existing listing
 func (s *ListingService) UpdateListing(ctx context.Context, carID, userID uuid.UUID, req UpdateCarRequest, newFiles []*multipart.FileHeader) (*Car, error) {
 	// 1. Fetch existing
@@ -227,8 +353,10 @@ func (s *ListingService) UpdateListing(ctx context.Context, carID, userID uuid.U
 		return nil, errors.New("unauthorized: you do not own this listing")
 	}
 
-	// Track old price for notification
+	// Track old price for notification and old status for re-activation
 	oldPrice := car.Price
+	oldStatus := car.Status
+	before := *car
 
 	// 3. Update fields
 	if req.Title != "" {
@@ -277,9 +405,37 @@ func (s *ListingService) UpdateListing(ctx context.Context, carID, userID uuid.U
 		car.Longitude = req.Longitude
 	}
 	if req.Status != "" {
+		// Expired listings come back through RenewListing so the renewal cap applies
+		if oldStatus == CarStatusExpired && req.Status == CarStatusActive {
+			return nil, errors.New("expired listings must be renewed to re-activate")
+		}
+		// Flagged listings stay hidden until a moderator resolves their reports
+		if oldStatus == CarStatusFlagged && req.Status != CarStatusDeleted {
+			return nil, errors.New("listing is under review and cannot change status")
+		}
+		// Reserved listings go back on sale by cancelling the accepted offer
+		if oldStatus == CarStatusReserved && req.Status != CarStatusSold && req.Status != CarStatusDeleted {
+			return nil, errors.New("reserved listings go back on sale by cancelling the accepted offer")
+		}
 		car.Status = req.Status
 	}
 
+	// Re-activating a listing and adding images count against the seller's plan
+	imagesChanged := len(req.ExistingImages) > 0 || len(newFiles) > 0
+	if imagesChanged || (oldStatus != CarStatusActive && car.Status == CarStatusActive) {
+		quota := s.quotaFor(ctx, userID)
+		if oldStatus != CarStatusActive && car.Status == CarStatusActive {
+			if err := s.checkActiveQuota(ctx, userID, quota); err != nil {
+				return nil, err
+			}
+		}
+		if imagesChanged {
+			if err := checkImageQuota(len(req.ExistingImages)+len(newFiles), quota); err != nil {
+				return nil, err
+			}
+		}
+	}
+
 	// 4. Handle images - merge existing with new uploads
 	// Start with existing images that the user wants to keep
 	var finalImages []string
@@ -302,20 +458,29 @@ func (s *ListingService) UpdateListing(ctx context.Context, carID, userID uuid.U
 
 	// Update images only if we have some (existing or new)
 	// If no existing images specified and no new files, keep original images
-	if len(req.ExistingImages) > 0 || len(newFiles) > 0 {
+	if imagesChanged {
 		car.Images = finalImages
 	}
 
 	car.UpdatedAt = time.Now()
 
 	// 5. Save
-	if err := s.repo.Update(ctx, car); err != nil {
+	if err := s.repo.Update(ctx, car, oldStatus); err != nil {
 		return nil, err
 	}
 
 	// 6. Invalidate cache
 	s.cache.Del(ctx, fmt.Sprintf("cache:car:%s", carID))
 
+	if changes := audit.Diff(before, *car); changes != nil {
+		delete(changes, "updated_at")
+		action := audit.ActionListingUpdated
+		if oldPrice != car.Price {
+			action = audit.ActionListingPriceChanged
+		}
+		s.audit(ctx, action, userID, carID, changes, nil)
+	}
+
 	// 7. Send price change notifications (async, don't block response)
 	log.Printf("DEBUG: UpdateListing - notificationService=%v, notifier=%v, reqPrice=%v, oldPrice=%v, newPrice=%v",
 		s.notificationService != nil, s.notifier != nil, req.Price, oldPrice, car.Price)
@@ -330,6 +495,11 @@ func (s *ListingService) UpdateListing(ctx context.Context, carID, userID uuid.U
 		go s.sendPriceChangeNotifications(carID, car.SellerID, car.Title, oldPrice, car.Price, carImage)
 	}
 
+	// 8. A re-activated listing is new to saved searches again
+	if oldStatus != CarStatusActive && car.Status == CarStatusActive {
+		s.matchListing(car)
+	}
+
 	return car, nil
 }
 
@@ -441,6 +611,8 @@ func (s *ListingService) DeleteListing(ctx context.Context, carID, userID uuid.U
 	go s.storage.DeleteMultipleImages(context.Background(), car.Images)
 
 	s.cache.Del(ctx, fmt.Sprintf("cache:car:%s", carID))
+
+	s.audit(ctx, audit.ActionListingDeleted, userID, carID, nil, audit.Metadata{"title": car.Title, "status": car.Status})
 	return nil
 }
 
@@ -472,17 +644,28 @@ func (s *ListingService) GetFavorites(ctx context.Context, userID uuid.UUID, pag
 
 // Helpers
 
-func (s *ListingService) checkRateLimit(ctx context.Context, userID uuid.UUID) error {
-	// Check against database count for today
-	count, err := s.repo.CountDailyPosts(ctx, userID)
-	if err != nil {
-		return err
+// audit records a seller's action on a listing if an audit log is set
+func (s *ListingService) audit(ctx context.Context, action string, userID, carID uuid.UUID, changes audit.Changes, metadata audit.Metadata) {
+	if s.auditLog == nil {
+		return
 	}
+	s.auditLog.Record(ctx, audit.Event{
+		ActorID:    audit.ActorID(userID),
+		Action:     action,
+		TargetType: audit.TargetListing,
+		TargetID:   carID.String(),
+		Changes:    changes,
+		Metadata:   metadata,
+	})
+}
 
-	if count >= 5 {
-		return errors.New("daily post limit reached: max 5 listings per day")
+// matchListing runs the listing matcher in the background on a copy of the car
+func (s *ListingService) matchListing(car *Car) {
+	if s.matcher == nil {
+		return
 	}
-	return nil
+	snapshot := *car
+	go s.matcher.MatchNewListing(context.Background(), &snapshot)
 }
 
 func (s *ListingService) incrementViewCount(ctx context.Context, carID uuid.UUID) {
@@ -31,11 +31,13 @@ type StorageService interface {
 	DeleteMultipleImages(ctx context.Context, imageURLs []string) error
 }
 
-// R2StorageService implements StorageService using Cloudflare R2
+// R2StorageService implements StorageService using Cloudflare R2. Private
+// documents go to a separate bucket and are addressed by key instead of URL.
 type R2StorageService struct {
-	client    *s3.Client
-	bucket    string
-	publicURL string
+	client        *s3.Client
+	bucket        string
+	privateBucket string
+	publicURL     string
 }
 
 // NewStorageService creates a new R2StorageService
@@ -86,9 +88,10 @@ func NewStorageService(cfg *config.Config) (*R2StorageService, error) {
 	fmt.Printf("✓ R2 Storage Service initialized (Public URL: %s)\n", publicURL)
 
 	return &R2StorageService{
-		client:    client,
-		bucket:    cfg.R2BucketName,
-		publicURL: publicURL,
+		client:        client,
+		bucket:        cfg.R2BucketName,
+		privateBucket: cfg.R2PrivateBucketName,
+		publicURL:     publicURL,
 	}, nil
 }
 
@@ -251,6 +254,87 @@ func (s *R2StorageService) DeleteMultipleImages(ctx context.Context, imageURLs [
 	return nil
 }
 
+// documentTypes are the content types accepted for private documents
+var documentTypes = map[string]bool{
+	"application/pdf": true,
+	"image/jpeg":      true,
+	"image/png":       true,
+}
+
+// UploadDocument stores a file in the private bucket and returns its key.
+// The content must be a PDF, JPEG or PNG.
+func (s *R2StorageService) UploadDocument(ctx context.Context, fileHeader *multipart.FileHeader, folder string) (string, error) {
+	if s.privateBucket == "" {
+		return "", fmt.Errorf("private storage not configured")
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return "", fmt.Errorf("failed to open file %s: %v", fileHeader.Filename, err)
+	}
+	defer file.Close()
+
+	fileBytes, err := io.ReadAll(file)
+	if err != nil {
+		return "", fmt.Errorf("failed to read file %s: %v", fileHeader.Filename, err)
+	}
+
+	// Trust the content, not the file name
+	contentType := http.DetectContentType(fileBytes)
+	if !documentTypes[contentType] {
+		return "", fmt.Errorf("file %s is not a PDF, JPEG or PNG", fileHeader.Filename)
+	}
+
+	key := fmt.Sprintf("%s/%s%s", folder, uuid.New().String(), strings.ToLower(filepath.Ext(fileHeader.Filename)))
+	_, err = s.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(s.privateBucket),
+		Key:         aws.String(key),
+		Body:        bytes.NewReader(fileBytes),
+		ContentType: aws.String(contentType),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to upload to R2: %v", err)
+	}
+
+	return key, nil
+}
+
+// DocumentURL returns a signed URL that gives access to a private document until it expires
+func (s *R2StorageService) DocumentURL(ctx context.Context, key string, expires time.Duration) (string, error) {
+	if s.privateBucket == "" {
+		return "", fmt.Errorf("private storage not configured")
+	}
+
+	req, err := s3.NewPresignClient(s.client).PresignGetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.privateBucket),
+		Key:    aws.String(key),
+	}, s3.WithPresignExpires(expires))
+	if err != nil {
+		return "", fmt.Errorf("failed to sign URL: %v", err)
+	}
+
+	return req.URL, nil
+}
+
+// DeleteDocuments deletes private documents by key
+func (s *R2StorageService) DeleteDocuments(ctx context.Context, keys []string) error {
+	if s.privateBucket == "" {
+		return nil
+	}
+
+	for _, key := range keys {
+		_, err := s.client.DeleteObject(ctx, &s3.DeleteObjectInput{
+			Bucket: aws.String(s.privateBucket),
+			Key:    aws.String(key),
+		})
+		if err != nil {
+			// Log error but continue with other deletions
+			fmt.Printf("Warning: Failed to delete document %s: %v\n", key, err)
+		}
+	}
+	return nil
+}
+
 // NullStorageService is a no-op storage service for when R2 is not configured
 type NullStorageService struct{}
 
@@ -269,3 +353,15 @@ func (s *NullStorageService) DeleteImage(ctx context.Context, imageURL string) e
 func (s *NullStorageService) DeleteMultipleImages(ctx context.Context, imageURLs []string) error {
 	return nil
 }
+
+func (s *NullStorageService) UploadDocument(ctx context.Context, fileHeader *multipart.FileHeader, folder string) (string, error) {
+	return "", fmt.Errorf("storage service not configured")
+}
+
+func (s *NullStorageService) DocumentURL(ctx context.Context, key string, expires time.Duration) (string, error) {
+	return "", fmt.Errorf("storage service not configured")
+}
+
+func (s *NullStorageService) DeleteDocuments(ctx context.Context, keys []string) error {
+	return nil
+}
@@ -30,14 +30,42 @@ func ValidateUpdateCarRequest(req UpdateCarRequest) error {
 	return nil
 }
 
-// ValidateImages checks file count, size, and type
+// ValidateListCarsQuery performs custom validation for listing queries
+func ValidateListCarsQuery(q ListCarsQuery) error {
+	if (q.Lat == nil) != (q.Lng == nil) {
+		return fmt.Errorf("lat and lng must be provided together")
+	}
+	if q.RadiusKm > 0 && q.Lat == nil {
+		return fmt.Errorf("radius_km requires lat and lng")
+	}
+	if q.RadiusKm > MaxRadiusKm {
+		return fmt.Errorf("radius_km cannot exceed %d", MaxRadiusKm)
+	}
+	if q.SortBy == SortByDistance && q.Lat == nil {
+		return fmt.Errorf("sort_by=distance_asc requires lat and lng")
+	}
+	if q.BBox != "" {
+		if _, err := ParseBoundingBox(q.BBox); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ValidateMapClustersQuery performs custom validation for map clustering queries
+func ValidateMapClustersQuery(q MapClustersQuery) error {
+	if q.BBox == "" {
+		return fmt.Errorf("bbox is required")
+	}
+	return ValidateListCarsQuery(q.ListCarsQuery)
+}
+
+// ValidateImages checks that there is at least one file and each file's size
+// and type. The maximum count depends on the seller's plan (see ListingQuota).
 func ValidateImages(files []*multipart.FileHeader) error {
 	if len(files) < 1 {
 		return fmt.Errorf("at least 1 image is required")
 	}
-	if len(files) > 10 {
-		return fmt.Errorf("maximum 10 images allowed")
-	}
 
 	for _, file := range files {
 		// Maxwell size 10MB
@@ -57,7 +85,7 @@ func ValidateImages(files []*multipart.FileHeader) error {
 // IsValidCarStatus checks if the status is valid
 func IsValidCarStatus(status string) bool {
 	switch status {
-	case CarStatusActive, CarStatusSold, CarStatusExpired, CarStatusFlagged, CarStatusDeleted:
+	case CarStatusActive, CarStatusReserved, CarStatusSold, CarStatusExpired, CarStatusFlagged, CarStatusDeleted:
 		return true
 	}
 	return false
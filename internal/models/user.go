@@ -7,6 +7,23 @@ import (
 	"gorm.io/gorm"
 )
 
+// User roles
+const (
+	RoleUser      = "user"
+	RoleDealer    = "dealer"
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+)
+
+// IsValidRole reports whether role is one of the known user roles
+func IsValidRole(role string) bool {
+	switch role {
+	case RoleUser, RoleDealer, RoleModerator, RoleAdmin:
+		return true
+	}
+	return false
+}
+
 // User represents a user in the system
 type User struct {
 	ID              uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
@@ -18,11 +35,23 @@ type User struct {
 	DOB             *time.Time `json:"dob"`
 	ProfilePhotoURL *string    `json:"profile_photo_url"`
 	IsVerified      bool       `gorm:"default:false" json:"is_verified"`
+	EmailVerified   bool       `gorm:"default:false;not null" json:"email_verified"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
 	IsDealer        bool       `gorm:"default:false" json:"is_dealer"`
+	Role            string     `gorm:"type:varchar(20);default:user;not null" json:"role"`
 	IsActive        bool       `gorm:"default:true" json:"is_active"`
 	CreatedAt       time.Time  `json:"created_at"`
 	UpdatedAt       time.Time  `json:"updated_at"`
 	LastLoginAt     *time.Time `json:"last_login_at"`
+
+	// Two-factor authentication
+	TwoFactorEnabled   bool       `gorm:"default:false;not null" json:"two_factor_enabled"`
+	TwoFactorEnabledAt *time.Time `json:"two_factor_enabled_at"`
+	TOTPSecret         *string    `gorm:"column:totp_secret" json:"-"` // Encrypted
+
+	// Account deletion
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at"` // Purge date while a deletion request is pending
+	DeletedAt           *time.Time `json:"deleted_at"`            // Set once the account has been purged
 }
 
 // BeforeCreate hook to generate UUID if not set
@@ -51,3 +80,12 @@ func (v *VerificationCode) BeforeCreate(tx *gorm.DB) error {
 	}
 	return nil
 }
+
+// UserRecoveryCode is a single-use two-factor recovery code
+type UserRecoveryCode struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID    uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	CodeHash  string     `gorm:"not null" json:"-"` // SHA-256 of the normalized code
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
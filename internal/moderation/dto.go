@@ -0,0 +1,31 @@
+package moderation
+
+// ReportRequest is the payload for reporting a listing
+// @Description Reason for reporting a listing
+type ReportRequest struct {
+	Reason  string `json:"reason" binding:"required,oneof=scam wrong_price already_sold offensive_images" example:"scam"`
+	Details string `json:"details" binding:"omitempty,max=1000" example:"Seller asked for a deposit by wire transfer"`
+}
+
+// ResolveRequest is the payload for resolving a listing's reports
+// @Description Moderator decision for a reported listing
+type ResolveRequest struct {
+	Action string `json:"action" binding:"required,oneof=restore remove ban_seller" example:"remove"`
+	Note   string `json:"note" binding:"omitempty,max=500" example:"Confirmed scam"` // Included in the seller notification
+}
+
+// ReportResponse is returned after reporting a listing
+// @Description Report receipt
+type ReportResponse struct {
+	Report  *Report `json:"report"`
+	Flagged bool    `json:"flagged"` // Whether this report pushed the listing over the flag threshold
+}
+
+// QueueResponse is the paginated moderation queue
+// @Description Listings with pending reports, most reported first
+type QueueResponse struct {
+	Items []QueueItem `json:"items"`
+	Total int64       `json:"total"`
+	Page  int         `json:"page"`
+	Limit int         `json:"limit"`
+}
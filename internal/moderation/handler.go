@@ -0,0 +1,195 @@
+package moderation
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	appErrors "github.com/yourusername/car-reselling-backend/pkg/errors"
+)
+
+// Handler handles HTTP requests for listing moderation
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a new moderation handler
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// RegisterRoutes registers the user-facing report route
+func (h *Handler) RegisterRoutes(router *gin.RouterGroup, authMiddleware gin.HandlerFunc) {
+	router.POST("/cars/:id/report", authMiddleware, h.ReportListing)
+}
+
+// RegisterAdminRoutes registers the moderation queue routes on an admin-only group
+func (h *Handler) RegisterAdminRoutes(admin *gin.RouterGroup) {
+	moderation := admin.Group("/moderation")
+	{
+		moderation.GET("/queue", h.GetQueue)
+		moderation.GET("/cars/:id/reports", h.GetCarReports)
+		moderation.POST("/cars/:id/resolve", h.Resolve)
+	}
+}
+
+// ReportListing reports a listing
+// @Summary Report a listing
+// @Description Report a listing as a scam, wrongly priced, already sold or with offensive images. Listings reported by 3 different users are hidden until reviewed.
+// @Tags moderation
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Car ID"
+// @Param request body ReportRequest true "Report reason"
+// @Success 201 {object} ReportResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/cars/{id}/report [post]
+func (h *Handler) ReportListing(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	carID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid car ID"})
+		return
+	}
+
+	var req ReportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := h.service.ReportListing(c.Request.Context(), carID, userID, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, appErrors.ErrNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Listing not found"})
+		case errors.Is(err, ErrAlreadyReported):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, resp)
+}
+
+// GetQueue returns the moderation queue
+// @Summary Get moderation queue
+// @Description Listings with pending reports, flagged listings first, then by report count
+// @Tags moderation
+// @Security BearerAuth
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} QueueResponse
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /api/admin/moderation/queue [get]
+func (h *Handler) GetQueue(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	queue, err := h.service.GetQueue(c.Request.Context(), page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, queue)
+}
+
+// GetCarReports returns all reports filed against a listing
+// @Summary Get listing reports
+// @Tags moderation
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Car ID"
+// @Success 200 {array} Report
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /api/admin/moderation/cars/{id}/reports [get]
+func (h *Handler) GetCarReports(c *gin.Context) {
+	carID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid car ID"})
+		return
+	}
+
+	reports, err := h.service.GetCarReports(c.Request.Context(), carID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, reports)
+}
+
+// Resolve resolves a listing's pending reports
+// @Summary Resolve listing reports
+// @Description Restore the listing, remove it, or remove it and ban the seller. Closes all pending reports and notifies the seller.
+// @Tags moderation
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Car ID"
+// @Param request body ResolveRequest true "Moderator decision"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/admin/moderation/cars/{id}/resolve [post]
+func (h *Handler) Resolve(c *gin.Context) {
+	moderatorID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	carID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid car ID"})
+		return
+	}
+
+	var req ResolveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.service.Resolve(c.Request.Context(), carID, moderatorID, &req); err != nil {
+		if errors.Is(err, appErrors.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Listing not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Reports resolved", "action": req.Action})
+}
+
+func getUserID(c *gin.Context) (uuid.UUID, bool) {
+	userID, err := uuid.Parse(c.GetString("userID"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return uuid.Nil, false
+	}
+	return userID, true
+}
@@ -0,0 +1,70 @@
+package moderation
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Report reasons
+const (
+	ReasonScam            = "scam"
+	ReasonWrongPrice      = "wrong_price"
+	ReasonAlreadySold     = "already_sold"
+	ReasonOffensiveImages = "offensive_images"
+)
+
+// Report statuses
+const (
+	ReportStatusPending  = "pending"
+	ReportStatusResolved = "resolved"
+)
+
+// Moderator actions when resolving a listing's reports
+const (
+	ActionRestore   = "restore"
+	ActionRemove    = "remove"
+	ActionBanSeller = "ban_seller"
+)
+
+// Notification types sent to sellers
+const (
+	NotificationTypeListingFlagged  = "listing_flagged"
+	NotificationTypeListingRestored = "listing_restored"
+	NotificationTypeListingRemoved  = "listing_removed"
+)
+
+// Report is a user's report against a listing
+type Report struct {
+	ID         uuid.UUID  `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	CarID      uuid.UUID  `json:"car_id" gorm:"type:uuid;not null"`
+	ReporterID uuid.UUID  `json:"reporter_id" gorm:"type:uuid;not null"`
+	Reason     string     `json:"reason" gorm:"type:varchar(30);not null"`
+	Details    string     `json:"details"`
+	Status     string     `json:"status" gorm:"type:varchar(20);default:pending"`
+	Resolution *string    `json:"resolution,omitempty"`
+	ResolvedBy *uuid.UUID `json:"resolved_by,omitempty" gorm:"type:uuid"`
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// TableName overrides the default table name
+func (Report) TableName() string { return "listing_reports" }
+
+// QueueItem is a listing with pending reports in the moderation queue
+type QueueItem struct {
+	CarID       uuid.UUID `json:"car_id"`
+	Title       string    `json:"title"`
+	SellerID    uuid.UUID `json:"seller_id"`
+	SellerName  string    `json:"seller_name"`
+	CarStatus   string    `json:"car_status"`
+	ReportCount int64     `json:"report_count"`
+
+	// Pending reports per reason
+	ScamCount            int64 `json:"scam_count"`
+	WrongPriceCount      int64 `json:"wrong_price_count"`
+	AlreadySoldCount     int64 `json:"already_sold_count"`
+	OffensiveImagesCount int64 `json:"offensive_images_count"`
+
+	LastReportedAt time.Time `json:"last_reported_at"`
+}
@@ -0,0 +1,193 @@
+package moderation
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/yourusername/car-reselling-backend/internal/listing"
+	appErrors "github.com/yourusername/car-reselling-backend/pkg/errors"
+)
+
+// ErrAlreadyReported is returned when a user reports a listing their earlier
+// report on is still pending
+var ErrAlreadyReported = errors.New("you have already reported this listing")
+
+// Repository handles database operations for listing moderation
+type Repository struct {
+	db *gorm.DB
+}
+
+// NewRepository creates a new moderation repository
+func NewRepository(db *gorm.DB) *Repository {
+	return &Repository{db: db}
+}
+
+// --- Listing Operations ---
+
+// FindCar retrieves the listing fields moderation needs (deleted listings are not found)
+func (r *Repository) FindCar(ctx context.Context, carID uuid.UUID) (*listing.Car, error) {
+	var car listing.Car
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT id, seller_id, title, status, images
+		FROM cars
+		WHERE id = ? AND status != 'deleted'
+	`, carID.String()).Scan(&car).Error
+	if err != nil {
+		return nil, err
+	}
+	if car.ID == uuid.Nil {
+		return nil, appErrors.ErrNotFound
+	}
+	return &car, nil
+}
+
+// FlagCar marks an active listing as flagged. Returns false if it wasn't active.
+func (r *Repository) FlagCar(ctx context.Context, carID uuid.UUID) (bool, error) {
+	result := r.db.WithContext(ctx).Exec(
+		"UPDATE cars SET status = 'flagged', updated_at = NOW() WHERE id = ? AND status = 'active'",
+		carID.String(),
+	)
+	return result.RowsAffected > 0, result.Error
+}
+
+// --- Report Operations ---
+
+// CreateReport stores a report, or returns ErrAlreadyReported if the user
+// already has a pending report on the listing. Once a moderator resolves it
+// they can report the listing again.
+func (r *Repository) CreateReport(ctx context.Context, report *Report) error {
+	result := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(report)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrAlreadyReported
+	}
+	return nil
+}
+
+// CountPendingReporters returns how many distinct users have pending reports on a listing
+func (r *Repository) CountPendingReporters(ctx context.Context, carID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&Report{}).
+		Where("car_id = ? AND status = ?", carID, ReportStatusPending).
+		Distinct("reporter_id").
+		Count(&count).Error
+	return count, err
+}
+
+// FindReportsByCar retrieves all reports for a listing, newest first
+func (r *Repository) FindReportsByCar(ctx context.Context, carID uuid.UUID) ([]Report, error) {
+	var reports []Report
+	err := r.db.WithContext(ctx).
+		Where("car_id = ?", carID).
+		Order("created_at DESC").
+		Find(&reports).Error
+	return reports, err
+}
+
+// FindQueue retrieves listings with pending reports, flagged and most reported first
+func (r *Repository) FindQueue(ctx context.Context, page, limit int) ([]QueueItem, int64, error) {
+	var total int64
+	err := r.db.WithContext(ctx).Model(&Report{}).
+		Where("status = ?", ReportStatusPending).
+		Distinct("car_id").
+		Count(&total).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var items []QueueItem
+	err = r.db.WithContext(ctx).Raw(`
+		SELECT c.id as car_id,
+			   c.title,
+			   c.seller_id,
+			   u.full_name as seller_name,
+			   c.status as car_status,
+			   COUNT(*) as report_count,
+			   COUNT(*) FILTER (WHERE lr.reason = ?) as scam_count,
+			   COUNT(*) FILTER (WHERE lr.reason = ?) as wrong_price_count,
+			   COUNT(*) FILTER (WHERE lr.reason = ?) as already_sold_count,
+			   COUNT(*) FILTER (WHERE lr.reason = ?) as offensive_images_count,
+			   MAX(lr.created_at) as last_reported_at
+		FROM listing_reports lr
+		JOIN cars c ON c.id = lr.car_id
+		LEFT JOIN users u ON u.id = c.seller_id
+		WHERE lr.status = ?
+		GROUP BY c.id, u.full_name
+		ORDER BY (c.status = 'flagged') DESC, report_count DESC, last_reported_at DESC
+		LIMIT ? OFFSET ?
+	`, ReasonScam, ReasonWrongPrice, ReasonAlreadySold, ReasonOffensiveImages,
+		ReportStatusPending, limit, (page-1)*limit).Scan(&items).Error
+
+	return items, total, err
+}
+
+// Resolve applies a moderator action to a listing and closes its pending reports.
+// Returns the IDs of every listing whose status changed.
+func (r *Repository) Resolve(ctx context.Context, car *listing.Car, action string, moderatorID uuid.UUID) ([]uuid.UUID, error) {
+	var changed []uuid.UUID
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		switch action {
+		case ActionRestore:
+			result := tx.Exec(
+				"UPDATE cars SET status = 'active', updated_at = NOW() WHERE id = ? AND status = 'flagged'",
+				car.ID.String(),
+			)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected > 0 {
+				changed = append(changed, car.ID)
+			}
+
+		case ActionRemove:
+			if err := tx.Exec(
+				"UPDATE cars SET status = 'deleted', updated_at = NOW() WHERE id = ?",
+				car.ID.String(),
+			).Error; err != nil {
+				return err
+			}
+			changed = append(changed, car.ID)
+
+		case ActionBanSeller:
+			if err := tx.Exec(
+				"UPDATE users SET is_active = false, updated_at = NOW() WHERE id = ?",
+				car.SellerID.String(),
+			).Error; err != nil {
+				return err
+			}
+			// Take down every listing of the banned seller
+			if err := tx.Raw(`
+				UPDATE cars SET status = 'deleted', updated_at = NOW()
+				WHERE seller_id = ? AND status != 'deleted'
+				RETURNING id
+			`, car.SellerID.String()).Scan(&changed).Error; err != nil {
+				return err
+			}
+
+		default:
+			return errors.New("invalid moderation action")
+		}
+
+		now := time.Now()
+		return tx.Model(&Report{}).
+			Where("car_id = ? AND status = ?", car.ID, ReportStatusPending).
+			Updates(map[string]interface{}{
+				"status":      ReportStatusResolved,
+				"resolution":  action,
+				"resolved_by": moderatorID,
+				"resolved_at": now,
+			}).Error
+	})
+
+	return changed, err
+}
@@ -0,0 +1,192 @@
+package moderation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/yourusername/car-reselling-backend/internal/listing"
+	"github.com/yourusername/car-reselling-backend/internal/notification"
+)
+
+// FlagThreshold is how many distinct users must report a listing before it is hidden pending review
+const FlagThreshold = 3
+
+// NotificationSender is the subset of the notification service used to inform sellers
+type NotificationSender interface {
+	CreateAndSend(ctx context.Context, userID uuid.UUID, title, message, notifType, imageURL string, data map[string]interface{}) (*notification.Notification, error)
+}
+
+// SessionRevoker logs a banned seller out of every device
+type SessionRevoker interface {
+	RevokeAllSessions(ctx context.Context, userID string) error
+}
+
+// Service handles listing reports and moderation decisions
+type Service struct {
+	repo         *Repository
+	cache        *redis.Client
+	notification NotificationSender
+	sessions     SessionRevoker
+}
+
+// NewService creates a new moderation service
+func NewService(repo *Repository, cache *redis.Client, notification NotificationSender, sessions SessionRevoker) *Service {
+	return &Service{
+		repo:         repo,
+		cache:        cache,
+		notification: notification,
+		sessions:     sessions,
+	}
+}
+
+// ReportListing records a user's report and flags the listing once enough
+// distinct users have reported it
+func (s *Service) ReportListing(ctx context.Context, carID, reporterID uuid.UUID, req *ReportRequest) (*ReportResponse, error) {
+	car, err := s.repo.FindCar(ctx, carID)
+	if err != nil {
+		return nil, err
+	}
+	if car.SellerID == reporterID {
+		return nil, errors.New("you cannot report your own listing")
+	}
+
+	report := &Report{
+		CarID:      carID,
+		ReporterID: reporterID,
+		Reason:     req.Reason,
+		Details:    req.Details,
+		Status:     ReportStatusPending,
+	}
+	if err := s.repo.CreateReport(ctx, report); err != nil {
+		return nil, err
+	}
+
+	resp := &ReportResponse{Report: report}
+
+	reporters, err := s.repo.CountPendingReporters(ctx, carID)
+	if err != nil {
+		log.Printf("Failed to count reports for car %s: %v", carID, err)
+		return resp, nil
+	}
+	if reporters < FlagThreshold {
+		return resp, nil
+	}
+
+	flagged, err := s.repo.FlagCar(ctx, carID)
+	if err != nil {
+		log.Printf("Failed to flag car %s: %v", carID, err)
+		return resp, nil
+	}
+	if flagged {
+		resp.Flagged = true
+		s.invalidateCar(ctx, carID)
+		go s.notifySeller(car, "Your listing is under review",
+			fmt.Sprintf("%s was reported by several users and is hidden until a moderator reviews it.", car.Title),
+			NotificationTypeListingFlagged)
+	}
+
+	return resp, nil
+}
+
+// GetQueue returns listings with pending reports
+func (s *Service) GetQueue(ctx context.Context, page, limit int) (*QueueResponse, error) {
+	items, total, err := s.repo.FindQueue(ctx, page, limit)
+	if err != nil {
+		return nil, err
+	}
+	if items == nil {
+		items = []QueueItem{}
+	}
+	return &QueueResponse{Items: items, Total: total, Page: page, Limit: limit}, nil
+}
+
+// GetCarReports returns every report filed against a listing
+func (s *Service) GetCarReports(ctx context.Context, carID uuid.UUID) ([]Report, error) {
+	reports, err := s.repo.FindReportsByCar(ctx, carID)
+	if err != nil {
+		return nil, err
+	}
+	if reports == nil {
+		reports = []Report{}
+	}
+	return reports, nil
+}
+
+// Resolve applies a moderator decision to a reported listing and notifies the seller
+func (s *Service) Resolve(ctx context.Context, carID, moderatorID uuid.UUID, req *ResolveRequest) error {
+	car, err := s.repo.FindCar(ctx, carID)
+	if err != nil {
+		return err
+	}
+
+	changed, err := s.repo.Resolve(ctx, car, req.Action, moderatorID)
+	if err != nil {
+		return err
+	}
+	for _, id := range changed {
+		s.invalidateCar(ctx, id)
+	}
+	if req.Action == ActionBanSeller {
+		// The ban only blocks new logins, so end the sessions already open
+		if err := s.sessions.RevokeAllSessions(ctx, car.SellerID.String()); err != nil {
+			log.Printf("Failed to revoke sessions of banned seller %s: %v", car.SellerID, err)
+		}
+	}
+
+	var title, body, notifType string
+	switch req.Action {
+	case ActionRestore:
+		if car.Status != listing.CarStatusFlagged {
+			return nil // Listing was never hidden, nothing to tell the seller
+		}
+		title = "Your listing is visible again"
+		body = fmt.Sprintf("%s was reviewed and restored.", car.Title)
+		notifType = NotificationTypeListingRestored
+	case ActionRemove:
+		title = "Your listing was removed"
+		body = fmt.Sprintf("%s was removed after review.", car.Title)
+		notifType = NotificationTypeListingRemoved
+	case ActionBanSeller:
+		title = "Your account has been suspended"
+		body = fmt.Sprintf("%s and your other listings were removed after review.", car.Title)
+		notifType = NotificationTypeListingRemoved
+	}
+	if req.Note != "" {
+		body += " " + req.Note
+	}
+
+	go s.notifySeller(car, title, body, notifType)
+	return nil
+}
+
+// Helpers
+
+func (s *Service) invalidateCar(ctx context.Context, carID uuid.UUID) {
+	if s.cache != nil {
+		s.cache.Del(ctx, fmt.Sprintf("cache:car:%s", carID))
+	}
+}
+
+func (s *Service) notifySeller(car *listing.Car, title, body, notifType string) {
+	if s.notification == nil {
+		return
+	}
+
+	var carImage string
+	if len(car.Images) > 0 {
+		carImage = car.Images[0]
+	}
+	data := map[string]interface{}{
+		"car_id":    car.ID.String(),
+		"car_title": car.Title,
+	}
+
+	if _, err := s.notification.CreateAndSend(context.Background(), car.SellerID, title, body, notifType, carImage, data); err != nil {
+		log.Printf("Failed to send %s notification for car %s: %v", notifType, car.ID, err)
+	}
+}
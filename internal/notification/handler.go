@@ -30,6 +30,11 @@ func (h *Handler) RegisterRoutes(router *gin.RouterGroup, authMiddleware gin.Han
 	}
 }
 
+// RegisterAdminRoutes registers notification routes on an admin-only group
+func (h *Handler) RegisterAdminRoutes(admin *gin.RouterGroup) {
+	admin.POST("/notifications/broadcast", h.Broadcast)
+}
+
 // List returns a paginated list of notifications for the authenticated user
 // @Summary List user notifications
 // @Description Get paginated notifications for the current user
@@ -182,3 +187,32 @@ func (h *Handler) MarkAllAsRead(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"message": "All notifications marked as read"})
 }
+
+// Broadcast sends an announcement to all active users or to one role
+// @Summary Broadcast notification
+// @Description Send a notification to every active user, or only users with the given role (admin only)
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Param request body BroadcastRequest true "Announcement"
+// @Success 202 {object} BroadcastResponse
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/admin/notifications/broadcast [post]
+// @Security BearerAuth
+func (h *Handler) Broadcast(c *gin.Context) {
+	var req BroadcastRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	recipients, err := h.service.Broadcast(c.Request.Context(), &req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, BroadcastResponse{Recipients: recipients})
+}
@@ -64,3 +64,19 @@ type PaginatedNotificationsResponse struct {
 type UnreadCountResponse struct {
 	Count int64 `json:"count"`
 }
+
+// NotificationTypeBroadcast is the type of admin announcements
+const NotificationTypeBroadcast = "broadcast"
+
+// BroadcastRequest is the payload for an admin announcement
+type BroadcastRequest struct {
+	Title    string `json:"title" binding:"required,max=255" example:"Scheduled maintenance"`
+	Message  string `json:"message" binding:"required,max=2000" example:"The app will be unavailable tonight from 2am to 3am."`
+	ImageURL string `json:"image_url" binding:"omitempty,url,max=512"`
+	Role     string `json:"role" binding:"omitempty,oneof=user dealer moderator admin" example:"dealer"` // Only users with this role (all active users if empty)
+}
+
+// BroadcastResponse reports how many users a broadcast was queued for
+type BroadcastResponse struct {
+	Recipients int `json:"recipients" example:"1520"`
+}
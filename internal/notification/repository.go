@@ -96,3 +96,15 @@ func (r *Repository) DeleteOldNotifications(ctx context.Context, days int) error
 		Where("created_at < NOW() - INTERVAL '? days'", days).
 		Delete(&Notification{}).Error
 }
+
+// FindActiveUserIDs returns the IDs of all active users, optionally limited to one role
+func (r *Repository) FindActiveUserIDs(ctx context.Context, role string) ([]uuid.UUID, error) {
+	query := r.db.WithContext(ctx).Table("users").Where("is_active = ?", true)
+	if role != "" {
+		query = query.Where("role = ?", role)
+	}
+
+	var userIDs []uuid.UUID
+	err := query.Pluck("id", &userIDs).Error
+	return userIDs, err
+}
@@ -123,6 +123,28 @@ func (s *Service) CreateAndSendBulk(ctx context.Context, userIDs []uuid.UUID, ti
 	return nil
 }
 
+// Broadcast queues an announcement for all active users (or all users with a role).
+// Returns the number of recipients; delivery continues in the background.
+func (s *Service) Broadcast(ctx context.Context, req *BroadcastRequest) (int, error) {
+	userIDs, err := s.repo.FindActiveUserIDs(ctx, req.Role)
+	if err != nil {
+		return 0, err
+	}
+	if len(userIDs) == 0 {
+		return 0, nil
+	}
+
+	go func() {
+		data := map[string]interface{}{"broadcast": true}
+		if err := s.CreateAndSendBulk(context.Background(), userIDs, req.Title, req.Message, NotificationTypeBroadcast, req.ImageURL, data); err != nil {
+			log.Printf("Failed to send broadcast: %v", err)
+		}
+		log.Printf("Broadcast %q sent to %d users", req.Title, len(userIDs))
+	}()
+
+	return len(userIDs), nil
+}
+
 // GetUserNotifications retrieves paginated notifications for a user
 func (s *Service) GetUserNotifications(ctx context.Context, userID uuid.UUID, page, limit int) (*PaginatedNotificationsResponse, error) {
 	notifications, total, err := s.repo.FindByUserID(ctx, userID, page, limit)
@@ -0,0 +1,24 @@
+package offer
+
+import "time"
+
+// CreateOfferRequest makes an offer on a listing
+type CreateOfferRequest struct {
+	Amount    float64    `json:"amount" binding:"required,gt=0" example:"18500"`
+	ExpiresAt *time.Time `json:"expires_at"` // Optional, defaults to DefaultOfferLifetime from now
+	Message   string     `json:"message" binding:"max=500"`
+}
+
+// CounterOfferRequest answers a pending offer with another price
+type CounterOfferRequest struct {
+	Amount    float64    `json:"amount" binding:"required,gt=0" example:"19500"`
+	ExpiresAt *time.Time `json:"expires_at"` // Optional, defaults to DefaultOfferLifetime from now
+}
+
+// OfferListResponse is a page of offers
+type OfferListResponse struct {
+	Items []Offer `json:"items"`
+	Total int64   `json:"total"`
+	Page  int     `json:"page"`
+	Limit int     `json:"limit"`
+}
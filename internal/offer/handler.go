@@ -0,0 +1,358 @@
+package offer
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	appErrors "github.com/yourusername/car-reselling-backend/pkg/errors"
+)
+
+// Handler handles HTTP requests for offers
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a new offer handler
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// RegisterRoutes registers the offer routes; all of them require authentication
+func (h *Handler) RegisterRoutes(router *gin.RouterGroup, authMiddleware gin.HandlerFunc) {
+	router.POST("/cars/:id/offers", authMiddleware, h.CreateOffer)
+	router.GET("/cars/:id/offers", authMiddleware, h.GetCarOffers)
+
+	offers := router.Group("/offers")
+	offers.Use(authMiddleware)
+	{
+		offers.GET("", h.GetMyOffers)
+		offers.POST("/:id/accept", h.Accept)
+		offers.POST("/:id/reject", h.Reject)
+		offers.POST("/:id/counter", h.Counter)
+		offers.POST("/:id/withdraw", h.Withdraw)
+		offers.POST("/:id/cancel", h.Cancel)
+	}
+}
+
+// CreateOffer makes an offer on a listing
+// @Summary Make an offer
+// @Description Offer a price for an active listing. The offer is posted into the conversation with the seller and stays open until expires_at (default 48 hours, at most 7 days). One open offer per buyer and listing.
+// @Tags offers
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Car ID"
+// @Param request body CreateOfferRequest true "Amount and optional expiry"
+// @Success 201 {object} Offer
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string "Listing not available or an open offer exists"
+// @Router /api/cars/{id}/offers [post]
+func (h *Handler) CreateOffer(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	carID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid car ID"})
+		return
+	}
+
+	var req CreateOfferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	offer, err := h.service.CreateOffer(c.Request.Context(), userID, carID, &req)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, offer)
+}
+
+// GetCarOffers lists the offers on a listing
+// @Summary List offers on a listing
+// @Description The seller sees every offer on the listing; anyone else sees their own
+// @Tags offers
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Car ID"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} OfferListResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/cars/{id}/offers [get]
+func (h *Handler) GetCarOffers(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	carID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid car ID"})
+		return
+	}
+
+	page, limit := pagination(c)
+	offers, err := h.service.GetCarOffers(c.Request.Context(), userID, carID, page, limit)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, offers)
+}
+
+// GetMyOffers lists the offers a user made or received
+// @Summary List my offers
+// @Description Offers the current user made (role=buyer, default) or received on their listings (role=seller), newest first
+// @Tags offers
+// @Security BearerAuth
+// @Produce json
+// @Param role query string false "buyer or seller" Enums(buyer, seller) default(buyer)
+// @Param status query string false "Filter by status" Enums(pending, countered, accepted, rejected, expired, withdrawn, cancelled)
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} OfferListResponse
+// @Failure 400 {object} map[string]string
+// @Router /api/offers [get]
+func (h *Handler) GetMyOffers(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	role := c.DefaultQuery("role", RoleBuyer)
+	if role != RoleBuyer && role != RoleSeller {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "role must be buyer or seller"})
+		return
+	}
+	status := c.Query("status")
+	switch status {
+	case "", StatusPending, StatusCountered, StatusAccepted, StatusRejected, StatusExpired, StatusWithdrawn:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status"})
+		return
+	}
+
+	page, limit := pagination(c)
+	offers, err := h.service.GetMyOffers(c.Request.Context(), userID, role, status, page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, offers)
+}
+
+// Accept accepts an offer
+// @Summary Accept an offer
+// @Description The seller accepts a pending offer, or the buyer a counter-offer. The listing's other open offers are declined and the listing is reserved unless reserve=false.
+// @Tags offers
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Offer ID"
+// @Param reserve query bool false "Reserve the listing" default(true)
+// @Success 200 {object} Offer
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string "Offer not open or listing not available"
+// @Router /api/offers/{id}/accept [post]
+func (h *Handler) Accept(c *gin.Context) {
+	userID, offerID, ok := offerParams(c)
+	if !ok {
+		return
+	}
+
+	reserve, err := strconv.ParseBool(c.DefaultQuery("reserve", "true"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "reserve must be true or false"})
+		return
+	}
+
+	offer, err := h.service.Accept(c.Request.Context(), userID, offerID, reserve)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, offer)
+}
+
+// Reject declines an offer
+// @Summary Reject an offer
+// @Description The seller rejects a pending offer, or the buyer a counter-offer
+// @Tags offers
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Offer ID"
+// @Success 200 {object} Offer
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string "Offer not open"
+// @Router /api/offers/{id}/reject [post]
+func (h *Handler) Reject(c *gin.Context) {
+	userID, offerID, ok := offerParams(c)
+	if !ok {
+		return
+	}
+
+	offer, err := h.service.Reject(c.Request.Context(), userID, offerID)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, offer)
+}
+
+// Counter answers an offer with another price
+// @Summary Counter an offer
+// @Description The seller answers a pending offer with their price; the buyer can then accept or reject it
+// @Tags offers
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Offer ID"
+// @Param request body CounterOfferRequest true "Counter amount and optional expiry"
+// @Success 200 {object} Offer
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string "Offer not pending"
+// @Router /api/offers/{id}/counter [post]
+func (h *Handler) Counter(c *gin.Context) {
+	userID, offerID, ok := offerParams(c)
+	if !ok {
+		return
+	}
+
+	var req CounterOfferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	offer, err := h.service.Counter(c.Request.Context(), userID, offerID, &req)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, offer)
+}
+
+// Withdraw takes back an offer
+// @Summary Withdraw an offer
+// @Description The buyer withdraws their pending or countered offer
+// @Tags offers
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Offer ID"
+// @Success 200 {object} Offer
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string "Offer not open"
+// @Router /api/offers/{id}/withdraw [post]
+func (h *Handler) Withdraw(c *gin.Context) {
+	userID, offerID, ok := offerParams(c)
+	if !ok {
+		return
+	}
+
+	offer, err := h.service.Withdraw(c.Request.Context(), userID, offerID)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, offer)
+}
+
+// Cancel calls off an accepted offer
+// @Summary Cancel an accepted offer
+// @Description The seller or the buyer calls off an accepted offer. A listing it reserved goes back on sale, and the buyer can no longer book viewings through it.
+// @Tags offers
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Offer ID"
+// @Success 200 {object} Offer
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string "Offer not accepted"
+// @Router /api/offers/{id}/cancel [post]
+func (h *Handler) Cancel(c *gin.Context) {
+	userID, offerID, ok := offerParams(c)
+	if !ok {
+		return
+	}
+
+	offer, err := h.service.Cancel(c.Request.Context(), userID, offerID)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, offer)
+}
+
+func handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, appErrors.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Offer or listing not found"})
+	case errors.Is(err, ErrOwnListing):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrInvalidTransition), errors.Is(err, ErrOfferExpired),
+		errors.Is(err, ErrOfferExists), errors.Is(err, ErrListingUnavailable):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrInvalidExpiry):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// offerParams reads the current user and the offer ID from the path
+func offerParams(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return uuid.Nil, uuid.Nil, false
+	}
+	offerID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid offer ID"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	return userID, offerID, true
+}
+
+func pagination(c *gin.Context) (int, int) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	return page, limit
+}
+
+func getUserID(c *gin.Context) (uuid.UUID, bool) {
+	userID, err := uuid.Parse(c.GetString("userID"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return uuid.Nil, false
+	}
+	return userID, true
+}
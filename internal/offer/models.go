@@ -0,0 +1,162 @@
+package offer
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Offer statuses. Pending offers wait for the seller and countered offers for
+// the buyer. Accepted offers can still be cancelled by either side; the others
+// are final.
+const (
+	StatusPending   = "pending"
+	StatusCountered = "countered"
+	StatusAccepted  = "accepted"
+	StatusRejected  = "rejected"
+	StatusExpired   = "expired"
+	StatusWithdrawn = "withdrawn"
+	StatusCancelled = "cancelled" // Accepted, then called off; the listing went back on sale
+)
+
+// Actions on an offer
+const (
+	ActionAccept   = "accept"
+	ActionReject   = "reject"
+	ActionCounter  = "counter"
+	ActionWithdraw = "withdraw"
+	ActionCancel   = "cancel"
+)
+
+// Sides of an offer
+const (
+	RoleBuyer  = "buyer"
+	RoleSeller = "seller"
+)
+
+// Reasons for offers rejected by the system rather than the seller
+const (
+	DeclineReasonOtherAccepted = "another_offer_accepted"
+)
+
+// Notification types sent about offers
+const (
+	NotificationTypeOfferReceived  = "offer_received"
+	NotificationTypeOfferCountered = "offer_countered"
+	NotificationTypeOfferAccepted  = "offer_accepted"
+	NotificationTypeOfferRejected  = "offer_rejected"
+	NotificationTypeOfferWithdrawn = "offer_withdrawn"
+	NotificationTypeOfferExpired   = "offer_expired"
+	NotificationTypeOfferCancelled = "offer_cancelled"
+)
+
+// Offer is a buyer's price offer on a listing
+type Offer struct {
+	ID            uuid.UUID  `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	CarID         uuid.UUID  `json:"car_id" gorm:"type:uuid"`
+	BuyerID       uuid.UUID  `json:"buyer_id" gorm:"type:uuid"`
+	SellerID      uuid.UUID  `json:"seller_id" gorm:"type:uuid"`
+	Amount        float64    `json:"amount" gorm:"type:decimal(12,2)"`
+	CounterAmount *float64   `json:"counter_amount,omitempty" gorm:"type:decimal(12,2)"` // Seller's counter-offer
+	Message       string     `json:"message,omitempty"`
+	Status        string     `json:"status" gorm:"type:varchar(20);default:pending"`
+	DeclineReason string     `json:"decline_reason,omitempty"`
+	ExpiresAt     time.Time  `json:"expires_at"`
+	RespondedAt   *time.Time `json:"responded_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+
+	// Listing context, filled in by queries that join cars
+	CarTitle string `json:"car_title,omitempty" gorm:"->;-:migration"`
+}
+
+// TableName overrides the default table name
+func (Offer) TableName() string { return "offers" }
+
+// Price is the amount on the table: the counter-offer once the seller made one
+func (o *Offer) Price() float64 {
+	if o.CounterAmount != nil {
+		return *o.CounterAmount
+	}
+	return o.Amount
+}
+
+// currencySymbols are shown instead of the code for common currencies
+var currencySymbols = map[string]string{"USD": "$", "EUR": "€", "GBP": "£", "INR": "₹"}
+
+// formatAmount formats an amount for messages, e.g. "$12,500" or "CHF 99.50".
+// Cents are only shown when there are some.
+func formatAmount(amount float64, currency string) string {
+	cents := int64(math.Round(amount * 100))
+	digits := strconv.FormatInt(cents/100, 10)
+
+	var b strings.Builder
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(d)
+	}
+	if cents%100 != 0 {
+		fmt.Fprintf(&b, ".%02d", cents%100)
+	}
+
+	currency = strings.ToUpper(currency)
+	if symbol, ok := currencySymbols[currency]; ok {
+		return symbol + b.String()
+	}
+	return currency + " " + b.String()
+}
+
+// IsOpen reports whether the offer still waits for an answer
+func (o *Offer) IsOpen() bool {
+	return o.Status == StatusPending || o.Status == StatusCountered
+}
+
+// Car is the part of a listing offers need
+type Car struct {
+	ID       uuid.UUID
+	SellerID uuid.UUID
+	Title    string
+	Status   string
+	Price    float64
+	Image    string // First image, for notifications
+}
+
+// transition returns the status an offer moves to when one side takes an
+// action on it, or ErrInvalidTransition. The seller answers pending offers and
+// the buyer answers counter-offers; the buyer can withdraw while it is open.
+// Either side can cancel an accepted offer.
+func transition(status, role, action string) (string, error) {
+	switch {
+	case action == ActionWithdraw:
+		if role == RoleBuyer && (status == StatusPending || status == StatusCountered) {
+			return StatusWithdrawn, nil
+		}
+	case action == ActionCancel:
+		if status == StatusAccepted {
+			return StatusCancelled, nil
+		}
+	case status == StatusPending && role == RoleSeller:
+		switch action {
+		case ActionAccept:
+			return StatusAccepted, nil
+		case ActionReject:
+			return StatusRejected, nil
+		case ActionCounter:
+			return StatusCountered, nil
+		}
+	case status == StatusCountered && role == RoleBuyer:
+		switch action {
+		case ActionAccept:
+			return StatusAccepted, nil
+		case ActionReject:
+			return StatusRejected, nil
+		}
+	}
+	return "", ErrInvalidTransition
+}
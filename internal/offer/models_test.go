@@ -0,0 +1,64 @@
+package offer
+
+import "testing"
+
+func TestTransition(t *testing.T) {
+	tests := []struct {
+		status, role, action string
+		want                 string // empty when the action is not allowed
+	}{
+		{StatusPending, RoleSeller, ActionAccept, StatusAccepted},
+		{StatusPending, RoleSeller, ActionReject, StatusRejected},
+		{StatusPending, RoleSeller, ActionCounter, StatusCountered},
+		{StatusPending, RoleBuyer, ActionWithdraw, StatusWithdrawn},
+		{StatusCountered, RoleBuyer, ActionAccept, StatusAccepted},
+		{StatusCountered, RoleBuyer, ActionReject, StatusRejected},
+		{StatusCountered, RoleBuyer, ActionWithdraw, StatusWithdrawn},
+		{StatusAccepted, RoleSeller, ActionCancel, StatusCancelled},
+		{StatusAccepted, RoleBuyer, ActionCancel, StatusCancelled},
+
+		{StatusPending, RoleBuyer, ActionAccept, ""},
+		{StatusPending, RoleSeller, ActionWithdraw, ""},
+		{StatusCountered, RoleSeller, ActionAccept, ""},
+		{StatusCountered, RoleBuyer, ActionCounter, ""},
+		{StatusAccepted, RoleBuyer, ActionWithdraw, ""},
+		{StatusExpired, RoleSeller, ActionAccept, ""},
+		{StatusRejected, RoleSeller, ActionCounter, ""},
+		{StatusPending, RoleSeller, ActionCancel, ""},
+		{StatusCancelled, RoleBuyer, ActionCancel, ""},
+	}
+
+	for _, tt := range tests {
+		got, err := transition(tt.status, tt.role, tt.action)
+		if tt.want == "" {
+			if err != ErrInvalidTransition {
+				t.Errorf("%s %s on %s: expected ErrInvalidTransition, got %q", tt.role, tt.action, tt.status, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("%s %s on %s = %q, %v; want %q", tt.role, tt.action, tt.status, got, err, tt.want)
+		}
+	}
+}
+
+func TestFormatAmount(t *testing.T) {
+	tests := []struct {
+		amount   float64
+		currency string
+		want     string
+	}{
+		{12500, "USD", "$12,500"},
+		{12500.5, "USD", "$12,500.50"},
+		{999.99, "eur", "€999.99"},
+		{1250000, "INR", "₹1,250,000"},
+		{100, "CHF", "CHF 100"},
+		{0.5, "GBP", "£0.50"},
+	}
+
+	for _, tt := range tests {
+		if got := formatAmount(tt.amount, tt.currency); got != tt.want {
+			t.Errorf("formatAmount(%v, %q) = %q, want %q", tt.amount, tt.currency, got, tt.want)
+		}
+	}
+}
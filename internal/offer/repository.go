@@ -0,0 +1,217 @@
+package offer
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/yourusername/car-reselling-backend/internal/listing"
+	appErrors "github.com/yourusername/car-reselling-backend/pkg/errors"
+)
+
+// Repository handles database operations for offers
+type Repository struct {
+	db *gorm.DB
+}
+
+// NewRepository creates a new offer repository
+func NewRepository(db *gorm.DB) *Repository {
+	return &Repository{db: db}
+}
+
+// FindCar retrieves the listing an offer is about
+func (r *Repository) FindCar(ctx context.Context, carID uuid.UUID) (*Car, error) {
+	var car Car
+	res := r.db.WithContext(ctx).Raw(`
+		SELECT id, seller_id, title, status, price, COALESCE(images[1], '') AS image
+		FROM cars WHERE id = ?`,
+		carID,
+	).Scan(&car)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil, appErrors.ErrNotFound
+	}
+	return &car, nil
+}
+
+// Create stores a new offer. Returns false if the buyer already has an open
+// offer on the listing.
+func (r *Repository) Create(ctx context.Context, offer *Offer) (bool, error) {
+	res := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(offer)
+	return res.RowsAffected > 0, res.Error
+}
+
+// FindByID retrieves an offer
+func (r *Repository) FindByID(ctx context.Context, id uuid.UUID) (*Offer, error) {
+	var offer Offer
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&offer).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, appErrors.ErrNotFound
+	}
+	return &offer, err
+}
+
+// ListForCar retrieves a page of a listing's offers, newest first. If buyerID
+// is set only that buyer's offers are returned.
+func (r *Repository) ListForCar(ctx context.Context, carID uuid.UUID, buyerID *uuid.UUID, page, limit int) ([]Offer, int64, error) {
+	query := r.db.WithContext(ctx).Model(&Offer{}).Where("car_id = ?", carID)
+	if buyerID != nil {
+		query = query.Where("buyer_id = ?", *buyerID)
+	}
+	return r.page(query, "*", page, limit)
+}
+
+// ListForUser retrieves a page of the offers a user made (RoleBuyer) or
+// received (RoleSeller), optionally filtered by status, with listing titles
+func (r *Repository) ListForUser(ctx context.Context, userID uuid.UUID, role, status string, page, limit int) ([]Offer, int64, error) {
+	column := "offers.buyer_id"
+	if role == RoleSeller {
+		column = "offers.seller_id"
+	}
+
+	query := r.db.WithContext(ctx).Model(&Offer{}).
+		Joins("JOIN cars ON cars.id = offers.car_id").
+		Where(column+" = ?", userID)
+	if status != "" {
+		query = query.Where("offers.status = ?", status)
+	}
+	return r.page(query, "offers.*, cars.title AS car_title", page, limit)
+}
+
+// page counts the offers a query matches and retrieves a page of them
+func (r *Repository) page(query *gorm.DB, columns string, page, limit int) ([]Offer, int64, error) {
+	query = query.Session(&gorm.Session{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offers := []Offer{}
+	err := query.Select(columns).Order("offers.created_at DESC").
+		Offset((page - 1) * limit).
+		Limit(limit).
+		Find(&offers).Error
+	return offers, total, err
+}
+
+// Respond saves a seller's or buyer's answer to an offer that is still in
+// status from and not past its expiry. Returns false if it changed meanwhile.
+func (r *Repository) Respond(ctx context.Context, offer *Offer, from string) (bool, error) {
+	res := r.db.WithContext(ctx).Model(&Offer{}).
+		Where("id = ? AND status = ? AND expires_at > NOW()", offer.ID, from).
+		Updates(map[string]interface{}{
+			"status":         offer.Status,
+			"counter_amount": offer.CounterAmount,
+			"expires_at":     offer.ExpiresAt,
+			"responded_at":   offer.RespondedAt,
+			"updated_at":     time.Now(),
+		})
+	return res.RowsAffected > 0, res.Error
+}
+
+// Accept accepts an offer that is still in status from and rejects the
+// listing's other open offers, reserving the listing if reserve is set. The
+// listing is locked and must still be active. Returns the rejected offers.
+func (r *Repository) Accept(ctx context.Context, offer *Offer, from string, reserve bool) ([]Offer, error) {
+	var declined []Offer
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var status string
+		if err := tx.Raw("SELECT status FROM cars WHERE id = ? FOR UPDATE", offer.CarID).Scan(&status).Error; err != nil {
+			return err
+		}
+		if status != listing.CarStatusActive {
+			return ErrListingUnavailable
+		}
+
+		res := tx.Model(&Offer{}).
+			Where("id = ? AND status = ? AND expires_at > NOW()", offer.ID, from).
+			Updates(map[string]interface{}{
+				"status":       StatusAccepted,
+				"responded_at": offer.RespondedAt,
+				"updated_at":   time.Now(),
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrInvalidTransition
+		}
+
+		if err := tx.Raw(`
+			UPDATE offers
+			SET status = 'rejected', decline_reason = ?, responded_at = NOW(), updated_at = NOW()
+			WHERE car_id = ? AND id <> ? AND status IN ('pending', 'countered')
+			RETURNING *`,
+			DeclineReasonOtherAccepted, offer.CarID, offer.ID,
+		).Scan(&declined).Error; err != nil {
+			return err
+		}
+
+		if reserve {
+			return tx.Exec(
+				"UPDATE cars SET status = 'reserved', updated_at = NOW() WHERE id = ?", offer.CarID,
+			).Error
+		}
+		return nil
+	})
+
+	return declined, err
+}
+
+// Cancel calls off an accepted offer and puts the listing back on sale if the
+// offer reserved it. The listing is locked while the offer changes. Returns
+// whether the listing was released.
+func (r *Repository) Cancel(ctx context.Context, offer *Offer) (bool, error) {
+	released := false
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var status string
+		if err := tx.Raw("SELECT status FROM cars WHERE id = ? FOR UPDATE", offer.CarID).Scan(&status).Error; err != nil {
+			return err
+		}
+
+		res := tx.Model(&Offer{}).
+			Where("id = ? AND status = ?", offer.ID, StatusAccepted).
+			Updates(map[string]interface{}{
+				"status":       StatusCancelled,
+				"responded_at": offer.RespondedAt,
+				"updated_at":   time.Now(),
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrInvalidTransition
+		}
+
+		if status != listing.CarStatusReserved {
+			return nil
+		}
+		released = true
+		return tx.Exec(
+			"UPDATE cars SET status = 'active', updated_at = NOW() WHERE id = ?", offer.CarID,
+		).Error
+	})
+
+	return released, err
+}
+
+// ExpireDue closes open offers whose expiry has passed and returns them
+func (r *Repository) ExpireDue(ctx context.Context, now time.Time) ([]Offer, error) {
+	var expired []Offer
+	err := r.db.WithContext(ctx).Raw(`
+		UPDATE offers SET status = 'expired', updated_at = NOW()
+		WHERE status IN ('pending', 'countered') AND expires_at <= ?
+		RETURNING *`,
+		now,
+	).Scan(&expired).Error
+	return expired, err
+}
@@ -0,0 +1,509 @@
+package offer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/yourusername/car-reselling-backend/internal/audit"
+	"github.com/yourusername/car-reselling-backend/internal/chat"
+	"github.com/yourusername/car-reselling-backend/internal/listing"
+	"github.com/yourusername/car-reselling-backend/internal/notification"
+	appErrors "github.com/yourusername/car-reselling-backend/pkg/errors"
+)
+
+const (
+	// DefaultOfferLifetime is how long an offer or counter-offer stays open
+	// when no expiry is given
+	DefaultOfferLifetime = 48 * time.Hour
+
+	// MinOfferLifetime and MaxOfferLifetime bound a requested expiry
+	MinOfferLifetime = time.Hour
+	MaxOfferLifetime = 7 * 24 * time.Hour
+
+	// expiryInterval is how often the expiry worker runs
+	expiryInterval = 5 * time.Minute
+
+	// defaultCurrency is used for amounts in messages until SetCurrency is called
+	defaultCurrency = "USD"
+)
+
+var (
+	// ErrInvalidTransition is returned when an offer cannot take an action in its current status
+	ErrInvalidTransition = errors.New("offer cannot be changed in its current state")
+	// ErrOfferExpired is returned when answering an offer past its expiry
+	ErrOfferExpired = errors.New("offer has expired")
+	// ErrOfferExists is returned when a buyer makes a second open offer on a listing
+	ErrOfferExists = errors.New("you already have an open offer on this listing")
+	// ErrOwnListing is returned when a seller makes an offer on their own listing
+	ErrOwnListing = errors.New("you cannot make an offer on your own listing")
+	// ErrListingUnavailable is returned when the listing is no longer active
+	ErrListingUnavailable = errors.New("listing is not available")
+	// ErrInvalidExpiry is returned for an expiry outside MinOfferLifetime..MaxOfferLifetime
+	ErrInvalidExpiry = fmt.Errorf("expires_at must be between %d hour and %d days from now",
+		int(MinOfferLifetime.Hours()), int(MaxOfferLifetime.Hours()/24))
+)
+
+// NotificationSender is the subset of the notification service used to inform buyers and sellers
+type NotificationSender interface {
+	CreateAndSend(ctx context.Context, userID uuid.UUID, title, message, notifType, imageURL string, data map[string]interface{}) (*notification.Notification, error)
+}
+
+// ChatPoster posts offer messages into the buyer and seller's conversation
+type ChatPoster interface {
+	PostCarMessage(msg chat.CarMessage) (uuid.UUID, error)
+}
+
+// AuditLogger records listings reserved and released through offers
+type AuditLogger interface {
+	Record(ctx context.Context, event audit.Event)
+}
+
+// Service handles offers and their negotiation
+type Service struct {
+	repo         *Repository
+	cache        *redis.Client
+	notification NotificationSender
+	chat         ChatPoster
+	auditLog     AuditLogger
+	currency     string
+}
+
+// NewService creates a new offer service
+func NewService(repo *Repository, cache *redis.Client, notification NotificationSender) *Service {
+	return &Service{
+		repo:         repo,
+		cache:        cache,
+		notification: notification,
+		currency:     defaultCurrency,
+	}
+}
+
+// SetCurrency sets the ISO 4217 currency listing prices and offers are in
+func (s *Service) SetCurrency(code string) {
+	s.currency = code
+}
+
+// SetChatPoster sets where offer messages are posted
+func (s *Service) SetChatPoster(c ChatPoster) {
+	s.chat = c
+}
+
+// SetAuditLogger sets the audit log for reserved and released listings
+func (s *Service) SetAuditLogger(l AuditLogger) {
+	s.auditLog = l
+}
+
+// CreateOffer makes a buyer's offer on an active listing
+func (s *Service) CreateOffer(ctx context.Context, buyerID, carID uuid.UUID, req *CreateOfferRequest) (*Offer, error) {
+	car, err := s.repo.FindCar(ctx, carID)
+	if err != nil {
+		return nil, err
+	}
+	if car.Status != listing.CarStatusActive {
+		return nil, ErrListingUnavailable
+	}
+	if car.SellerID == buyerID {
+		return nil, ErrOwnListing
+	}
+
+	now := time.Now()
+	expiresAt, err := offerExpiry(now, req.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	offer := &Offer{
+		CarID:     carID,
+		BuyerID:   buyerID,
+		SellerID:  car.SellerID,
+		Amount:    req.Amount,
+		Message:   req.Message,
+		Status:    StatusPending,
+		ExpiresAt: expiresAt,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	created, err := s.repo.Create(ctx, offer)
+	if err != nil {
+		return nil, err
+	}
+	if !created {
+		return nil, ErrOfferExists
+	}
+
+	content := fmt.Sprintf("Offered %s", s.money(offer.Amount))
+	if offer.Message != "" {
+		content += ": " + offer.Message
+	}
+	s.postMessage(car, offer, buyerID, content)
+	s.notify(ctx, car, offer, offer.SellerID, "New offer 💰",
+		fmt.Sprintf("You received an offer of %s for %s", s.money(offer.Amount), car.Title),
+		NotificationTypeOfferReceived)
+
+	return offer, nil
+}
+
+// GetCarOffers returns the offers on a listing: all of them for its seller,
+// the user's own ones for anyone else
+func (s *Service) GetCarOffers(ctx context.Context, userID, carID uuid.UUID, page, limit int) (*OfferListResponse, error) {
+	car, err := s.repo.FindCar(ctx, carID)
+	if err != nil {
+		return nil, err
+	}
+
+	var buyerID *uuid.UUID
+	if car.SellerID != userID {
+		buyerID = &userID
+	}
+	offers, total, err := s.repo.ListForCar(ctx, carID, buyerID, page, limit)
+	if err != nil {
+		return nil, err
+	}
+	for i := range offers {
+		offers[i].CarTitle = car.Title
+	}
+
+	return &OfferListResponse{Items: offers, Total: total, Page: page, Limit: limit}, nil
+}
+
+// GetMyOffers returns the offers a user made (RoleBuyer) or received
+// (RoleSeller), optionally filtered by status
+func (s *Service) GetMyOffers(ctx context.Context, userID uuid.UUID, role, status string, page, limit int) (*OfferListResponse, error) {
+	offers, total, err := s.repo.ListForUser(ctx, userID, role, status, page, limit)
+	if err != nil {
+		return nil, err
+	}
+	return &OfferListResponse{Items: offers, Total: total, Page: page, Limit: limit}, nil
+}
+
+// Accept accepts an offer: the seller accepts a pending offer, the buyer a
+// counter-offer. The listing's other open offers are rejected and, if reserve
+// is set, the listing is reserved.
+func (s *Service) Accept(ctx context.Context, userID, offerID uuid.UUID, reserve bool) (*Offer, error) {
+	offer, car, err := s.prepare(ctx, userID, offerID, ActionAccept)
+	if err != nil {
+		return nil, err
+	}
+
+	from := offer.Status
+	now := time.Now()
+	offer.Status = StatusAccepted
+	offer.RespondedAt = &now
+
+	declined, err := s.repo.Accept(ctx, offer, from, reserve)
+	if err != nil {
+		return nil, err
+	}
+
+	if reserve {
+		s.cache.Del(ctx, fmt.Sprintf("cache:car:%s", car.ID))
+		if s.auditLog != nil {
+			s.auditLog.Record(ctx, audit.Event{
+				ActorID:    audit.ActorID(userID),
+				Action:     audit.ActionListingUpdated,
+				TargetType: audit.TargetListing,
+				TargetID:   car.ID.String(),
+				Changes:    audit.Changes{"status": {Before: car.Status, After: listing.CarStatusReserved}},
+				Metadata:   audit.Metadata{"offer_id": offer.ID.String(), "amount": offer.Price()},
+			})
+		}
+	}
+
+	s.postMessage(car, offer, userID, fmt.Sprintf("Accepted the offer of %s", s.money(offer.Price())))
+	s.notify(ctx, car, offer, otherParty(offer, userID), "Offer accepted 🎉",
+		fmt.Sprintf("Your %s of %s for %s was accepted", proposal(offer), s.money(offer.Price()), car.Title),
+		NotificationTypeOfferAccepted)
+
+	for i := range declined {
+		other := &declined[i]
+		s.postMessage(car, other, other.SellerID, "The seller accepted another offer")
+		s.notify(ctx, car, other, other.BuyerID, "Offer declined",
+			fmt.Sprintf("The seller of %s accepted another offer", car.Title),
+			NotificationTypeOfferRejected)
+	}
+
+	return offer, nil
+}
+
+// Reject declines an offer: the seller rejects a pending offer, the buyer a
+// counter-offer
+func (s *Service) Reject(ctx context.Context, userID, offerID uuid.UUID) (*Offer, error) {
+	offer, car, err := s.respond(ctx, userID, offerID, ActionReject, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	s.postMessage(car, offer, userID, fmt.Sprintf("Declined the offer of %s", s.money(offer.Price())))
+	s.notify(ctx, car, offer, otherParty(offer, userID), "Offer declined",
+		fmt.Sprintf("Your %s of %s for %s was declined", proposal(offer), s.money(offer.Price()), car.Title),
+		NotificationTypeOfferRejected)
+	return offer, nil
+}
+
+// Counter answers a pending offer with the seller's price
+func (s *Service) Counter(ctx context.Context, userID, offerID uuid.UUID, req *CounterOfferRequest) (*Offer, error) {
+	offer, car, err := s.respond(ctx, userID, offerID, ActionCounter, func(o *Offer, now time.Time) error {
+		expiresAt, err := offerExpiry(now, req.ExpiresAt)
+		if err != nil {
+			return err
+		}
+		o.CounterAmount = &req.Amount
+		o.ExpiresAt = expiresAt
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.postMessage(car, offer, userID, fmt.Sprintf("Countered with %s", s.money(req.Amount)))
+	s.notify(ctx, car, offer, offer.BuyerID, "Counter-offer",
+		fmt.Sprintf("The seller of %s countered your offer of %s with %s", car.Title, s.money(offer.Amount), s.money(req.Amount)),
+		NotificationTypeOfferCountered)
+	return offer, nil
+}
+
+// Withdraw takes back a buyer's open offer
+func (s *Service) Withdraw(ctx context.Context, userID, offerID uuid.UUID) (*Offer, error) {
+	offer, car, err := s.respond(ctx, userID, offerID, ActionWithdraw, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	s.postMessage(car, offer, userID, "Withdrew the offer")
+	s.notify(ctx, car, offer, offer.SellerID, "Offer withdrawn",
+		fmt.Sprintf("An offer of %s for %s was withdrawn", s.money(offer.Amount), car.Title),
+		NotificationTypeOfferWithdrawn)
+	return offer, nil
+}
+
+// Cancel calls off an accepted offer on behalf of either side. A listing the
+// offer reserved goes back on sale, and the buyer loses the access to it the
+// accepted offer gave them, e.g. booking viewings.
+func (s *Service) Cancel(ctx context.Context, userID, offerID uuid.UUID) (*Offer, error) {
+	offer, car, err := s.prepare(ctx, userID, offerID, ActionCancel)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	offer.Status = StatusCancelled
+	offer.RespondedAt = &now
+
+	released, err := s.repo.Cancel(ctx, offer)
+	if err != nil {
+		return nil, err
+	}
+
+	if released {
+		s.cache.Del(ctx, fmt.Sprintf("cache:car:%s", car.ID))
+		if s.auditLog != nil {
+			s.auditLog.Record(ctx, audit.Event{
+				ActorID:    audit.ActorID(userID),
+				Action:     audit.ActionListingUpdated,
+				TargetType: audit.TargetListing,
+				TargetID:   car.ID.String(),
+				Changes:    audit.Changes{"status": {Before: listing.CarStatusReserved, After: listing.CarStatusActive}},
+				Metadata:   audit.Metadata{"offer_id": offer.ID.String()},
+			})
+		}
+	}
+
+	s.postMessage(car, offer, userID, "Cancelled the accepted offer")
+	s.notify(ctx, car, offer, otherParty(offer, userID), "Offer cancelled",
+		fmt.Sprintf("The accepted offer of %s for %s was cancelled", s.money(offer.Price()), car.Title),
+		NotificationTypeOfferCancelled)
+	return offer, nil
+}
+
+// prepare loads an offer the user is a party to and checks the action is
+// allowed. Offers are hidden from everyone else.
+func (s *Service) prepare(ctx context.Context, userID, offerID uuid.UUID, action string) (*Offer, *Car, error) {
+	offer, err := s.repo.FindByID(ctx, offerID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if userID != offer.BuyerID && userID != offer.SellerID {
+		return nil, nil, appErrors.ErrNotFound
+	}
+	if _, err := transition(offer.Status, roleOf(offer, userID), action); err != nil {
+		return nil, nil, err
+	}
+	if offer.IsOpen() && !time.Now().Before(offer.ExpiresAt) {
+		return nil, nil, ErrOfferExpired
+	}
+
+	car, err := s.repo.FindCar(ctx, offer.CarID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return offer, car, nil
+}
+
+// respond applies an action other than accepting. update makes any further
+// changes to the offer before it is saved.
+func (s *Service) respond(ctx context.Context, userID, offerID uuid.UUID, action string, update func(o *Offer, now time.Time) error) (*Offer, *Car, error) {
+	offer, car, err := s.prepare(ctx, userID, offerID, action)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	from := offer.Status
+	now := time.Now()
+	offer.Status, _ = transition(from, roleOf(offer, userID), action)
+	offer.RespondedAt = &now
+	offer.UpdatedAt = now
+	if update != nil {
+		if err := update(offer, now); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	saved, err := s.repo.Respond(ctx, offer, from)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !saved {
+		return nil, nil, ErrInvalidTransition
+	}
+	return offer, car, nil
+}
+
+// RunExpiryWorker closes open offers once their expiry passes. Blocks forever;
+// run in a goroutine.
+func (s *Service) RunExpiryWorker() {
+	ticker := time.NewTicker(expiryInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		s.ExpireOffers(context.Background())
+	}
+}
+
+// ExpireOffers closes overdue offers and tells whoever made the last proposal
+func (s *Service) ExpireOffers(ctx context.Context) {
+	expired, err := s.repo.ExpireDue(ctx, time.Now())
+	if err != nil {
+		log.Printf("Failed to expire offers: %v", err)
+		return
+	}
+
+	for i := range expired {
+		offer := &expired[i]
+		car, err := s.repo.FindCar(ctx, offer.CarID)
+		if err != nil {
+			log.Printf("Failed to load car %s for expired offer %s: %v", offer.CarID, offer.ID, err)
+			continue
+		}
+
+		// A counter-offer waited for the buyer, anything else for the seller
+		proposer := offer.BuyerID
+		if offer.CounterAmount != nil {
+			proposer = offer.SellerID
+		}
+		s.postMessage(car, offer, proposer, "The offer expired")
+		s.notify(ctx, car, offer, proposer, "Offer expired",
+			fmt.Sprintf("Your %s of %s for %s expired without an answer", proposal(offer), s.money(offer.Price()), car.Title),
+			NotificationTypeOfferExpired)
+	}
+	if len(expired) > 0 {
+		log.Printf("Expired %d offers", len(expired))
+	}
+}
+
+// postMessage posts an offer update into the buyer and seller's conversation
+func (s *Service) postMessage(car *Car, offer *Offer, senderID uuid.UUID, content string) {
+	if s.chat == nil {
+		return
+	}
+	_, err := s.chat.PostCarMessage(chat.CarMessage{
+		CarID:       car.ID,
+		CarTitle:    car.Title,
+		SellerID:    offer.SellerID,
+		BuyerID:     offer.BuyerID,
+		SenderID:    senderID,
+		MessageType: chat.MessageTypeOffer,
+		Content:     content,
+		Data:        offerData(offer, s.currency),
+	})
+	if err != nil {
+		log.Printf("Failed to post offer %s message: %v", offer.ID, err)
+	}
+}
+
+// notify sends an offer notification to one side
+func (s *Service) notify(ctx context.Context, car *Car, offer *Offer, userID uuid.UUID, title, body, notifType string) {
+	if s.notification == nil {
+		return
+	}
+	data := offerData(offer, s.currency)
+	data["car_title"] = car.Title
+	if _, err := s.notification.CreateAndSend(ctx, userID, title, body, notifType, car.Image, data); err != nil {
+		log.Printf("Failed to send %s notification for offer %s: %v", notifType, offer.ID, err)
+	}
+}
+
+// money formats an amount in the listing currency for messages
+func (s *Service) money(amount float64) string {
+	return formatAmount(amount, s.currency)
+}
+
+// offerData is the structured form of an offer in chat messages and
+// notifications. Amounts are kept unformatted for clients to display.
+func offerData(offer *Offer, currency string) map[string]interface{} {
+	data := map[string]interface{}{
+		"offer_id":   offer.ID.String(),
+		"car_id":     offer.CarID.String(),
+		"status":     offer.Status,
+		"amount":     offer.Amount,
+		"currency":   currency,
+		"expires_at": offer.ExpiresAt,
+	}
+	if offer.CounterAmount != nil {
+		data["counter_amount"] = *offer.CounterAmount
+	}
+	if offer.DeclineReason != "" {
+		data["decline_reason"] = offer.DeclineReason
+	}
+	return data
+}
+
+// offerExpiry returns the expiry of a new offer or counter-offer
+func offerExpiry(now time.Time, requested *time.Time) (time.Time, error) {
+	if requested == nil {
+		return now.Add(DefaultOfferLifetime), nil
+	}
+	lifetime := requested.Sub(now)
+	if lifetime < MinOfferLifetime || lifetime > MaxOfferLifetime {
+		return time.Time{}, ErrInvalidExpiry
+	}
+	return *requested, nil
+}
+
+// proposal names the price on the table: the buyer's offer or the seller's counter-offer
+func proposal(offer *Offer) string {
+	if offer.CounterAmount != nil {
+		return "counter-offer"
+	}
+	return "offer"
+}
+
+func roleOf(offer *Offer, userID uuid.UUID) string {
+	if userID == offer.SellerID {
+		return RoleSeller
+	}
+	return RoleBuyer
+}
+
+func otherParty(offer *Offer, userID uuid.UUID) uuid.UUID {
+	if userID == offer.SellerID {
+		return offer.BuyerID
+	}
+	return offer.SellerID
+}
@@ -0,0 +1,36 @@
+package payments
+
+import "github.com/google/uuid"
+
+// CheckoutResponse is a started checkout
+// @Description Send the buyer to checkout_url to pay
+type CheckoutResponse struct {
+	PaymentID   uuid.UUID `json:"payment_id"`
+	CheckoutURL string    `json:"checkout_url"`
+}
+
+// RefundPaymentRequest represents the payload for refunding a payment
+// @Description Amount to refund; omit to refund whatever has not been refunded yet
+type RefundPaymentRequest struct {
+	Amount *float64 `json:"amount" binding:"omitempty,gt=0" example:"5.00"`
+	Reason string   `json:"reason" binding:"omitempty,max=255" example:"Listing removed by moderation"`
+}
+
+// PaymentListResponse is a page of payments
+// @Description A user's payments, newest first
+type PaymentListResponse struct {
+	Items []Payment `json:"items"`
+	Total int64     `json:"total"`
+	Page  int       `json:"page"`
+	Limit int       `json:"limit"`
+}
+
+// LedgerResponse is a page of a user's ledger with their balances
+// @Description Ledger entries, newest first, and charged/refunded totals per currency
+type LedgerResponse struct {
+	Items    []LedgerEntry   `json:"items"`
+	Balances []LedgerBalance `json:"balances"`
+	Total    int64           `json:"total"`
+	Page     int             `json:"page"`
+	Limit    int             `json:"limit"`
+}
@@ -0,0 +1,209 @@
+package payments
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	appErrors "github.com/yourusername/car-reselling-backend/pkg/errors"
+)
+
+// maxWebhookBytes caps webhook bodies; provider events are a few KB
+const maxWebhookBytes = 1 << 20
+
+// Handler handles HTTP requests for payments
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a new payments handler
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// RegisterRoutes registers the provider webhook and the user's payment history
+func (h *Handler) RegisterRoutes(router *gin.RouterGroup, authMiddleware gin.HandlerFunc) {
+	router.POST("/payments/webhook", h.Webhook)
+	router.GET("/payments/me", authMiddleware, h.ListMyPayments)
+}
+
+// RegisterAdminRoutes registers refund and ledger routes on an admin-only group
+func (h *Handler) RegisterAdminRoutes(admin *gin.RouterGroup) {
+	admin.POST("/payments/:id/refund", h.Refund)
+	admin.GET("/users/:id/ledger", h.GetLedger)
+}
+
+// Webhook receives payment provider events
+// @Summary Payment provider webhook
+// @Description Signed events from the payment provider (checkout completed or expired, charge refunded). Each event is applied once; repeated deliveries are acknowledged and ignored.
+// @Tags payments
+// @Accept json
+// @Produce json
+// @Param Stripe-Signature header string true "Webhook signature"
+// @Success 200 {object} map[string]bool
+// @Failure 400 {object} map[string]string "Invalid signature or payload"
+// @Failure 503 {object} map[string]string "Payments not configured"
+// @Router /api/payments/webhook [post]
+func (h *Handler) Webhook(c *gin.Context) {
+	payload, err := io.ReadAll(io.LimitReader(c.Request.Body, maxWebhookBytes))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Could not read body"})
+		return
+	}
+
+	if err := h.service.HandleWebhook(c.Request.Context(), payload, c.Request.Header); err != nil {
+		switch {
+		case errors.Is(err, ErrNotConfigured):
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		case errors.Is(err, ErrInvalidSignature):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			// The provider retries on errors
+			log.Printf("Failed to handle payment webhook: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to handle event"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"received": true})
+}
+
+// ListMyPayments returns the current user's payments
+// @Summary List my payments
+// @Description The current user's payments, newest first
+// @Tags payments
+// @Security BearerAuth
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} PaymentListResponse
+// @Failure 401 {object} map[string]string
+// @Router /api/payments/me [get]
+func (h *Handler) ListMyPayments(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	page, limit := pagination(c)
+	payments, err := h.service.ListPayments(c.Request.Context(), userID, page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, payments)
+}
+
+// Refund refunds a payment
+// @Summary Refund a payment
+// @Description Refund part or all of a paid payment through the provider and record it in the ledger
+// @Tags payments
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Payment ID"
+// @Param request body RefundPaymentRequest true "Amount and reason"
+// @Success 200 {object} Payment
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string "Payment not refundable"
+// @Router /api/admin/payments/{id}/refund [post]
+func (h *Handler) Refund(c *gin.Context) {
+	adminID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	paymentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid payment ID"})
+		return
+	}
+
+	var req RefundPaymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	payment, err := h.service.Refund(c.Request.Context(), adminID, paymentID, &req)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, payment)
+}
+
+// GetLedger returns a user's ledger
+// @Summary Get a user's ledger
+// @Description A user's ledger entries, newest first, with charged, refunded and net totals per currency
+// @Tags payments
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "User ID"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} LedgerResponse
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /api/admin/users/{id}/ledger [get]
+func (h *Handler) GetLedger(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	page, limit := pagination(c)
+	ledger, err := h.service.GetLedger(c.Request.Context(), userID, page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, ledger)
+}
+
+func handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, appErrors.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrNotRefundable):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrRefundTooLarge), errors.Is(err, ErrInvalidAmount):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrNotConfigured):
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+func pagination(c *gin.Context) (int, int) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	return page, limit
+}
+
+func getUserID(c *gin.Context) (uuid.UUID, bool) {
+	userID, err := uuid.Parse(c.GetString("userID"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return uuid.Nil, false
+	}
+	return userID, true
+}
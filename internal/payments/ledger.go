@@ -0,0 +1,56 @@
+package payments
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// chargeTransaction books a payment: the provider holds the money, and it is
+// revenue
+func chargeTransaction(p *Payment, now time.Time) []LedgerEntry {
+	description := fmt.Sprintf("Charge for %s", p.Purpose)
+	return ledgerTransaction(p, KindCharge, AccountProviderBalance, AccountRevenue, p.Amount, description, now)
+}
+
+// refundTransaction reverses amount of a payment's charge
+func refundTransaction(p *Payment, amount float64, now time.Time) []LedgerEntry {
+	description := fmt.Sprintf("Refund for %s", p.Purpose)
+	return ledgerTransaction(p, KindRefund, AccountRevenue, AccountProviderBalance, amount, description, now)
+}
+
+// ledgerTransaction returns a balanced pair of entries moving amount from the
+// credited account to the debited one
+func ledgerTransaction(p *Payment, kind, debitAccount, creditAccount string, amount float64, description string, now time.Time) []LedgerEntry {
+	txID := uuid.New()
+	entry := func(account string, debit, credit float64) LedgerEntry {
+		return LedgerEntry{
+			ID:            uuid.New(),
+			TransactionID: txID,
+			UserID:        p.UserID,
+			PaymentID:     p.ID,
+			Kind:          kind,
+			Account:       account,
+			Debit:         debit,
+			Credit:        credit,
+			Currency:      p.Currency,
+			Description:   description,
+			CreatedAt:     now,
+		}
+	}
+	return []LedgerEntry{
+		entry(debitAccount, amount, 0),
+		entry(creditAccount, 0, amount),
+	}
+}
+
+// balanced reports whether the entries' debits equal their credits, to the cent
+func balanced(entries []LedgerEntry) bool {
+	var debits, credits int64
+	for _, e := range entries {
+		debits += toMinorUnits(e.Debit)
+		credits += toMinorUnits(e.Credit)
+	}
+	return debits == credits
+}
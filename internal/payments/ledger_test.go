@@ -0,0 +1,41 @@
+package payments
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestLedgerTransactions(t *testing.T) {
+	payment := &Payment{ID: uuid.New(), UserID: uuid.New(), Purpose: "boost", Amount: 35, Currency: "USD"}
+	now := time.Now()
+
+	charge := chargeTransaction(payment, now)
+	refund := refundTransaction(payment, 10.10, now)
+
+	for name, entries := range map[string][]LedgerEntry{"charge": charge, "refund": refund} {
+		if len(entries) != 2 || !balanced(entries) {
+			t.Fatalf("%s: expected a balanced pair, got %+v", name, entries)
+		}
+		if entries[0].TransactionID != entries[1].TransactionID {
+			t.Errorf("%s: entries belong to different transactions", name)
+		}
+		for _, e := range entries {
+			if e.UserID != payment.UserID || e.PaymentID != payment.ID {
+				t.Errorf("%s: entry not recorded against the payment's user: %+v", name, e)
+			}
+		}
+	}
+
+	if charge[0].Account != AccountProviderBalance || charge[0].Debit != 35 {
+		t.Errorf("charge should debit the provider balance, got %+v", charge[0])
+	}
+	if refund[0].Account != AccountRevenue || refund[0].Debit != 10.10 {
+		t.Errorf("refund should debit revenue, got %+v", refund[0])
+	}
+
+	if balanced([]LedgerEntry{{Debit: 10}, {Credit: 9.99}}) {
+		t.Error("unbalanced entries reported as balanced")
+	}
+}
@@ -0,0 +1,88 @@
+package payments
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Payment statuses
+const (
+	StatusPending   = "pending"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+	StatusRefunded  = "refunded" // Fully refunded; partial refunds stay succeeded
+	StatusReview    = "review"   // Paid, but not the amount or currency expected; not fulfilled or booked
+)
+
+// Payment purposes; each is delivered by the Fulfiller registered for it
+const (
+	PurposeBoost = "boost" // ReferenceID is the listing boost
+	PurposePlan  = "plan"  // ReferenceID is the seller plan
+)
+
+// Ledger transaction kinds
+const (
+	KindCharge = "charge"
+	KindRefund = "refund"
+)
+
+// Ledger accounts. A charge moves money from the buyer into the provider
+// balance and books it as revenue; a refund reverses that.
+const (
+	AccountProviderBalance = "provider_balance"
+	AccountRevenue         = "revenue"
+)
+
+// Payment is one purchase through the payment provider
+type Payment struct {
+	ID                uuid.UUID  `json:"id" gorm:"column:id"`
+	UserID            uuid.UUID  `json:"user_id" gorm:"column:user_id"`
+	Purpose           string     `json:"purpose" gorm:"column:purpose"`
+	ReferenceID       string     `json:"reference_id" gorm:"column:reference_id"`
+	Description       string     `json:"description" gorm:"column:description"`
+	Amount            float64    `json:"amount" gorm:"column:amount"`
+	RefundedAmount    float64    `json:"refunded_amount" gorm:"column:refunded_amount"`
+	Currency          string     `json:"currency" gorm:"column:currency"`
+	Status            string     `json:"status" gorm:"column:status"`
+	Provider          string     `json:"provider" gorm:"column:provider"`
+	CheckoutID        *string    `json:"-" gorm:"column:checkout_id"`
+	ProviderPaymentID *string    `json:"-" gorm:"column:provider_payment_id"`
+	PaidAt            *time.Time `json:"paid_at" gorm:"column:paid_at"`
+	FulfilledAt       *time.Time `json:"fulfilled_at,omitempty" gorm:"column:fulfilled_at"` // When what was bought was delivered
+	CreatedAt         time.Time  `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt         time.Time  `json:"updated_at" gorm:"column:updated_at"`
+}
+
+// TableName overrides the default table name
+func (Payment) TableName() string {
+	return "payments"
+}
+
+// LedgerEntry is one side of a ledger transaction
+type LedgerEntry struct {
+	ID            uuid.UUID `json:"id" gorm:"column:id"`
+	TransactionID uuid.UUID `json:"transaction_id" gorm:"column:transaction_id"`
+	UserID        uuid.UUID `json:"user_id" gorm:"column:user_id"`
+	PaymentID     uuid.UUID `json:"payment_id" gorm:"column:payment_id"`
+	Kind          string    `json:"kind" gorm:"column:kind"`
+	Account       string    `json:"account" gorm:"column:account"`
+	Debit         float64   `json:"debit" gorm:"column:debit"`
+	Credit        float64   `json:"credit" gorm:"column:credit"`
+	Currency      string    `json:"currency" gorm:"column:currency"`
+	Description   string    `json:"description" gorm:"column:description"`
+	CreatedAt     time.Time `json:"created_at" gorm:"column:created_at"`
+}
+
+// TableName overrides the default table name
+func (LedgerEntry) TableName() string {
+	return "ledger_entries"
+}
+
+// LedgerBalance is the net of a user's ledger entries in one currency
+type LedgerBalance struct {
+	Currency string  `json:"currency" gorm:"column:currency"`
+	Charged  float64 `json:"charged" gorm:"column:charged"`
+	Refunded float64 `json:"refunded" gorm:"column:refunded"`
+	Net      float64 `json:"net" gorm:"column:net"`
+}
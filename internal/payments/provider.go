@@ -0,0 +1,100 @@
+package payments
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/yourusername/car-reselling-backend/internal/config"
+)
+
+// Payment providers
+const (
+	ProviderStripe = "stripe"
+)
+
+// Normalized webhook event types
+const (
+	EventPaymentSucceeded = "payment.succeeded"
+	EventPaymentFailed    = "payment.failed"
+	EventPaymentRefunded  = "payment.refunded"
+)
+
+// ErrInvalidSignature is returned for webhooks that are unsigned, wrongly
+// signed or too old
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+// CheckoutRequest asks the provider for a hosted checkout page
+type CheckoutRequest struct {
+	PaymentID   uuid.UUID
+	UserID      uuid.UUID
+	Amount      float64
+	Currency    string
+	Description string
+	SuccessURL  string    // Optional, defaults to the provider's configured URL
+	CancelURL   string    // Optional, defaults to the provider's configured URL
+	ExpiresAt   time.Time // Optional, when the checkout page stops accepting payment
+}
+
+// Checkout is a hosted checkout page the buyer is sent to
+type Checkout struct {
+	ID  string
+	URL string
+}
+
+// RefundRequest refunds part or all of a paid payment
+type RefundRequest struct {
+	PaymentID         uuid.UUID
+	ProviderPaymentID string
+	Amount            float64
+	IdempotencyKey    string // Same key for retries of the same refund
+}
+
+// Refund is a refund accepted by the provider
+type Refund struct {
+	ID     string
+	Amount float64
+	Status string
+}
+
+// WebhookEvent is a verified provider event. Type is one of the Event*
+// constants, or empty for events this API does not act on.
+type WebhookEvent struct {
+	ID                string
+	Type              string
+	ProviderType      string    // The provider's own event type
+	PaymentID         uuid.UUID // From the metadata set at checkout; uuid.Nil if missing
+	CheckoutID        string
+	ProviderPaymentID string
+	Amount            float64 // Amount paid, or total refunded so far for refunds
+	Currency          string
+}
+
+// Provider is a payment service provider
+type Provider interface {
+	Name() string
+	// CreateCheckout creates a hosted checkout page for a payment
+	CreateCheckout(ctx context.Context, req CheckoutRequest) (*Checkout, error)
+	// VerifyWebhook checks a webhook's signature and parses it
+	VerifyWebhook(payload []byte, header http.Header) (*WebhookEvent, error)
+	// Refund refunds a paid payment
+	Refund(ctx context.Context, req RefundRequest) (*Refund, error)
+}
+
+// NewProvider creates the payment provider configured by STRIPE_SECRET_KEY. It
+// returns nil when payments are not configured.
+func NewProvider(cfg *config.Config) (Provider, error) {
+	if cfg.StripeSecretKey == "" {
+		return nil, nil
+	}
+	return NewStripeProvider(StripeConfig{
+		APIURL:        cfg.StripeAPIURL,
+		SecretKey:     cfg.StripeSecretKey,
+		WebhookSecret: cfg.StripeWebhookSecret,
+		SuccessURL:    cfg.PaymentSuccessURL,
+		CancelURL:     cfg.PaymentCancelURL,
+	})
+}
@@ -0,0 +1,282 @@
+package payments
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	appErrors "github.com/yourusername/car-reselling-backend/pkg/errors"
+)
+
+// Repository handles database operations for payments and the ledger
+type Repository struct {
+	db *gorm.DB
+}
+
+// NewRepository creates a new payments repository
+func NewRepository(db *gorm.DB) *Repository {
+	return &Repository{db: db}
+}
+
+// --- Payments ---
+
+// CreatePayment inserts a pending payment
+func (r *Repository) CreatePayment(ctx context.Context, payment *Payment) error {
+	return r.db.WithContext(ctx).Create(payment).Error
+}
+
+// SetCheckout stores the provider's checkout session of a payment
+func (r *Repository) SetCheckout(ctx context.Context, paymentID uuid.UUID, checkoutID string) error {
+	return r.db.WithContext(ctx).Model(&Payment{}).
+		Where("id = ?", paymentID).
+		Updates(map[string]interface{}{"checkout_id": checkoutID, "updated_at": time.Now()}).Error
+}
+
+// FindPayment retrieves a payment by ID
+func (r *Repository) FindPayment(ctx context.Context, paymentID uuid.UUID) (*Payment, error) {
+	var payment Payment
+	err := r.db.WithContext(ctx).Where("id = ?", paymentID).First(&payment).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, appErrors.ErrNotFound
+	}
+	return &payment, err
+}
+
+// LockPayment runs fn with the payment locked until fn returns, so refunds
+// and webhooks for it are applied one at a time. fn's changes are rolled back
+// if it fails.
+func (r *Repository) LockPayment(ctx context.Context, paymentID uuid.UUID, fn func(tx *Repository, payment *Payment) error) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var payment Payment
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", paymentID).First(&payment).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return appErrors.ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+		return fn(&Repository{db: tx}, &payment)
+	})
+}
+
+// FindEventPayment retrieves the payment a webhook event is about, by the
+// payment ID in its metadata or else by the provider's checkout or payment ID
+func (r *Repository) FindEventPayment(ctx context.Context, provider string, event *WebhookEvent) (*Payment, error) {
+	query := r.db.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"})
+	switch {
+	case event.PaymentID != uuid.Nil:
+		query = query.Where("id = ?", event.PaymentID)
+	case event.CheckoutID != "":
+		query = query.Where("provider = ? AND checkout_id = ?", provider, event.CheckoutID)
+	case event.ProviderPaymentID != "":
+		query = query.Where("provider = ? AND provider_payment_id = ?", provider, event.ProviderPaymentID)
+	default:
+		return nil, appErrors.ErrNotFound
+	}
+
+	var payment Payment
+	err := query.First(&payment).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, appErrors.ErrNotFound
+	}
+	return &payment, err
+}
+
+// ListPayments retrieves a user's payments, newest first
+func (r *Repository) ListPayments(ctx context.Context, userID uuid.UUID, page, limit int) ([]Payment, int64, error) {
+	var payments []Payment
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&Payment{}).Where("user_id = ?", userID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := query.Order("created_at DESC, id DESC").
+		Offset((page - 1) * limit).Limit(limit).
+		Find(&payments).Error
+	return payments, total, err
+}
+
+// MarkSucceeded marks a pending payment as paid and books the charge in the
+// ledger. It returns false if the payment was not pending.
+func (r *Repository) MarkSucceeded(ctx context.Context, payment *Payment, providerPaymentID string, paidAt time.Time) (bool, error) {
+	var updated bool
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&Payment{}).
+			Where("id = ? AND status = ?", payment.ID, StatusPending).
+			Updates(map[string]interface{}{
+				"status":              StatusSucceeded,
+				"provider_payment_id": providerPaymentID,
+				"paid_at":             paidAt,
+				"updated_at":          paidAt,
+			})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		updated = true
+		return insertLedger(tx, chargeTransaction(payment, paidAt))
+	})
+	return updated, err
+}
+
+// MarkForReview marks a pending payment whose charge did not match it for
+// review, without booking it. It returns false if the payment was not pending.
+func (r *Repository) MarkForReview(ctx context.Context, paymentID uuid.UUID, providerPaymentID string, paidAt time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&Payment{}).
+		Where("id = ? AND status = ?", paymentID, StatusPending).
+		Updates(map[string]interface{}{
+			"status":              StatusReview,
+			"provider_payment_id": providerPaymentID,
+			"paid_at":             paidAt,
+			"updated_at":          paidAt,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// MarkFulfilled records that what a payment bought was delivered
+func (r *Repository) MarkFulfilled(ctx context.Context, paymentID uuid.UUID, at time.Time) error {
+	return r.db.WithContext(ctx).Model(&Payment{}).
+		Where("id = ?", paymentID).
+		Updates(map[string]interface{}{"fulfilled_at": at, "updated_at": at}).Error
+}
+
+// FindUnfulfilled retrieves paid payments for the given purposes that were not
+// delivered, paid between since and before, oldest first
+func (r *Repository) FindUnfulfilled(ctx context.Context, purposes []string, since, before time.Time, limit int) ([]Payment, error) {
+	var payments []Payment
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND fulfilled_at IS NULL AND purpose IN ? AND paid_at >= ? AND paid_at < ?",
+			StatusSucceeded, purposes, since, before).
+		Order("paid_at ASC").
+		Limit(limit).
+		Find(&payments).Error
+	return payments, err
+}
+
+// MarkFailed marks a pending payment as failed. It returns false if the
+// payment was not pending.
+func (r *Repository) MarkFailed(ctx context.Context, paymentID uuid.UUID) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&Payment{}).
+		Where("id = ? AND status = ?", paymentID, StatusPending).
+		Updates(map[string]interface{}{"status": StatusFailed, "updated_at": time.Now()})
+	return result.RowsAffected > 0, result.Error
+}
+
+// ApplyRefund raises a payment's refunded total to totalRefunded and books the
+// difference in the ledger. Totals at or below what is already recorded are
+// ignored, so the same refund reported twice is only booked once. It returns
+// the amount booked.
+func (r *Repository) ApplyRefund(ctx context.Context, paymentID uuid.UUID, totalRefunded float64) (float64, error) {
+	var booked float64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var payment Payment
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", paymentID).First(&payment).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return appErrors.ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		// Only booked charges can be refunded in the ledger; payments held for
+		// review are settled by hand
+		if payment.Status != StatusSucceeded && payment.Status != StatusRefunded {
+			return nil
+		}
+
+		delta := toMinorUnits(totalRefunded) - toMinorUnits(payment.RefundedAmount)
+		if delta <= 0 {
+			return nil
+		}
+		if toMinorUnits(totalRefunded) > toMinorUnits(payment.Amount) {
+			return fmt.Errorf("refunded total %.2f exceeds payment %s of %.2f", totalRefunded, payment.ID, payment.Amount)
+		}
+
+		status := payment.Status
+		if toMinorUnits(totalRefunded) == toMinorUnits(payment.Amount) {
+			status = StatusRefunded
+		}
+		now := time.Now()
+		if err := tx.Model(&Payment{}).Where("id = ?", paymentID).
+			Updates(map[string]interface{}{
+				"refunded_amount": totalRefunded,
+				"status":          status,
+				"updated_at":      now,
+			}).Error; err != nil {
+			return err
+		}
+
+		booked = fromMinorUnits(delta)
+		return insertLedger(tx, refundTransaction(&payment, booked, now))
+	})
+	return booked, err
+}
+
+// --- Webhook events ---
+
+// HandleEvent runs fn for a webhook event unless the event was already
+// handled. The event is only recorded as handled if fn succeeds, so a failed
+// event is processed again when the provider retries. It returns false for
+// duplicates.
+func (r *Repository) HandleEvent(ctx context.Context, provider, eventID, eventType string, fn func(tx *Repository) error) (bool, error) {
+	var handled bool
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Exec(`
+			INSERT INTO payment_webhook_events (provider, event_id, event_type)
+			VALUES (?, ?, ?)
+			ON CONFLICT (provider, event_id) DO NOTHING
+		`, provider, eventID, eventType)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		handled = true
+		return fn(&Repository{db: tx})
+	})
+	return handled, err
+}
+
+// --- Ledger ---
+
+// ListLedger retrieves a user's ledger entries, newest first
+func (r *Repository) ListLedger(ctx context.Context, userID uuid.UUID, page, limit int) ([]LedgerEntry, int64, error) {
+	var entries []LedgerEntry
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&LedgerEntry{}).Where("user_id = ?", userID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := query.Order("created_at DESC, transaction_id, debit DESC").
+		Offset((page - 1) * limit).Limit(limit).
+		Find(&entries).Error
+	return entries, total, err
+}
+
+// LedgerBalances sums a user's revenue entries per currency
+func (r *Repository) LedgerBalances(ctx context.Context, userID uuid.UUID) ([]LedgerBalance, error) {
+	var balances []LedgerBalance
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT currency,
+			   COALESCE(SUM(credit) FILTER (WHERE kind = ?), 0) as charged,
+			   COALESCE(SUM(debit) FILTER (WHERE kind = ?), 0) as refunded,
+			   COALESCE(SUM(credit - debit), 0) as net
+		FROM ledger_entries
+		WHERE user_id = ? AND account = ?
+		GROUP BY currency
+		ORDER BY currency
+	`, KindCharge, KindRefund, userID, AccountRevenue).Scan(&balances).Error
+	return balances, err
+}
+
+// insertLedger writes a ledger transaction, refusing unbalanced ones
+func insertLedger(tx *gorm.DB, entries []LedgerEntry) error {
+	if !balanced(entries) {
+		return errors.New("ledger transaction does not balance")
+	}
+	return tx.Create(&entries).Error
+}
@@ -0,0 +1,392 @@
+package payments
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/yourusername/car-reselling-backend/internal/audit"
+	appErrors "github.com/yourusername/car-reselling-backend/pkg/errors"
+)
+
+const (
+	// fulfilmentInterval is how often undelivered paid payments are retried
+	fulfilmentInterval = time.Minute
+
+	// fulfilmentGrace leaves a just-paid payment to its webhook before the
+	// worker retries it
+	fulfilmentGrace = time.Minute
+
+	// fulfilmentRetryWindow is how long delivery is retried after payment;
+	// older payments need an admin to look at them
+	fulfilmentRetryWindow = 72 * time.Hour
+
+	// fulfilmentBatch caps the payments retried per run
+	fulfilmentBatch = 100
+)
+
+var (
+	// ErrNotConfigured is returned when no payment provider is configured
+	ErrNotConfigured = errors.New("payments are not configured")
+	// ErrInvalidAmount is returned for checkouts of zero or negative amounts
+	ErrInvalidAmount = errors.New("amount must be greater than zero")
+	// ErrNotRefundable is returned when refunding a payment that was not paid or is fully refunded
+	ErrNotRefundable = errors.New("payment cannot be refunded")
+	// ErrRefundTooLarge is returned when refunding more than is left of a payment
+	ErrRefundTooLarge = errors.New("refund exceeds the amount left to refund")
+)
+
+// Fulfiller delivers what was bought once its payment succeeds, and releases
+// it if the checkout fails or expires unpaid. PaymentSucceeded may be called
+// again for a payment it already delivered and must not deliver it twice.
+type Fulfiller interface {
+	PaymentSucceeded(ctx context.Context, payment *Payment) error
+	PaymentFailed(ctx context.Context, payment *Payment) error
+}
+
+// AuditLogger records refunds
+type AuditLogger interface {
+	Record(ctx context.Context, event audit.Event)
+}
+
+// CheckoutOptions describes a purchase
+type CheckoutOptions struct {
+	Purpose     string // e.g. "boost"; selects the Fulfiller
+	ReferenceID string // ID of the thing being bought
+	Description string // Shown to the buyer at checkout
+	Amount      float64
+	Currency    string
+	SuccessURL  string    // Optional
+	CancelURL   string    // Optional
+	ExpiresAt   time.Time // Optional, when the checkout stops accepting payment
+}
+
+// Service handles payments through the configured provider
+type Service struct {
+	repo       *Repository
+	provider   Provider
+	fulfillers map[string]Fulfiller
+	auditLog   AuditLogger
+}
+
+// NewService creates a new payments service. provider may be nil, in which
+// case payments are disabled.
+func NewService(repo *Repository, provider Provider) *Service {
+	return &Service{
+		repo:       repo,
+		provider:   provider,
+		fulfillers: make(map[string]Fulfiller),
+	}
+}
+
+// SetAuditLogger sets the audit log for refunds
+func (s *Service) SetAuditLogger(l AuditLogger) {
+	s.auditLog = l
+}
+
+// RegisterFulfiller sets who delivers purchases of the given purpose
+func (s *Service) RegisterFulfiller(purpose string, f Fulfiller) {
+	s.fulfillers[purpose] = f
+}
+
+// CreateCheckout records a pending payment and starts a hosted checkout for it.
+// The payment completes when the provider's webhook arrives.
+func (s *Service) CreateCheckout(ctx context.Context, userID uuid.UUID, opts CheckoutOptions) (*CheckoutResponse, error) {
+	if s.provider == nil {
+		return nil, ErrNotConfigured
+	}
+	if toMinorUnits(opts.Amount) <= 0 {
+		return nil, ErrInvalidAmount
+	}
+
+	now := time.Now()
+	payment := &Payment{
+		ID:          uuid.New(),
+		UserID:      userID,
+		Purpose:     opts.Purpose,
+		ReferenceID: opts.ReferenceID,
+		Description: opts.Description,
+		Amount:      opts.Amount,
+		Currency:    strings.ToUpper(opts.Currency),
+		Status:      StatusPending,
+		Provider:    s.provider.Name(),
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := s.repo.CreatePayment(ctx, payment); err != nil {
+		return nil, err
+	}
+
+	checkout, err := s.provider.CreateCheckout(ctx, CheckoutRequest{
+		PaymentID:   payment.ID,
+		UserID:      userID,
+		Amount:      payment.Amount,
+		Currency:    payment.Currency,
+		Description: payment.Description,
+		SuccessURL:  opts.SuccessURL,
+		CancelURL:   opts.CancelURL,
+		ExpiresAt:   opts.ExpiresAt,
+	})
+	if err != nil {
+		if _, markErr := s.repo.MarkFailed(ctx, payment.ID); markErr != nil {
+			log.Printf("Failed to mark payment %s as failed: %v", payment.ID, markErr)
+		}
+		return nil, err
+	}
+
+	if err := s.repo.SetCheckout(ctx, payment.ID, checkout.ID); err != nil {
+		return nil, err
+	}
+	return &CheckoutResponse{PaymentID: payment.ID, CheckoutURL: checkout.URL}, nil
+}
+
+// HandleWebhook verifies and applies a provider webhook. Events are applied
+// at most once; repeats of a handled event are acknowledged and ignored.
+func (s *Service) HandleWebhook(ctx context.Context, payload []byte, header http.Header) error {
+	if s.provider == nil {
+		return ErrNotConfigured
+	}
+	event, err := s.provider.VerifyWebhook(payload, header)
+	if err != nil {
+		return err
+	}
+
+	var paid, failed *Payment
+	_, err = s.repo.HandleEvent(ctx, s.provider.Name(), event.ID, event.ProviderType, func(tx *Repository) error {
+		if event.Type == "" {
+			return nil
+		}
+
+		payment, err := tx.FindEventPayment(ctx, s.provider.Name(), event)
+		if errors.Is(err, appErrors.ErrNotFound) {
+			// Not started through this API, nothing to update
+			log.Printf("Ignoring %s webhook %s for unknown payment", event.ProviderType, event.ID)
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		switch event.Type {
+		case EventPaymentSucceeded:
+			if toMinorUnits(event.Amount) != toMinorUnits(payment.Amount) || !strings.EqualFold(event.Currency, payment.Currency) {
+				// Don't deliver or book a charge that doesn't match the checkout
+				log.Printf("Payment %s held for review: provider reported %.2f %s, expected %.2f %s",
+					payment.ID, event.Amount, event.Currency, payment.Amount, payment.Currency)
+				_, err := tx.MarkForReview(ctx, payment.ID, event.ProviderPaymentID, time.Now())
+				return err
+			}
+			succeeded, err := tx.MarkSucceeded(ctx, payment, event.ProviderPaymentID, time.Now())
+			if err != nil {
+				return err
+			}
+			if succeeded {
+				paid = payment
+			}
+		case EventPaymentFailed:
+			marked, err := tx.MarkFailed(ctx, payment.ID)
+			if err != nil {
+				return err
+			}
+			if marked {
+				failed = payment
+			}
+		case EventPaymentRefunded:
+			// Refunds made through Refund are already booked; this books the
+			// rest (e.g. refunds made in the provider's dashboard)
+			_, err := tx.ApplyRefund(ctx, payment.ID, event.Amount)
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if paid != nil {
+		// The charge is booked; if delivery fails the worker retries it
+		if err := s.fulfill(ctx, paid); err != nil {
+			log.Printf("Failed to fulfil %s payment %s, will retry: %v", paid.Purpose, paid.ID, err)
+		}
+	}
+	if failed != nil {
+		s.release(ctx, failed)
+	}
+	return nil
+}
+
+// Refund refunds part or all of a paid payment (admin). The payment stays
+// locked from reading what is left to booking the refund, so concurrent
+// refunds and refund webhooks can't book against a stale total.
+func (s *Service) Refund(ctx context.Context, adminID, paymentID uuid.UUID, req *RefundPaymentRequest) (*Payment, error) {
+	if s.provider == nil {
+		return nil, ErrNotConfigured
+	}
+
+	var payment *Payment
+	var refund *Refund
+	var amount int64
+	err := s.repo.LockPayment(ctx, paymentID, func(tx *Repository, locked *Payment) error {
+		payment = locked
+		if payment.Status != StatusSucceeded || payment.ProviderPaymentID == nil {
+			return ErrNotRefundable
+		}
+
+		left := toMinorUnits(payment.Amount) - toMinorUnits(payment.RefundedAmount)
+		amount = left
+		if req.Amount != nil {
+			amount = toMinorUnits(*req.Amount)
+		}
+		if amount <= 0 || amount > left {
+			return ErrRefundTooLarge
+		}
+
+		var err error
+		refund, err = s.provider.Refund(ctx, RefundRequest{
+			PaymentID:         payment.ID,
+			ProviderPaymentID: *payment.ProviderPaymentID,
+			Amount:            fromMinorUnits(amount),
+			// Retrying the same refund reuses the key; the next refund of the payment gets a new one
+			IdempotencyKey: fmt.Sprintf("refund-%s-%d-%d", payment.ID, toMinorUnits(payment.RefundedAmount), amount),
+		})
+		if err != nil {
+			return err
+		}
+
+		total := fromMinorUnits(toMinorUnits(payment.RefundedAmount) + amount)
+		if _, err := tx.ApplyRefund(ctx, payment.ID, total); err != nil {
+			// The provider's refund webhook books it later
+			log.Printf("Refund %s of payment %s went through but could not be booked: %v", refund.ID, payment.ID, err)
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.audit(ctx, audit.Event{
+		ActorID:    audit.ActorID(adminID),
+		Action:     audit.ActionPaymentRefunded,
+		TargetType: audit.TargetPayment,
+		TargetID:   payment.ID.String(),
+		Metadata: audit.Metadata{
+			"user_id":   payment.UserID,
+			"amount":    fromMinorUnits(amount),
+			"currency":  payment.Currency,
+			"refund_id": refund.ID,
+			"reason":    req.Reason,
+		},
+	})
+
+	return s.repo.FindPayment(ctx, payment.ID)
+}
+
+// ListPayments returns a page of the user's payments
+func (s *Service) ListPayments(ctx context.Context, userID uuid.UUID, page, limit int) (*PaymentListResponse, error) {
+	payments, total, err := s.repo.ListPayments(ctx, userID, page, limit)
+	if err != nil {
+		return nil, err
+	}
+	if payments == nil {
+		payments = []Payment{}
+	}
+	return &PaymentListResponse{Items: payments, Total: total, Page: page, Limit: limit}, nil
+}
+
+// GetLedger returns a page of a user's ledger and their balances (admin)
+func (s *Service) GetLedger(ctx context.Context, userID uuid.UUID, page, limit int) (*LedgerResponse, error) {
+	entries, total, err := s.repo.ListLedger(ctx, userID, page, limit)
+	if err != nil {
+		return nil, err
+	}
+	balances, err := s.repo.LedgerBalances(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if entries == nil {
+		entries = []LedgerEntry{}
+	}
+	if balances == nil {
+		balances = []LedgerBalance{}
+	}
+	return &LedgerResponse{Items: entries, Balances: balances, Total: total, Page: page, Limit: limit}, nil
+}
+
+// fulfill hands a paid payment to its purpose's fulfiller and records the
+// delivery. The payment stays locked meanwhile, so the webhook and the worker
+// never deliver it at the same time.
+func (s *Service) fulfill(ctx context.Context, payment *Payment) error {
+	f, ok := s.fulfillers[payment.Purpose]
+	if !ok {
+		return fmt.Errorf("no fulfiller for %s payments", payment.Purpose)
+	}
+	return s.repo.LockPayment(ctx, payment.ID, func(tx *Repository, locked *Payment) error {
+		if locked.Status != StatusSucceeded || locked.FulfilledAt != nil {
+			return nil
+		}
+		if err := f.PaymentSucceeded(ctx, locked); err != nil {
+			return err
+		}
+		return tx.MarkFulfilled(ctx, locked.ID, time.Now())
+	})
+}
+
+// RunFulfillmentWorker periodically retries paid payments whose delivery failed
+func (s *Service) RunFulfillmentWorker() {
+	ticker := time.NewTicker(fulfilmentInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		s.RetryFulfillment(context.Background())
+	}
+}
+
+// RetryFulfillment delivers paid payments that were not delivered when their
+// webhook arrived, e.g. because the database or the process failed in between
+func (s *Service) RetryFulfillment(ctx context.Context) {
+	if len(s.fulfillers) == 0 {
+		return
+	}
+	purposes := make([]string, 0, len(s.fulfillers))
+	for purpose := range s.fulfillers {
+		purposes = append(purposes, purpose)
+	}
+
+	now := time.Now()
+	pending, err := s.repo.FindUnfulfilled(ctx, purposes, now.Add(-fulfilmentRetryWindow), now.Add(-fulfilmentGrace), fulfilmentBatch)
+	if err != nil {
+		log.Printf("Failed to find undelivered payments: %v", err)
+		return
+	}
+	for i := range pending {
+		payment := &pending[i]
+		if err := s.fulfill(ctx, payment); err != nil {
+			log.Printf("Failed to fulfil %s payment %s paid at %s: %v",
+				payment.Purpose, payment.ID, payment.PaidAt.Format(time.RFC3339), err)
+		}
+	}
+}
+
+// release tells a failed payment's fulfiller to give up what was held for it
+func (s *Service) release(ctx context.Context, payment *Payment) {
+	f, ok := s.fulfillers[payment.Purpose]
+	if !ok {
+		return
+	}
+	if err := f.PaymentFailed(ctx, payment); err != nil {
+		log.Printf("Failed to release %s payment %s: %v", payment.Purpose, payment.ID, err)
+	}
+}
+
+func (s *Service) audit(ctx context.Context, event audit.Event) {
+	if s.auditLog != nil {
+		s.auditLog.Record(ctx, event)
+	}
+}
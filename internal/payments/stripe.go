@@ -0,0 +1,298 @@
+package payments
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// webhookTolerance is how old a signed webhook may be, against replays
+const webhookTolerance = 5 * time.Minute
+
+// StripeConfig configures StripeProvider
+type StripeConfig struct {
+	APIURL        string // e.g. https://api.stripe.com, or a local mock server
+	SecretKey     string
+	WebhookSecret string
+	SuccessURL    string
+	CancelURL     string
+}
+
+// StripeProvider takes payments through the Stripe API, or any server speaking
+// it (stripe-mock, a test server). It uses hosted Checkout pages; the outcome
+// arrives by webhook.
+type StripeProvider struct {
+	cfg    StripeConfig
+	client *http.Client
+	now    func() time.Time
+}
+
+// NewStripeProvider creates a Stripe provider
+func NewStripeProvider(cfg StripeConfig) (*StripeProvider, error) {
+	if cfg.SecretKey == "" || cfg.WebhookSecret == "" {
+		return nil, fmt.Errorf("stripe payments require STRIPE_SECRET_KEY and STRIPE_WEBHOOK_SECRET")
+	}
+	if cfg.APIURL == "" {
+		cfg.APIURL = "https://api.stripe.com"
+	}
+	cfg.APIURL = strings.TrimRight(cfg.APIURL, "/")
+
+	return &StripeProvider{
+		cfg:    cfg,
+		client: &http.Client{Timeout: 15 * time.Second},
+		now:    time.Now,
+	}, nil
+}
+
+// Name returns the provider name stored with payments
+func (p *StripeProvider) Name() string {
+	return ProviderStripe
+}
+
+// stripeCheckoutSession is the part of a Checkout Session this API reads
+type stripeCheckoutSession struct {
+	ID            string            `json:"id"`
+	URL           string            `json:"url"`
+	PaymentIntent string            `json:"payment_intent"`
+	PaymentStatus string            `json:"payment_status"` // paid, unpaid or no_payment_required
+	AmountTotal   int64             `json:"amount_total"`
+	Currency      string            `json:"currency"`
+	Metadata      map[string]string `json:"metadata"`
+}
+
+// stripeCharge is the part of a Charge this API reads
+type stripeCharge struct {
+	ID             string            `json:"id"`
+	PaymentIntent  string            `json:"payment_intent"`
+	AmountRefunded int64             `json:"amount_refunded"`
+	Currency       string            `json:"currency"`
+	Metadata       map[string]string `json:"metadata"`
+}
+
+// stripeRefund is the part of a Refund this API reads
+type stripeRefund struct {
+	ID     string `json:"id"`
+	Amount int64  `json:"amount"`
+	Status string `json:"status"`
+}
+
+// stripeEvent is a webhook event
+type stripeEvent struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+	Data struct {
+		Object json.RawMessage `json:"object"`
+	} `json:"data"`
+}
+
+// stripeError is the error body of the API
+type stripeError struct {
+	Error struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// CreateCheckout creates a Checkout Session for a one-off payment. The payment
+// ID is stored as metadata on the session and its charge, so webhooks can be
+// matched back, and doubles as the idempotency key.
+func (p *StripeProvider) CreateCheckout(ctx context.Context, req CheckoutRequest) (*Checkout, error) {
+	successURL, cancelURL := req.SuccessURL, req.CancelURL
+	if successURL == "" {
+		successURL = p.cfg.SuccessURL
+	}
+	if cancelURL == "" {
+		cancelURL = p.cfg.CancelURL
+	}
+
+	form := url.Values{}
+	form.Set("mode", "payment")
+	form.Set("success_url", successURL)
+	form.Set("cancel_url", cancelURL)
+	form.Set("client_reference_id", req.UserID.String())
+	form.Set("line_items[0][quantity]", "1")
+	form.Set("line_items[0][price_data][currency]", strings.ToLower(req.Currency))
+	form.Set("line_items[0][price_data][unit_amount]", strconv.FormatInt(toMinorUnits(req.Amount), 10))
+	form.Set("line_items[0][price_data][product_data][name]", req.Description)
+	form.Set("metadata[payment_id]", req.PaymentID.String())
+	form.Set("payment_intent_data[metadata][payment_id]", req.PaymentID.String())
+	if !req.ExpiresAt.IsZero() {
+		form.Set("expires_at", strconv.FormatInt(req.ExpiresAt.Unix(), 10))
+	}
+
+	var session stripeCheckoutSession
+	if err := p.post(ctx, "/v1/checkout/sessions", form, "checkout-"+req.PaymentID.String(), &session); err != nil {
+		return nil, err
+	}
+	return &Checkout{ID: session.ID, URL: session.URL}, nil
+}
+
+// Refund refunds part or all of a payment intent
+func (p *StripeProvider) Refund(ctx context.Context, req RefundRequest) (*Refund, error) {
+	amount := toMinorUnits(req.Amount)
+
+	form := url.Values{}
+	form.Set("payment_intent", req.ProviderPaymentID)
+	form.Set("amount", strconv.FormatInt(amount, 10))
+	form.Set("metadata[payment_id]", req.PaymentID.String())
+
+	var refund stripeRefund
+	if err := p.post(ctx, "/v1/refunds", form, req.IdempotencyKey, &refund); err != nil {
+		return nil, err
+	}
+	return &Refund{ID: refund.ID, Amount: fromMinorUnits(refund.Amount), Status: refund.Status}, nil
+}
+
+// VerifyWebhook checks the Stripe-Signature header ("t=<unix>,v1=<hex>", an
+// HMAC-SHA256 of "<t>.<payload>") and parses the event
+func (p *StripeProvider) VerifyWebhook(payload []byte, header http.Header) (*WebhookEvent, error) {
+	if err := p.verifySignature(payload, header.Get("Stripe-Signature")); err != nil {
+		return nil, err
+	}
+
+	var event stripeEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, fmt.Errorf("stripe: invalid event: %w", err)
+	}
+	result := &WebhookEvent{ID: event.ID, ProviderType: event.Type}
+
+	switch event.Type {
+	case "checkout.session.completed", "checkout.session.async_payment_succeeded",
+		"checkout.session.expired", "checkout.session.async_payment_failed":
+		var session stripeCheckoutSession
+		if err := json.Unmarshal(event.Data.Object, &session); err != nil {
+			return nil, fmt.Errorf("stripe: invalid checkout session: %w", err)
+		}
+		switch event.Type {
+		case "checkout.session.completed":
+			// Delayed payment methods complete the session unpaid; the money
+			// arrives with async_payment_succeeded
+			if session.PaymentStatus == "paid" {
+				result.Type = EventPaymentSucceeded
+			}
+		case "checkout.session.async_payment_succeeded":
+			result.Type = EventPaymentSucceeded
+		default:
+			result.Type = EventPaymentFailed
+		}
+		result.PaymentID = metadataPaymentID(session.Metadata)
+		result.CheckoutID = session.ID
+		result.ProviderPaymentID = session.PaymentIntent
+		result.Amount = fromMinorUnits(session.AmountTotal)
+		result.Currency = strings.ToUpper(session.Currency)
+
+	case "charge.refunded":
+		var charge stripeCharge
+		if err := json.Unmarshal(event.Data.Object, &charge); err != nil {
+			return nil, fmt.Errorf("stripe: invalid charge: %w", err)
+		}
+		result.Type = EventPaymentRefunded
+		result.PaymentID = metadataPaymentID(charge.Metadata)
+		result.ProviderPaymentID = charge.PaymentIntent
+		result.Amount = fromMinorUnits(charge.AmountRefunded)
+		result.Currency = strings.ToUpper(charge.Currency)
+	}
+
+	return result, nil
+}
+
+func (p *StripeProvider) verifySignature(payload []byte, signature string) error {
+	var timestamp string
+	var signatures []string
+	for _, part := range strings.Split(signature, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+	if timestamp == "" || len(signatures) == 0 {
+		return ErrInvalidSignature
+	}
+
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	if age := p.now().Sub(time.Unix(unix, 0)); age > webhookTolerance || age < -webhookTolerance {
+		return ErrInvalidSignature
+	}
+
+	mac := hmac.New(sha256.New, []byte(p.cfg.WebhookSecret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(payload)
+	expected := mac.Sum(nil)
+
+	// Several v1 signatures are sent while the secret is being rolled
+	for _, sig := range signatures {
+		decoded, err := hex.DecodeString(sig)
+		if err == nil && hmac.Equal(decoded, expected) {
+			return nil
+		}
+	}
+	return ErrInvalidSignature
+}
+
+// post sends a form-encoded API request and decodes the JSON response into out
+func (p *StripeProvider) post(ctx context.Context, path string, form url.Values, idempotencyKey string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.cfg.APIURL+path, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+p.cfg.SecretKey)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Idempotency-Key", idempotencyKey)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("stripe: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var apiErr stripeError
+		if err := json.NewDecoder(resp.Body).Decode(&apiErr); err == nil && apiErr.Error.Message != "" {
+			return fmt.Errorf("stripe: %s (%s)", apiErr.Error.Message, apiErr.Error.Type)
+		}
+		return fmt.Errorf("stripe: unexpected status %d", resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("stripe: invalid response: %w", err)
+	}
+	return nil
+}
+
+func metadataPaymentID(metadata map[string]string) uuid.UUID {
+	id, err := uuid.Parse(metadata["payment_id"])
+	if err != nil {
+		return uuid.Nil
+	}
+	return id
+}
+
+// toMinorUnits converts an amount to cents. Only currencies with two decimals
+// are accepted as PAYMENT_CURRENCY (see config).
+func toMinorUnits(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
+
+func fromMinorUnits(amount int64) float64 {
+	return float64(amount) / 100
+}
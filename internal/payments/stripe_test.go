@@ -0,0 +1,150 @@
+package payments
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func newTestStripe(t *testing.T, handler http.HandlerFunc) *StripeProvider {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	p, err := NewStripeProvider(StripeConfig{
+		APIURL:        server.URL,
+		SecretKey:     "sk_test_123",
+		WebhookSecret: "whsec_test",
+		SuccessURL:    "https://example.com/success",
+		CancelURL:     "https://example.com/cancel",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestStripeCreateCheckout(t *testing.T) {
+	paymentID := uuid.New()
+
+	p := newTestStripe(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/checkout/sessions" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer sk_test_123" {
+			t.Errorf("Authorization = %q", got)
+		}
+		if got := r.Header.Get("Idempotency-Key"); got != "checkout-"+paymentID.String() {
+			t.Errorf("Idempotency-Key = %q", got)
+		}
+		if err := r.ParseForm(); err != nil {
+			t.Error(err)
+			return
+		}
+		want := map[string]string{
+			"mode":                                   "payment",
+			"success_url":                            "https://example.com/success",
+			"line_items[0][price_data][currency]":    "usd",
+			"line_items[0][price_data][unit_amount]": "1999",
+			"metadata[payment_id]":                   paymentID.String(),
+		}
+		for key, value := range want {
+			if got := r.PostForm.Get(key); got != value {
+				t.Errorf("%s = %q, want %q", key, got, value)
+			}
+		}
+		fmt.Fprint(w, `{"id": "cs_test_1", "url": "https://checkout.example.com/cs_test_1"}`)
+	})
+
+	checkout, err := p.CreateCheckout(context.Background(), CheckoutRequest{
+		PaymentID:   paymentID,
+		UserID:      uuid.New(),
+		Amount:      19.99,
+		Currency:    "USD",
+		Description: "Listing boost",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if checkout.ID != "cs_test_1" || checkout.URL != "https://checkout.example.com/cs_test_1" {
+		t.Errorf("unexpected checkout %+v", checkout)
+	}
+}
+
+func TestStripeRefundError(t *testing.T) {
+	p := newTestStripe(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"error": {"type": "invalid_request_error", "message": "Charge has already been refunded."}}`)
+	})
+
+	_, err := p.Refund(context.Background(), RefundRequest{PaymentID: uuid.New(), ProviderPaymentID: "pi_1", Amount: 5})
+	if err == nil || !strings.Contains(err.Error(), "already been refunded") {
+		t.Fatalf("expected the API error message, got %v", err)
+	}
+}
+
+func TestStripeVerifyWebhook(t *testing.T) {
+	p := newTestStripe(t, func(w http.ResponseWriter, r *http.Request) {})
+	now := time.Unix(1700000000, 0)
+	p.now = func() time.Time { return now }
+
+	paymentID := uuid.New()
+	payload := []byte(fmt.Sprintf(`{"id": "evt_1", "type": "checkout.session.completed", "data": {"object": {
+		"id": "cs_1", "payment_intent": "pi_1", "payment_status": "paid", "amount_total": 1500, "currency": "usd",
+		"metadata": {"payment_id": %q}}}}`, paymentID))
+
+	sign := func(secret string, ts time.Time, body []byte) http.Header {
+		mac := hmac.New(sha256.New, []byte(secret))
+		fmt.Fprintf(mac, "%d.%s", ts.Unix(), body)
+		header := http.Header{}
+		header.Set("Stripe-Signature", fmt.Sprintf("t=%d,v1=%s", ts.Unix(), hex.EncodeToString(mac.Sum(nil))))
+		return header
+	}
+
+	event, err := p.VerifyWebhook(payload, sign("whsec_test", now, payload))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.Type != EventPaymentSucceeded || event.PaymentID != paymentID ||
+		event.ProviderPaymentID != "pi_1" || event.Amount != 15 || event.Currency != "USD" {
+		t.Errorf("unexpected event %+v", event)
+	}
+
+	// A completed session paid by a delayed method is not paid yet
+	unpaid := []byte(fmt.Sprintf(`{"id": "evt_2", "type": "checkout.session.completed", "data": {"object": {
+		"id": "cs_2", "payment_status": "unpaid", "amount_total": 1500, "currency": "usd",
+		"metadata": {"payment_id": %q}}}}`, paymentID))
+	if event, err := p.VerifyWebhook(unpaid, sign("whsec_test", now, unpaid)); err != nil || event.Type != "" {
+		t.Errorf("unpaid session: expected no event type, got %+v, %v", event, err)
+	}
+	settled := []byte(strings.Replace(string(unpaid), "checkout.session.completed", "checkout.session.async_payment_succeeded", 1))
+	if event, err := p.VerifyWebhook(settled, sign("whsec_test", now, settled)); err != nil || event.Type != EventPaymentSucceeded {
+		t.Errorf("async payment succeeded: expected %s, got %+v, %v", EventPaymentSucceeded, event, err)
+	}
+
+	invalid := map[string]http.Header{
+		"wrong secret": sign("whsec_other", now, payload),
+		"too old":      sign("whsec_test", now.Add(-10*time.Minute), payload),
+		"unsigned":     {},
+	}
+	for name, header := range invalid {
+		if _, err := p.VerifyWebhook(payload, header); !errors.Is(err, ErrInvalidSignature) {
+			t.Errorf("%s: expected ErrInvalidSignature, got %v", name, err)
+		}
+	}
+
+	tampered := []byte(strings.Replace(string(payload), "1500", "1", 1))
+	if _, err := p.VerifyWebhook(tampered, sign("whsec_test", now, payload)); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("tampered payload: expected ErrInvalidSignature, got %v", err)
+	}
+}
@@ -0,0 +1,15 @@
+package savedsearch
+
+// SavedSearchRequest is the payload for creating or replacing a saved search
+// @Description Saved search criteria and alert frequency
+type SavedSearchRequest struct {
+	Name      string  `json:"name" binding:"required,max=100" example:"Camry under 20k"`
+	Make      string  `json:"make" binding:"omitempty,max=50" example:"Toyota"`
+	Model     string  `json:"model" binding:"omitempty,max=50" example:"Camry"`
+	MinPrice  float64 `json:"min_price" binding:"omitempty,min=0" example:"5000"`
+	MaxPrice  float64 `json:"max_price" binding:"omitempty,min=0" example:"20000"`
+	City      string  `json:"city" binding:"omitempty,max=100" example:"New York"`
+	Condition string  `json:"condition" binding:"omitempty,oneof=excellent good fair" example:"good"`
+	Frequency string  `json:"frequency" binding:"omitempty,oneof=instant daily" example:"instant"`
+	IsActive  *bool   `json:"is_active" example:"true"`
+}
@@ -0,0 +1,205 @@
+package savedsearch
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	appErrors "github.com/yourusername/car-reselling-backend/pkg/errors"
+)
+
+// Handler handles HTTP requests for saved searches
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a new saved search handler
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// RegisterRoutes registers saved search routes
+func (h *Handler) RegisterRoutes(router *gin.RouterGroup, authMiddleware gin.HandlerFunc) {
+	searches := router.Group("/saved-searches")
+	searches.Use(authMiddleware)
+	{
+		searches.GET("", h.List)
+		searches.POST("", h.Create)
+		searches.GET("/:id", h.Get)
+		searches.PUT("/:id", h.Update)
+		searches.DELETE("/:id", h.Delete)
+	}
+}
+
+// List returns the authenticated user's saved searches
+// @Summary List saved searches
+// @Tags saved-searches
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} SavedSearch
+// @Failure 401 {object} map[string]string
+// @Router /api/saved-searches [get]
+func (h *Handler) List(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	searches, err := h.service.List(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if searches == nil {
+		searches = []SavedSearch{}
+	}
+
+	c.JSON(http.StatusOK, searches)
+}
+
+// Create saves a new search
+// @Summary Create saved search
+// @Description Save listing search criteria and get alerted when a matching car is posted
+// @Tags saved-searches
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body SavedSearchRequest true "Search criteria"
+// @Success 201 {object} SavedSearch
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /api/saved-searches [post]
+func (h *Handler) Create(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	var req SavedSearchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	search, err := h.service.Create(c.Request.Context(), userID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, search)
+}
+
+// Get returns a single saved search
+// @Summary Get saved search
+// @Tags saved-searches
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Saved search ID"
+// @Success 200 {object} SavedSearch
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/saved-searches/{id} [get]
+func (h *Handler) Get(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid saved search ID"})
+		return
+	}
+
+	search, err := h.service.Get(c.Request.Context(), userID, id)
+	if err != nil {
+		appErrors.HandleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, search)
+}
+
+// Update replaces a saved search
+// @Summary Update saved search
+// @Tags saved-searches
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Saved search ID"
+// @Param request body SavedSearchRequest true "Search criteria"
+// @Success 200 {object} SavedSearch
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/saved-searches/{id} [put]
+func (h *Handler) Update(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid saved search ID"})
+		return
+	}
+
+	var req SavedSearchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	search, err := h.service.Update(c.Request.Context(), userID, id, &req)
+	if err != nil {
+		if err == appErrors.ErrNotFound {
+			appErrors.HandleError(c, err)
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, search)
+}
+
+// Delete removes a saved search
+// @Summary Delete saved search
+// @Tags saved-searches
+// @Security BearerAuth
+// @Param id path string true "Saved search ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/saved-searches/{id} [delete]
+func (h *Handler) Delete(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid saved search ID"})
+		return
+	}
+
+	if err := h.service.Delete(c.Request.Context(), userID, id); err != nil {
+		appErrors.HandleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// getUserID extracts the authenticated user ID from context
+func getUserID(c *gin.Context) (uuid.UUID, bool) {
+	userID, err := uuid.Parse(c.GetString("userID"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return uuid.Nil, false
+	}
+	return userID, true
+}
@@ -0,0 +1,49 @@
+package savedsearch
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Alert frequencies
+const (
+	FrequencyInstant = "instant"
+	FrequencyDaily   = "daily"
+)
+
+// NotificationTypeNewListingMatch is the notification type for saved search alerts
+const NotificationTypeNewListingMatch = "new_listing_match"
+
+// SavedSearch is a stored listing query a user wants to be alerted about.
+// Empty strings and zero prices mean "any".
+type SavedSearch struct {
+	ID             uuid.UUID  `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	UserID         uuid.UUID  `json:"user_id" gorm:"type:uuid;index;not null"`
+	Name           string     `json:"name" gorm:"type:varchar(100);not null"`
+	Make           string     `json:"make"`
+	Model          string     `json:"model"`
+	MinPrice       float64    `json:"min_price"`
+	MaxPrice       float64    `json:"max_price"`
+	City           string     `json:"city"`
+	Condition      string     `json:"condition"`
+	Frequency      string     `json:"frequency" gorm:"default:instant"` // instant, daily
+	IsActive       bool       `json:"is_active"`
+	LastNotifiedAt *time.Time `json:"last_notified_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// Match records that a listing matched a saved search
+type Match struct {
+	ID            uuid.UUID  `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	SavedSearchID uuid.UUID  `json:"saved_search_id" gorm:"type:uuid;index"`
+	UserID        uuid.UUID  `json:"user_id" gorm:"type:uuid"`
+	CarID         uuid.UUID  `json:"car_id" gorm:"type:uuid"`
+	NotifiedAt    *time.Time `json:"notified_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// Table name overrides for GORM
+func (SavedSearch) TableName() string { return "saved_searches" }
+func (Match) TableName() string       { return "saved_search_matches" }
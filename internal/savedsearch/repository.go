@@ -0,0 +1,135 @@
+package savedsearch
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	appErrors "github.com/yourusername/car-reselling-backend/pkg/errors"
+)
+
+// Repository handles database operations for saved searches
+type Repository struct {
+	db *gorm.DB
+}
+
+// NewRepository creates a new saved search repository
+func NewRepository(db *gorm.DB) *Repository {
+	return &Repository{db: db}
+}
+
+// --- Saved Search Operations ---
+
+// Create saves a new saved search
+func (r *Repository) Create(ctx context.Context, search *SavedSearch) error {
+	return r.db.WithContext(ctx).Create(search).Error
+}
+
+// Update saves changes to a saved search
+func (r *Repository) Update(ctx context.Context, search *SavedSearch) error {
+	return r.db.WithContext(ctx).Save(search).Error
+}
+
+// FindByIDForUser retrieves a saved search only if it belongs to the user
+func (r *Repository) FindByIDForUser(ctx context.Context, userID, id uuid.UUID) (*SavedSearch, error) {
+	var search SavedSearch
+	err := r.db.WithContext(ctx).Where("id = ? AND user_id = ?", id, userID).First(&search).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, appErrors.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &search, nil
+}
+
+// FindByUserID retrieves all saved searches for a user
+func (r *Repository) FindByUserID(ctx context.Context, userID uuid.UUID) ([]SavedSearch, error) {
+	var searches []SavedSearch
+	err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Find(&searches).Error
+	return searches, err
+}
+
+// CountByUserID returns how many saved searches a user has
+func (r *Repository) CountByUserID(ctx context.Context, userID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&SavedSearch{}).Where("user_id = ?", userID).Count(&count).Error
+	return count, err
+}
+
+// DeleteForUser removes a saved search only if it belongs to the user
+func (r *Repository) DeleteForUser(ctx context.Context, userID, id uuid.UUID) error {
+	result := r.db.WithContext(ctx).Where("id = ? AND user_id = ?", id, userID).Delete(&SavedSearch{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return appErrors.ErrNotFound
+	}
+	return nil
+}
+
+// --- Matching Operations ---
+
+// FindMatching returns active saved searches (excluding the seller's own) whose criteria accept a listing
+func (r *Repository) FindMatching(ctx context.Context, sellerID uuid.UUID, make, model, city, condition string, price float64) ([]SavedSearch, error) {
+	var searches []SavedSearch
+	err := r.db.WithContext(ctx).
+		Where("is_active = ? AND user_id != ?", true, sellerID).
+		Where("make = '' OR make = ?", make).
+		Where("model = '' OR model = ?", model).
+		Where("city = '' OR city = ?", city).
+		Where("condition = '' OR condition = ?", condition).
+		Where("min_price = 0 OR min_price <= ?", price).
+		Where("max_price = 0 OR max_price >= ?", price).
+		Find(&searches).Error
+	return searches, err
+}
+
+// RecordMatch stores a match unless the user was already matched with this car.
+// Returns true if the match is new.
+func (r *Repository) RecordMatch(ctx context.Context, match *Match) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(match)
+	return result.RowsAffected > 0, result.Error
+}
+
+// FindDigestDue returns daily searches with pending matches that were not notified in the last period
+func (r *Repository) FindDigestDue(ctx context.Context, period time.Duration) ([]SavedSearch, error) {
+	var searches []SavedSearch
+	err := r.db.WithContext(ctx).
+		Where("is_active = ? AND frequency = ?", true, FrequencyDaily).
+		Where("last_notified_at IS NULL OR last_notified_at <= ?", time.Now().Add(-period)).
+		Where("EXISTS (SELECT 1 FROM saved_search_matches m WHERE m.saved_search_id = saved_searches.id AND m.notified_at IS NULL)").
+		Find(&searches).Error
+	return searches, err
+}
+
+// ClaimPendingMatches atomically marks a search's pending matches as notified and
+// returns their car IDs. Safe to call from several API replicas at once.
+func (r *Repository) ClaimPendingMatches(ctx context.Context, savedSearchID uuid.UUID) ([]uuid.UUID, error) {
+	var carIDs []uuid.UUID
+	err := r.db.WithContext(ctx).Raw(`
+		UPDATE saved_search_matches
+		SET notified_at = NOW()
+		WHERE saved_search_id = ? AND notified_at IS NULL
+		RETURNING car_id
+	`, savedSearchID).Scan(&carIDs).Error
+	return carIDs, err
+}
+
+// MarkNotified records when a saved search last sent an alert
+func (r *Repository) MarkNotified(ctx context.Context, savedSearchID uuid.UUID, at time.Time) error {
+	return r.db.WithContext(ctx).
+		Model(&SavedSearch{}).
+		Where("id = ?", savedSearchID).
+		Update("last_notified_at", at).Error
+}
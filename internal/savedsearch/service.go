@@ -0,0 +1,259 @@
+package savedsearch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/yourusername/car-reselling-backend/internal/listing"
+)
+
+const (
+	// maxSavedSearchesPerUser caps how many searches one user can save
+	maxSavedSearchesPerUser = 20
+
+	// digestPeriod is how often a daily search may send its digest
+	digestPeriod = 24 * time.Hour
+
+	// digestCheckInterval is how often the digest worker looks for due searches
+	digestCheckInterval = time.Hour
+)
+
+// NotificationSender is the subset of the notification service used for alerts
+type NotificationSender interface {
+	CreateAndSendBulk(ctx context.Context, userIDs []uuid.UUID, title, message, notifType, imageURL string, data map[string]interface{}) error
+}
+
+// Service handles saved search business logic
+type Service struct {
+	repo         *Repository
+	notification NotificationSender
+}
+
+// NewService creates a new saved search service
+func NewService(repo *Repository, notification NotificationSender) *Service {
+	return &Service{
+		repo:         repo,
+		notification: notification,
+	}
+}
+
+// --- CRUD Operations ---
+
+// Create stores a new saved search for a user
+func (s *Service) Create(ctx context.Context, userID uuid.UUID, req *SavedSearchRequest) (*SavedSearch, error) {
+	if err := validateRequest(req); err != nil {
+		return nil, err
+	}
+
+	count, err := s.repo.CountByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= maxSavedSearchesPerUser {
+		return nil, fmt.Errorf("saved search limit reached: max %d per user", maxSavedSearchesPerUser)
+	}
+
+	search := newSavedSearch(userID, req)
+	if err := s.repo.Create(ctx, search); err != nil {
+		return nil, err
+	}
+	return search, nil
+}
+
+// List returns all saved searches for a user
+func (s *Service) List(ctx context.Context, userID uuid.UUID) ([]SavedSearch, error) {
+	return s.repo.FindByUserID(ctx, userID)
+}
+
+// Get returns a saved search owned by the user
+func (s *Service) Get(ctx context.Context, userID, id uuid.UUID) (*SavedSearch, error) {
+	return s.repo.FindByIDForUser(ctx, userID, id)
+}
+
+// Update replaces the criteria of a saved search owned by the user
+func (s *Service) Update(ctx context.Context, userID, id uuid.UUID, req *SavedSearchRequest) (*SavedSearch, error) {
+	if err := validateRequest(req); err != nil {
+		return nil, err
+	}
+
+	search, err := s.repo.FindByIDForUser(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	applyRequest(search, req)
+	if err := s.repo.Update(ctx, search); err != nil {
+		return nil, err
+	}
+	return search, nil
+}
+
+// Delete removes a saved search owned by the user
+func (s *Service) Delete(ctx context.Context, userID, id uuid.UUID) error {
+	return s.repo.DeleteForUser(ctx, userID, id)
+}
+
+// validateRequest checks cross-field rules not covered by binding tags
+func validateRequest(req *SavedSearchRequest) error {
+	if req.MaxPrice > 0 && req.MinPrice > req.MaxPrice {
+		return errors.New("min_price cannot be greater than max_price")
+	}
+	return nil
+}
+
+// newSavedSearch builds a saved search from a request. Searches start active
+// unless the request says otherwise.
+func newSavedSearch(userID uuid.UUID, req *SavedSearchRequest) *SavedSearch {
+	search := &SavedSearch{UserID: userID, IsActive: true}
+	applyRequest(search, req)
+	return search
+}
+
+// applyRequest copies request fields onto a saved search
+func applyRequest(search *SavedSearch, req *SavedSearchRequest) {
+	search.Name = req.Name
+	search.Make = req.Make
+	search.Model = req.Model
+	search.MinPrice = req.MinPrice
+	search.MaxPrice = req.MaxPrice
+	search.City = req.City
+	search.Condition = req.Condition
+
+	search.Frequency = req.Frequency
+	if search.Frequency == "" {
+		search.Frequency = FrequencyInstant
+	}
+	if req.IsActive != nil {
+		search.IsActive = *req.IsActive
+	}
+}
+
+// --- Matching ---
+
+// MatchNewListing alerts users whose saved searches match a newly active listing.
+// Implements listing.ListingMatcher. Each user is alerted at most once per car.
+func (s *Service) MatchNewListing(ctx context.Context, car *listing.Car) {
+	if car.Status != listing.CarStatusActive {
+		return
+	}
+
+	searches, err := s.repo.FindMatching(ctx, car.SellerID, car.Make, car.Model, car.City, car.Condition, car.Price)
+	if err != nil {
+		log.Printf("Failed to match saved searches for car %s: %v", car.ID, err)
+		return
+	}
+
+	var instantUsers []uuid.UUID
+	for _, search := range searches {
+		match := &Match{
+			SavedSearchID: search.ID,
+			UserID:        search.UserID,
+			CarID:         car.ID,
+		}
+		if search.Frequency == FrequencyInstant {
+			now := time.Now()
+			match.NotifiedAt = &now
+		}
+
+		isNew, err := s.repo.RecordMatch(ctx, match)
+		if err != nil {
+			log.Printf("Failed to record saved search match %s/%s: %v", search.ID, car.ID, err)
+			continue
+		}
+		if !isNew || search.Frequency != FrequencyInstant {
+			continue
+		}
+
+		instantUsers = append(instantUsers, search.UserID)
+		if err := s.repo.MarkNotified(ctx, search.ID, *match.NotifiedAt); err != nil {
+			log.Printf("Failed to update saved search %s: %v", search.ID, err)
+		}
+	}
+
+	if len(instantUsers) == 0 || s.notification == nil {
+		return
+	}
+
+	var carImage string
+	if len(car.Images) > 0 {
+		carImage = car.Images[0]
+	}
+
+	title := "New match for your saved search 🚗"
+	body := fmt.Sprintf("%s - $%.0f in %s", car.Title, car.Price, car.City)
+	data := map[string]interface{}{
+		"car_id":    car.ID.String(),
+		"car_title": car.Title,
+		"price":     car.Price,
+	}
+
+	if err := s.notification.CreateAndSendBulk(ctx, instantUsers, title, body, NotificationTypeNewListingMatch, carImage, data); err != nil {
+		log.Printf("Failed to send saved search alerts for car %s: %v", car.ID, err)
+	}
+}
+
+// --- Daily Digest ---
+
+// RunDigestWorker periodically sends digests for daily saved searches
+func (s *Service) RunDigestWorker() {
+	ticker := time.NewTicker(digestCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		s.SendDueDigests(context.Background())
+		<-ticker.C
+	}
+}
+
+// SendDueDigests sends one digest per daily saved search with pending matches
+func (s *Service) SendDueDigests(ctx context.Context) {
+	searches, err := s.repo.FindDigestDue(ctx, digestPeriod)
+	if err != nil {
+		log.Printf("Failed to find due saved search digests: %v", err)
+		return
+	}
+
+	for _, search := range searches {
+		carIDs, err := s.repo.ClaimPendingMatches(ctx, search.ID)
+		if err != nil {
+			log.Printf("Failed to claim matches for saved search %s: %v", search.ID, err)
+			continue
+		}
+		if len(carIDs) == 0 {
+			continue // Another replica already sent it
+		}
+
+		if err := s.repo.MarkNotified(ctx, search.ID, time.Now()); err != nil {
+			log.Printf("Failed to update saved search %s: %v", search.ID, err)
+		}
+
+		if s.notification == nil {
+			continue
+		}
+
+		ids := make([]string, len(carIDs))
+		for i, id := range carIDs {
+			ids[i] = id.String()
+		}
+
+		title := "Your daily car matches"
+		body := fmt.Sprintf("%d new cars match \"%s\"", len(carIDs), search.Name)
+		if len(carIDs) == 1 {
+			body = fmt.Sprintf("1 new car matches \"%s\"", search.Name)
+		}
+		data := map[string]interface{}{
+			"saved_search_id": search.ID.String(),
+			"car_ids":         strings.Join(ids, ","),
+		}
+
+		if err := s.notification.CreateAndSendBulk(ctx, []uuid.UUID{search.UserID}, title, body, NotificationTypeNewListingMatch, "", data); err != nil {
+			log.Printf("Failed to send digest for saved search %s: %v", search.ID, err)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package savedsearch
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/logger"
+)
+
+// newDryRunRepository returns a repository whose database only builds statements.
+// The is_active values each INSERT or UPDATE would write are passed to record.
+func newDryRunRepository(t *testing.T, record func(values []bool)) *Repository {
+	t.Helper()
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:                 true,
+		SkipDefaultTransaction: true,
+		DisableAutomaticPing:   true,
+		Logger:                 logger.Discard,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	capture := func(tx *gorm.DB) { record(columnValues(tx.Statement, "is_active")) }
+	db.Callback().Create().After("gorm:create").Register("test:capture", capture)
+	db.Callback().Update().After("gorm:update").Register("test:capture", capture)
+	return NewRepository(db)
+}
+
+// columnValues returns the boolean values a statement writes to column: one
+// per inserted row, or the value it is SET to
+func columnValues(stmt *gorm.Statement, column string) []bool {
+	var values []bool
+	if c, ok := stmt.Clauses["VALUES"]; ok {
+		if insert, ok := c.Expression.(clause.Values); ok {
+			for i, col := range insert.Columns {
+				if col.Name != column {
+					continue
+				}
+				for _, row := range insert.Values {
+					if b, ok := row[i].(bool); ok {
+						values = append(values, b)
+					}
+				}
+			}
+		}
+	}
+	if c, ok := stmt.Clauses["SET"]; ok {
+		if set, ok := c.Expression.(clause.Set); ok {
+			for _, assignment := range set {
+				if b, ok := assignment.Value.(bool); ok && assignment.Column.Name == column {
+					values = append(values, b)
+				}
+			}
+		}
+	}
+	return values
+}
+
+func TestIsActiveIsSaved(t *testing.T) {
+	ctx := context.Background()
+	inactive, active := false, true
+
+	tests := []struct {
+		name     string
+		isActive *bool
+		want     bool
+	}{
+		{"omitted", nil, true},
+		{"inactive", &inactive, false},
+		{"active", &active, true},
+	}
+
+	for _, tt := range tests {
+		var saved []bool
+		repo := newDryRunRepository(t, func(values []bool) { saved = values })
+
+		search := newSavedSearch(uuid.New(), &SavedSearchRequest{Name: "SUVs", IsActive: tt.isActive})
+		if err := repo.Create(ctx, search); err != nil {
+			t.Fatalf("%s: create: %v", tt.name, err)
+		}
+		if len(saved) != 1 || saved[0] != tt.want {
+			t.Errorf("%s: create stored is_active %v, want %v", tt.name, saved, tt.want)
+		}
+
+		flipped := !tt.want
+		saved = nil
+		applyRequest(search, &SavedSearchRequest{Name: "SUVs", IsActive: &flipped})
+		if err := repo.Update(ctx, search); err != nil {
+			t.Fatalf("%s: update: %v", tt.name, err)
+		}
+		if len(saved) != 1 || saved[0] != flipped {
+			t.Errorf("%s: update stored is_active %v, want %v", tt.name, saved, flipped)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package subscription
+
+import "time"
+
+// MyPlanResponse is the current user's plan with usage and remaining allowance
+// @Description Current plan, its quotas, what is used and what is left
+type MyPlanResponse struct {
+	Plan      Plan       `json:"plan"`
+	ExpiresAt *time.Time `json:"expires_at"` // When the plan falls back to free; nil for no end date
+	Usage     Usage      `json:"usage"`
+	Remaining Remaining  `json:"remaining"`
+}
+
+// Remaining is what a seller can still do under their plan
+type Remaining struct {
+	DailyPosts     int `json:"daily_posts"`
+	ActiveListings int `json:"active_listings"`
+	FeaturedSlots  int `json:"featured_slots"`
+}
+
+// UpdatePlanRequest is the payload for changing a plan's quotas (admin)
+// @Description Fields to change; omitted fields keep their value
+type UpdatePlanRequest struct {
+	Name             *string  `json:"name" binding:"omitempty,min=1,max=100" example:"Pro Seller"`
+	DailyPosts       *int     `json:"daily_posts" binding:"omitempty,min=0" example:"20"`
+	ActiveListings   *int     `json:"active_listings" binding:"omitempty,min=0" example:"100"`
+	ImagesPerListing *int     `json:"images_per_listing" binding:"omitempty,min=1" example:"20"`
+	FeaturedSlots    *int     `json:"featured_slots" binding:"omitempty,min=0" example:"3"`
+	Price            *float64 `json:"price" binding:"omitempty,min=0" example:"29"` // 0 takes the plan off sale
+}
+
+// AssignPlanRequest is the payload for putting a user on a plan (admin)
+// @Description Plan to assign and optional end date
+type AssignPlanRequest struct {
+	PlanID    string     `json:"plan_id" binding:"required" example:"pro_seller"`
+	ExpiresAt *time.Time `json:"expires_at" example:"2027-01-01T00:00:00Z"` // Omit for no end date
+}
@@ -0,0 +1,203 @@
+package subscription
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	appErrors "github.com/yourusername/car-reselling-backend/pkg/errors"
+)
+
+// Handler handles HTTP requests for seller plans
+type Handler struct {
+	service *Service
+}
+
+// NewHandler creates a new subscription handler
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+// RegisterRoutes registers the plan list and the current user's plan
+func (h *Handler) RegisterRoutes(router *gin.RouterGroup, authMiddleware gin.HandlerFunc) {
+	router.GET("/plans", h.ListPlans)
+	router.GET("/auth/me/plan", authMiddleware, h.GetMyPlan)
+	router.POST("/plans/:id/purchase", authMiddleware, h.PurchasePlan)
+}
+
+// RegisterAdminRoutes registers plan management routes on an admin-only group
+func (h *Handler) RegisterAdminRoutes(admin *gin.RouterGroup) {
+	admin.PUT("/plans/:id", h.UpdatePlan)
+	admin.PUT("/users/:id/plan", h.AssignPlan)
+}
+
+// ListPlans lists the available seller plans
+// @Summary List plans
+// @Description Seller plans with their quotas: new listings per day, active listings, images per listing and featured slots
+// @Tags plans
+// @Produce json
+// @Success 200 {array} Plan
+// @Router /api/plans [get]
+func (h *Handler) ListPlans(c *gin.Context) {
+	plans, err := h.service.ListPlans(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, plans)
+}
+
+// GetMyPlan returns the current user's plan and remaining allowance
+// @Summary Get my plan
+// @Description The current plan and its quotas, what is used today and in total, and what is left. Daily posts reset at midnight.
+// @Tags plans
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} MyPlanResponse
+// @Failure 401 {object} map[string]string
+// @Router /api/auth/me/plan [get]
+func (h *Handler) GetMyPlan(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	plan, err := h.service.GetMyPlan(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, plan)
+}
+
+// UpdatePlan changes a plan's quotas
+// @Summary Update plan quotas
+// @Description Change a plan's name or quotas. Applies to every user on the plan; existing listings over a lowered quota stay up.
+// @Tags plans
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Plan ID (free, pro_seller, dealer)"
+// @Param request body UpdatePlanRequest true "Fields to change"
+// @Success 200 {object} Plan
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/admin/plans/{id} [put]
+func (h *Handler) UpdatePlan(c *gin.Context) {
+	adminID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	var req UpdatePlanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	plan, err := h.service.UpdatePlan(c.Request.Context(), adminID, c.Param("id"), &req)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, plan)
+}
+
+// AssignPlan puts a user on a plan
+// @Summary Assign a user's plan
+// @Description Put a user on a plan, optionally until a date after which they fall back to the free plan
+// @Tags plans
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param request body AssignPlanRequest true "Plan and end date"
+// @Success 200 {object} Subscription
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/admin/users/{id}/plan [put]
+func (h *Handler) AssignPlan(c *gin.Context) {
+	adminID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var req AssignPlanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	sub, err := h.service.AssignPlan(c.Request.Context(), adminID, userID, &req)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, sub)
+}
+
+// PurchasePlan starts buying a plan
+// @Summary Buy a plan
+// @Description Start a checkout for 30 days of a paid plan. The plan starts once payment succeeds; buying the plan you are on extends it by 30 days.
+// @Tags plans
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Plan ID"
+// @Success 201 {object} payments.CheckoutResponse
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string "Plan not for sale, or already held with no end date"
+// @Failure 503 {object} map[string]string "Payments not available"
+// @Router /api/plans/{id}/purchase [post]
+func (h *Handler) PurchasePlan(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	checkout, err := h.service.PurchasePlan(c.Request.Context(), userID, c.Param("id"))
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, checkout)
+}
+
+func handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, appErrors.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrUnknownPlan), errors.Is(err, ErrExpiryInPast):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrNotForSale), errors.Is(err, ErrAlreadyOnPlan):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrPaymentsUnavailable):
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+func getUserID(c *gin.Context) (uuid.UUID, bool) {
+	userID, err := uuid.Parse(c.GetString("userID"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID"})
+		return uuid.Nil, false
+	}
+	return userID, true
+}
@@ -0,0 +1,68 @@
+package subscription
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/yourusername/car-reselling-backend/internal/listing"
+)
+
+// PlanPeriod is how long one purchase of a plan lasts
+const PlanPeriod = 30 * 24 * time.Hour
+
+// Plan IDs
+const (
+	PlanFree      = "free"
+	PlanProSeller = "pro_seller"
+	PlanDealer    = "dealer"
+)
+
+// Plan is a seller plan with its posting quotas
+type Plan struct {
+	ID               string    `json:"id" gorm:"primaryKey"`
+	Name             string    `json:"name"`
+	DailyPosts       int       `json:"daily_posts"`        // New listings per day, reset at midnight
+	ActiveListings   int       `json:"active_listings"`    // Active listings at a time
+	ImagesPerListing int       `json:"images_per_listing"` // Images on one listing
+	FeaturedSlots    int       `json:"featured_slots"`     // Featured listings at a time
+	Price            float64   `json:"price"`              // Per PlanPeriod; 0 means the plan cannot be bought
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// TableName overrides the default table name
+func (Plan) TableName() string { return "subscription_plans" }
+
+// Quota returns the plan's quotas as enforced by the listing service
+func (p *Plan) Quota() *listing.ListingQuota {
+	return &listing.ListingQuota{
+		Plan:             p.ID,
+		DailyPosts:       p.DailyPosts,
+		ActiveListings:   p.ActiveListings,
+		ImagesPerListing: p.ImagesPerListing,
+		FeaturedSlots:    p.FeaturedSlots,
+	}
+}
+
+// Subscription puts a user on a plan. Users without one are on the free plan.
+type Subscription struct {
+	UserID     uuid.UUID  `json:"user_id" gorm:"type:uuid;primaryKey"`
+	PlanID     string     `json:"plan_id"`
+	StartedAt  time.Time  `json:"started_at"`
+	ExpiresAt  *time.Time `json:"expires_at"` // Nil means no end date
+	AssignedBy *uuid.UUID `json:"assigned_by,omitempty" gorm:"type:uuid"`
+	PaymentID  *uuid.UUID `json:"payment_id,omitempty" gorm:"type:uuid"` // Payment that last bought or extended it
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// TableName overrides the default table name
+func (Subscription) TableName() string { return "user_subscriptions" }
+
+// Usage is how much of their quotas a seller is using
+type Usage struct {
+	DailyPosts       int `json:"daily_posts"`       // Listings created today
+	ActiveListings   int `json:"active_listings"`   // Listings currently active
+	FeaturedListings int `json:"featured_listings"` // Active listings currently featured
+}
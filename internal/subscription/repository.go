@@ -0,0 +1,108 @@
+package subscription
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/yourusername/car-reselling-backend/internal/listing"
+	appErrors "github.com/yourusername/car-reselling-backend/pkg/errors"
+)
+
+// Repository handles database operations for plans and subscriptions
+type Repository struct {
+	db *gorm.DB
+}
+
+// NewRepository creates a new subscription repository
+func NewRepository(db *gorm.DB) *Repository {
+	return &Repository{db: db}
+}
+
+// --- Plans ---
+
+// ListPlans retrieves all plans, smallest first
+func (r *Repository) ListPlans(ctx context.Context) ([]Plan, error) {
+	var plans []Plan
+	err := r.db.WithContext(ctx).Order("daily_posts, id").Find(&plans).Error
+	return plans, err
+}
+
+// FindPlan retrieves a plan by ID
+func (r *Repository) FindPlan(ctx context.Context, planID string) (*Plan, error) {
+	var plan Plan
+	err := r.db.WithContext(ctx).Where("id = ?", planID).First(&plan).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, appErrors.ErrNotFound
+	}
+	return &plan, err
+}
+
+// UpdatePlan saves a plan's name, quotas and price
+func (r *Repository) UpdatePlan(ctx context.Context, plan *Plan) error {
+	plan.UpdatedAt = time.Now()
+	return r.db.WithContext(ctx).Model(&Plan{}).
+		Where("id = ?", plan.ID).
+		Updates(map[string]interface{}{
+			"name":               plan.Name,
+			"daily_posts":        plan.DailyPosts,
+			"active_listings":    plan.ActiveListings,
+			"images_per_listing": plan.ImagesPerListing,
+			"featured_slots":     plan.FeaturedSlots,
+			"price":              plan.Price,
+			"updated_at":         plan.UpdatedAt,
+		}).Error
+}
+
+// --- Subscriptions ---
+
+// FindActiveSubscription retrieves a user's subscription if it has not expired
+func (r *Repository) FindActiveSubscription(ctx context.Context, userID uuid.UUID) (*Subscription, error) {
+	var sub Subscription
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND (expires_at IS NULL OR expires_at > NOW())", userID).
+		First(&sub).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, appErrors.ErrNotFound
+	}
+	return &sub, err
+}
+
+// SaveSubscription creates or replaces a user's subscription
+func (r *Repository) SaveSubscription(ctx context.Context, sub *Subscription) error {
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"plan_id", "started_at", "expires_at", "assigned_by", "payment_id", "updated_at"}),
+		}).
+		Create(sub).Error
+}
+
+// UserExists reports whether an active, not deleted user exists
+func (r *Repository) UserExists(ctx context.Context, userID uuid.UUID) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Table("users").
+		Where("id = ? AND deleted_at IS NULL", userID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// --- Usage ---
+
+// GetUsage counts a seller's listings against their quotas. Daily posts
+// include listings deleted since, like the listing service's check.
+func (r *Repository) GetUsage(ctx context.Context, userID uuid.UUID) (*Usage, error) {
+	var usage Usage
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT COUNT(*) FILTER (WHERE created_at >= CURRENT_DATE) as daily_posts,
+			   COUNT(*) FILTER (WHERE status = ?) as active_listings,
+			   COUNT(*) FILTER (WHERE status = ? AND is_featured) as featured_listings
+		FROM cars
+		WHERE seller_id = ?
+	`, listing.CarStatusActive, listing.CarStatusActive, userID.String()).Scan(&usage).Error
+	return &usage, err
+}
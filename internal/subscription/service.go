@@ -0,0 +1,335 @@
+package subscription
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/yourusername/car-reselling-backend/internal/audit"
+	"github.com/yourusername/car-reselling-backend/internal/listing"
+	"github.com/yourusername/car-reselling-backend/internal/payments"
+	appErrors "github.com/yourusername/car-reselling-backend/pkg/errors"
+)
+
+var (
+	// ErrUnknownPlan is returned when assigning a plan that does not exist
+	ErrUnknownPlan = errors.New("unknown plan")
+	// ErrExpiryInPast is returned when assigning a plan that would already be over
+	ErrExpiryInPast = errors.New("expires_at must be in the future")
+	// ErrNotForSale is returned when buying the free plan or a plan without a price
+	ErrNotForSale = errors.New("plan cannot be purchased")
+	// ErrAlreadyOnPlan is returned when buying a plan the user already has with no end date
+	ErrAlreadyOnPlan = errors.New("you already have this plan with no end date")
+	// ErrPaymentsUnavailable is returned when buying a plan without a payment provider
+	ErrPaymentsUnavailable = errors.New("payments are not available")
+)
+
+// AuditLogger records plan changes
+type AuditLogger interface {
+	Record(ctx context.Context, event audit.Event)
+}
+
+// PaymentService takes payments for plans through a hosted checkout. The plan
+// starts when the payment's webhook arrives (see PaymentSucceeded).
+type PaymentService interface {
+	CreateCheckout(ctx context.Context, userID uuid.UUID, opts payments.CheckoutOptions) (*payments.CheckoutResponse, error)
+}
+
+// Service handles seller plans and their quotas
+type Service struct {
+	repo     *Repository
+	auditLog AuditLogger
+	payments PaymentService
+	currency string
+}
+
+// NewService creates a new subscription service
+func NewService(repo *Repository) *Service {
+	return &Service{repo: repo}
+}
+
+// SetAuditLogger sets the audit log for plan changes
+func (s *Service) SetAuditLogger(l AuditLogger) {
+	s.auditLog = l
+}
+
+// SetPaymentService sets how plans are paid for and in which currency their
+// prices are. Without one, buying a plan fails with ErrPaymentsUnavailable.
+func (s *Service) SetPaymentService(p PaymentService, currency string) {
+	s.payments = p
+	s.currency = currency
+}
+
+// QuotaFor returns the quotas of the user's current plan (listing.QuotaProvider)
+func (s *Service) QuotaFor(ctx context.Context, userID uuid.UUID) (*listing.ListingQuota, error) {
+	plan, _, err := s.currentPlan(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return plan.Quota(), nil
+}
+
+// GetMyPlan returns the user's plan with what they have used and have left
+func (s *Service) GetMyPlan(ctx context.Context, userID uuid.UUID) (*MyPlanResponse, error) {
+	plan, sub, err := s.currentPlan(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	usage, err := s.repo.GetUsage(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &MyPlanResponse{
+		Plan:  *plan,
+		Usage: *usage,
+		Remaining: Remaining{
+			DailyPosts:     remaining(plan.DailyPosts, usage.DailyPosts),
+			ActiveListings: remaining(plan.ActiveListings, usage.ActiveListings),
+			FeaturedSlots:  remaining(plan.FeaturedSlots, usage.FeaturedListings),
+		},
+	}
+	if sub != nil {
+		resp.ExpiresAt = sub.ExpiresAt
+	}
+	// Posting is also capped by the active listing quota
+	if resp.Remaining.ActiveListings < resp.Remaining.DailyPosts {
+		resp.Remaining.DailyPosts = resp.Remaining.ActiveListings
+	}
+	return resp, nil
+}
+
+// ListPlans returns all plans
+func (s *Service) ListPlans(ctx context.Context) ([]Plan, error) {
+	plans, err := s.repo.ListPlans(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if plans == nil {
+		plans = []Plan{}
+	}
+	return plans, nil
+}
+
+// UpdatePlan changes a plan's name or quotas (admin). Changes apply to every
+// user on the plan from their next action.
+func (s *Service) UpdatePlan(ctx context.Context, adminID uuid.UUID, planID string, req *UpdatePlanRequest) (*Plan, error) {
+	plan, err := s.repo.FindPlan(ctx, planID)
+	if err != nil {
+		return nil, err
+	}
+	before := *plan
+
+	if req.Name != nil {
+		plan.Name = *req.Name
+	}
+	if req.DailyPosts != nil {
+		plan.DailyPosts = *req.DailyPosts
+	}
+	if req.ActiveListings != nil {
+		plan.ActiveListings = *req.ActiveListings
+	}
+	if req.ImagesPerListing != nil {
+		plan.ImagesPerListing = *req.ImagesPerListing
+	}
+	if req.FeaturedSlots != nil {
+		plan.FeaturedSlots = *req.FeaturedSlots
+	}
+	if req.Price != nil {
+		plan.Price = *req.Price
+	}
+
+	if err := s.repo.UpdatePlan(ctx, plan); err != nil {
+		return nil, err
+	}
+
+	if changes := audit.Diff(before, *plan); changes != nil {
+		delete(changes, "updated_at")
+		s.audit(ctx, audit.Event{
+			ActorID:    audit.ActorID(adminID),
+			Action:     audit.ActionPlanUpdated,
+			TargetType: audit.TargetPlan,
+			TargetID:   plan.ID,
+			Changes:    changes,
+		})
+	}
+	return plan, nil
+}
+
+// AssignPlan puts a user on a plan (admin). Assigning the free plan ends any paid plan.
+func (s *Service) AssignPlan(ctx context.Context, adminID, userID uuid.UUID, req *AssignPlanRequest) (*Subscription, error) {
+	exists, err := s.repo.UserExists(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, appErrors.ErrNotFound
+	}
+	if _, err := s.repo.FindPlan(ctx, req.PlanID); err != nil {
+		if errors.Is(err, appErrors.ErrNotFound) {
+			return nil, ErrUnknownPlan
+		}
+		return nil, err
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, ErrExpiryInPast
+	}
+
+	previous := PlanFree
+	if current, err := s.repo.FindActiveSubscription(ctx, userID); err == nil {
+		previous = current.PlanID
+	}
+
+	now := time.Now()
+	sub := &Subscription{
+		UserID:     userID,
+		PlanID:     req.PlanID,
+		StartedAt:  now,
+		ExpiresAt:  req.ExpiresAt,
+		AssignedBy: &adminID,
+		UpdatedAt:  now,
+	}
+	if err := s.repo.SaveSubscription(ctx, sub); err != nil {
+		return nil, err
+	}
+
+	s.audit(ctx, audit.Event{
+		ActorID:    audit.ActorID(adminID),
+		Action:     audit.ActionUserPlanChanged,
+		TargetType: audit.TargetUser,
+		TargetID:   userID.String(),
+		Changes:    audit.Changes{"plan": {Before: previous, After: req.PlanID}},
+		Metadata:   audit.Metadata{"expires_at": req.ExpiresAt},
+	})
+	return sub, nil
+}
+
+// PurchasePlan starts a checkout for PlanPeriod of a paid plan. The plan
+// starts, or is extended if the user is already on it, once the payment succeeds.
+// A plan the user already has with no end date can't be extended, so it can't
+// be bought.
+func (s *Service) PurchasePlan(ctx context.Context, userID uuid.UUID, planID string) (*payments.CheckoutResponse, error) {
+	if s.payments == nil {
+		return nil, ErrPaymentsUnavailable
+	}
+	plan, err := s.repo.FindPlan(ctx, planID)
+	if err != nil {
+		return nil, err
+	}
+	if plan.ID == PlanFree || plan.Price <= 0 {
+		return nil, ErrNotForSale
+	}
+	current, err := s.repo.FindActiveSubscription(ctx, userID)
+	if err != nil && !errors.Is(err, appErrors.ErrNotFound) {
+		return nil, err
+	}
+	if current != nil && current.PlanID == plan.ID && current.ExpiresAt == nil {
+		return nil, ErrAlreadyOnPlan
+	}
+
+	checkout, err := s.payments.CreateCheckout(ctx, userID, payments.CheckoutOptions{
+		Purpose:     payments.PurposePlan,
+		ReferenceID: plan.ID,
+		Description: fmt.Sprintf("%s plan, %d days", plan.Name, int(PlanPeriod.Hours()/24)),
+		Amount:      plan.Price,
+		Currency:    s.currency,
+	})
+	if errors.Is(err, payments.ErrNotConfigured) {
+		return nil, ErrPaymentsUnavailable
+	}
+	return checkout, err
+}
+
+// PaymentSucceeded puts the buyer on the plan they paid for (payments.Fulfiller).
+// A purchase of the plan the user is already on extends it by PlanPeriod;
+// otherwise the new plan replaces the current one from now. A payment that
+// already bought the current subscription is not applied again.
+func (s *Service) PaymentSucceeded(ctx context.Context, payment *payments.Payment) error {
+	plan, err := s.repo.FindPlan(ctx, payment.ReferenceID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	previous := PlanFree
+	startedAt, from := now, now
+	current, err := s.repo.FindActiveSubscription(ctx, payment.UserID)
+	if err != nil && !errors.Is(err, appErrors.ErrNotFound) {
+		return err
+	}
+	if current != nil {
+		if current.PaymentID != nil && *current.PaymentID == payment.ID {
+			return nil
+		}
+		previous = current.PlanID
+		if current.PlanID == plan.ID {
+			if current.ExpiresAt == nil {
+				return fmt.Errorf("user %s already has the %s plan with no end date", payment.UserID, plan.ID)
+			}
+			startedAt, from = current.StartedAt, *current.ExpiresAt
+		}
+	}
+
+	expiresAt := from.Add(PlanPeriod)
+	sub := &Subscription{
+		UserID:    payment.UserID,
+		PlanID:    plan.ID,
+		StartedAt: startedAt,
+		ExpiresAt: &expiresAt,
+		PaymentID: &payment.ID,
+		UpdatedAt: now,
+	}
+	if err := s.repo.SaveSubscription(ctx, sub); err != nil {
+		return err
+	}
+
+	s.audit(ctx, audit.Event{
+		ActorID:    audit.ActorID(payment.UserID),
+		Action:     audit.ActionPlanPurchased,
+		TargetType: audit.TargetUser,
+		TargetID:   payment.UserID.String(),
+		Changes:    audit.Changes{"plan": {Before: previous, After: plan.ID}},
+		Metadata:   audit.Metadata{"expires_at": expiresAt, "payment_id": payment.ID},
+	})
+	return nil
+}
+
+// PaymentFailed is a no-op; nothing is held for a plan until it is paid (payments.Fulfiller)
+func (s *Service) PaymentFailed(ctx context.Context, payment *payments.Payment) error {
+	return nil
+}
+
+// currentPlan returns the user's plan and subscription, or the free plan and
+// nil when they have no active subscription
+func (s *Service) currentPlan(ctx context.Context, userID uuid.UUID) (*Plan, *Subscription, error) {
+	sub, err := s.repo.FindActiveSubscription(ctx, userID)
+	if err != nil && !errors.Is(err, appErrors.ErrNotFound) {
+		return nil, nil, err
+	}
+
+	planID := PlanFree
+	if sub != nil {
+		planID = sub.PlanID
+	}
+	plan, err := s.repo.FindPlan(ctx, planID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return plan, sub, nil
+}
+
+func (s *Service) audit(ctx context.Context, event audit.Event) {
+	if s.auditLog != nil {
+		s.auditLog.Record(ctx, event)
+	}
+}
+
+func remaining(limit, used int) int {
+	if used >= limit {
+		return 0
+	}
+	return limit - used
+}
@@ -0,0 +1,25 @@
+-- Migration: Full-text and fuzzy search over car listings
+-- UP Migration
+
+-- Trigram matching for typo-tolerant fallback
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- Weighted search document: title and make/model rank above color and description
+ALTER TABLE cars ADD COLUMN IF NOT EXISTS search_vector tsvector
+    GENERATED ALWAYS AS (
+        setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+        setweight(to_tsvector('english', coalesce(make, '') || ' ' || coalesce(model, '')), 'A') ||
+        setweight(to_tsvector('english', coalesce(color, '')), 'C') ||
+        setweight(to_tsvector('english', coalesce(description, '')), 'D')
+    ) STORED;
+
+-- Indexes for search
+CREATE INDEX IF NOT EXISTS idx_cars_search_vector ON cars USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS idx_cars_title_trgm ON cars USING GIN (title gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_cars_make_model_trgm ON cars USING GIN ((make || ' ' || model) gin_trgm_ops);
+
+-- DOWN Migration (for rollback)
+-- DROP INDEX IF EXISTS idx_cars_make_model_trgm;
+-- DROP INDEX IF EXISTS idx_cars_title_trgm;
+-- DROP INDEX IF EXISTS idx_cars_search_vector;
+-- ALTER TABLE cars DROP COLUMN IF EXISTS search_vector;
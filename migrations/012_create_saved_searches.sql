@@ -0,0 +1,49 @@
+-- Migration: Saved searches with new-listing alerts
+-- UP Migration
+
+-- Saved listing searches (empty string / 0 means "any")
+CREATE TABLE IF NOT EXISTS saved_searches (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    make VARCHAR(50) NOT NULL DEFAULT '',
+    model VARCHAR(50) NOT NULL DEFAULT '',
+    min_price DECIMAL(12, 2) NOT NULL DEFAULT 0,
+    max_price DECIMAL(12, 2) NOT NULL DEFAULT 0,
+    city VARCHAR(100) NOT NULL DEFAULT '',
+    condition VARCHAR(20) NOT NULL DEFAULT '',
+    frequency VARCHAR(20) NOT NULL DEFAULT 'instant', -- instant, daily
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    last_notified_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Listings matched by saved searches. One row per (user, car) so nobody is alerted twice for the same car
+CREATE TABLE IF NOT EXISTS saved_search_matches (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    saved_search_id UUID NOT NULL REFERENCES saved_searches(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    car_id UUID NOT NULL REFERENCES cars(id) ON DELETE CASCADE,
+    notified_at TIMESTAMP WITH TIME ZONE, -- NULL while waiting for the daily digest
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE (user_id, car_id)
+);
+
+-- Indexes for performance
+CREATE INDEX IF NOT EXISTS idx_saved_searches_user_id ON saved_searches(user_id);
+CREATE INDEX IF NOT EXISTS idx_saved_searches_active ON saved_searches(is_active, frequency);
+CREATE INDEX IF NOT EXISTS idx_saved_search_matches_pending ON saved_search_matches(saved_search_id) WHERE notified_at IS NULL;
+
+-- Trigger to update updated_at on saved_searches
+DROP TRIGGER IF EXISTS update_saved_searches_updated_at ON saved_searches;
+CREATE TRIGGER update_saved_searches_updated_at BEFORE UPDATE ON saved_searches
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- DOWN Migration (for rollback)
+-- DROP TRIGGER IF EXISTS update_saved_searches_updated_at ON saved_searches;
+-- DROP INDEX IF EXISTS idx_saved_search_matches_pending;
+-- DROP INDEX IF EXISTS idx_saved_searches_active;
+-- DROP INDEX IF EXISTS idx_saved_searches_user_id;
+-- DROP TABLE IF EXISTS saved_search_matches;
+-- DROP TABLE IF EXISTS saved_searches;
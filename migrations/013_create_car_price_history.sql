@@ -0,0 +1,23 @@
+-- Migration: Price history for car listings
+-- UP Migration
+
+-- Every price a listing has had. The first row per car has old_price NULL (the original asking price)
+CREATE TABLE IF NOT EXISTS car_price_history (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    car_id UUID NOT NULL REFERENCES cars(id) ON DELETE CASCADE,
+    old_price DECIMAL(12, 2),
+    new_price DECIMAL(12, 2) NOT NULL,
+    changed_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_car_price_history_car_id ON car_price_history(car_id, changed_at);
+
+-- Backfill: treat the current price of existing listings as their original price
+INSERT INTO car_price_history (car_id, old_price, new_price, changed_at)
+SELECT c.id, NULL, c.price, c.created_at
+FROM cars c
+WHERE NOT EXISTS (SELECT 1 FROM car_price_history h WHERE h.car_id = c.id);
+
+-- DOWN Migration (for rollback)
+-- DROP INDEX IF EXISTS idx_car_price_history_car_id;
+-- DROP TABLE IF EXISTS car_price_history;
@@ -0,0 +1,19 @@
+-- Migration: Listing expiry and renewal lifecycle
+-- UP Migration
+
+-- How many times the seller renewed the listing (capped in the application)
+ALTER TABLE cars ADD COLUMN IF NOT EXISTS renewal_count INT NOT NULL DEFAULT 0;
+
+-- Smallest "days before expiry" warning already sent for the current expiry date (NULL = none yet)
+ALTER TABLE cars ADD COLUMN IF NOT EXISTS expiry_warning_days SMALLINT;
+
+-- Listings without an expiry date get the default 90 day lifetime from creation
+UPDATE cars SET expires_at = created_at + INTERVAL '90 days' WHERE expires_at IS NULL;
+
+-- Lifecycle worker scans active listings by expiry date
+CREATE INDEX IF NOT EXISTS idx_cars_active_expires_at ON cars(expires_at) WHERE status = 'active';
+
+-- DOWN Migration (for rollback)
+-- DROP INDEX IF EXISTS idx_cars_active_expires_at;
+-- ALTER TABLE cars DROP COLUMN IF EXISTS expiry_warning_days;
+-- ALTER TABLE cars DROP COLUMN IF EXISTS renewal_count;
@@ -0,0 +1,27 @@
+-- Migration: Listing reports and moderation queue
+-- UP Migration
+
+-- User reports against listings. One report per user per listing so the flag
+-- threshold counts distinct reporters
+CREATE TABLE IF NOT EXISTS listing_reports (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    car_id UUID NOT NULL REFERENCES cars(id) ON DELETE CASCADE,
+    reporter_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    reason VARCHAR(30) NOT NULL, -- scam, wrong_price, already_sold, offensive_images
+    details TEXT NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL DEFAULT 'pending', -- pending, resolved
+    resolution VARCHAR(20), -- restore, remove, ban_seller
+    resolved_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    resolved_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE (car_id, reporter_id)
+);
+
+-- Indexes for performance
+CREATE INDEX IF NOT EXISTS idx_listing_reports_pending ON listing_reports(car_id) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_listing_reports_reporter_id ON listing_reports(reporter_id);
+
+-- DOWN Migration (for rollback)
+-- DROP INDEX IF EXISTS idx_listing_reports_reporter_id;
+-- DROP INDEX IF EXISTS idx_listing_reports_pending;
+-- DROP TABLE IF EXISTS listing_reports;
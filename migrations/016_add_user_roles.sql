@@ -0,0 +1,26 @@
+-- Migration: User roles for access control
+-- UP Migration
+
+-- user, dealer, moderator, admin
+ALTER TABLE users ADD COLUMN IF NOT EXISTS role VARCHAR(20) NOT NULL DEFAULT 'user';
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'users_role_check') THEN
+        ALTER TABLE users ADD CONSTRAINT users_role_check
+            CHECK (role IN ('user', 'dealer', 'moderator', 'admin'));
+    END IF;
+END $$;
+
+-- Existing dealers keep their dealer role
+UPDATE users SET role = 'dealer' WHERE is_dealer = TRUE AND role = 'user';
+
+CREATE INDEX IF NOT EXISTS idx_users_role ON users(role);
+
+-- Bootstrap the first admin manually:
+-- UPDATE users SET role = 'admin' WHERE email = 'admin@example.com';
+
+-- DOWN Migration (for rollback)
+-- DROP INDEX IF EXISTS idx_users_role;
+-- ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_check;
+-- ALTER TABLE users DROP COLUMN IF EXISTS role;
@@ -0,0 +1,17 @@
+-- Migration: Email verification and chat email digests
+-- UP Migration
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email_verified BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email_verified_at TIMESTAMP WITH TIME ZONE;
+
+-- When this participant was last emailed about unread messages in the conversation
+ALTER TABLE conversation_participants ADD COLUMN IF NOT EXISTS digest_emailed_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_conversation_participants_unread
+    ON conversation_participants(conversation_id) WHERE unread_count > 0;
+
+-- DOWN Migration (for rollback)
+-- DROP INDEX IF EXISTS idx_conversation_participants_unread;
+-- ALTER TABLE conversation_participants DROP COLUMN IF EXISTS digest_emailed_at;
+-- ALTER TABLE users DROP COLUMN IF EXISTS email_verified_at;
+-- ALTER TABLE users DROP COLUMN IF EXISTS email_verified;
@@ -0,0 +1,15 @@
+-- Migration: Account deletion with a grace period
+-- UP Migration
+
+-- Set when the user asks to delete their account; the account is purged once it passes
+ALTER TABLE users ADD COLUMN IF NOT EXISTS deletion_scheduled_at TIMESTAMP WITH TIME ZONE;
+-- Set when the account has been purged and anonymised
+ALTER TABLE users ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_users_deletion_scheduled_at
+    ON users(deletion_scheduled_at) WHERE deletion_scheduled_at IS NOT NULL;
+
+-- DOWN Migration (for rollback)
+-- DROP INDEX IF EXISTS idx_users_deletion_scheduled_at;
+-- ALTER TABLE users DROP COLUMN IF EXISTS deleted_at;
+-- ALTER TABLE users DROP COLUMN IF EXISTS deletion_scheduled_at;
@@ -0,0 +1,25 @@
+-- Migration: TOTP two-factor authentication with recovery codes
+-- UP Migration
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS two_factor_enabled BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS two_factor_enabled_at TIMESTAMP WITH TIME ZONE;
+-- TOTP secret, encrypted with TOTP_ENCRYPTION_KEY
+ALTER TABLE users ADD COLUMN IF NOT EXISTS totp_secret TEXT;
+
+-- Single-use recovery codes, stored as SHA-256 hashes
+CREATE TABLE IF NOT EXISTS user_recovery_codes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    code_hash VARCHAR(64) NOT NULL,
+    used_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_recovery_codes_user_hash
+    ON user_recovery_codes(user_id, code_hash);
+
+-- DOWN Migration (for rollback)
+-- DROP TABLE IF EXISTS user_recovery_codes;
+-- ALTER TABLE users DROP COLUMN IF EXISTS totp_secret;
+-- ALTER TABLE users DROP COLUMN IF EXISTS two_factor_enabled_at;
+-- ALTER TABLE users DROP COLUMN IF EXISTS two_factor_enabled;
@@ -0,0 +1,40 @@
+-- Migration: Dealer storefront profiles with admin verification
+-- UP Migration
+
+-- One storefront per dealer account
+CREATE TABLE IF NOT EXISTS dealer_profiles (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    business_name VARCHAR(150) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    logo_url TEXT,
+    address VARCHAR(255) NOT NULL DEFAULT '',
+    city VARCHAR(100) NOT NULL DEFAULT '',
+    state VARCHAR(100) NOT NULL DEFAULT '',
+    website VARCHAR(255) NOT NULL DEFAULT '',
+    opening_hours JSONB NOT NULL DEFAULT '{}', -- {"mon": "09:00-18:00", "sun": "closed"}
+    verification_status VARCHAR(20) NOT NULL DEFAULT 'unverified', -- unverified, pending, verified, rejected
+    verification_documents TEXT[] NOT NULL DEFAULT '{}', -- Keys of business registration documents in the private bucket
+    verification_note TEXT NOT NULL DEFAULT '', -- Reason given when verification is rejected
+    submitted_at TIMESTAMP WITH TIME ZONE,
+    reviewed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    reviewed_at TIMESTAMP WITH TIME ZONE,
+    verified_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'dealer_profiles_verification_status_check') THEN
+        ALTER TABLE dealer_profiles ADD CONSTRAINT dealer_profiles_verification_status_check
+            CHECK (verification_status IN ('unverified', 'pending', 'verified', 'rejected'));
+    END IF;
+END $$;
+
+-- Verification queue
+CREATE INDEX IF NOT EXISTS idx_dealer_profiles_pending
+    ON dealer_profiles(submitted_at) WHERE verification_status = 'pending';
+
+-- DOWN Migration (for rollback)
+-- DROP INDEX IF EXISTS idx_dealer_profiles_pending;
+-- DROP TABLE IF EXISTS dealer_profiles;
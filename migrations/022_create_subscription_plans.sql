@@ -0,0 +1,47 @@
+-- Migration: Seller subscription plans and posting quotas
+-- UP Migration
+
+-- Plans and their quotas. Admins can change quotas at runtime; the defaults
+-- below are only inserted once.
+CREATE TABLE IF NOT EXISTS subscription_plans (
+    id VARCHAR(30) PRIMARY KEY, -- free, pro_seller, dealer
+    name VARCHAR(100) NOT NULL,
+    daily_posts INT NOT NULL CHECK (daily_posts >= 0),
+    active_listings INT NOT NULL CHECK (active_listings >= 0),
+    images_per_listing INT NOT NULL CHECK (images_per_listing >= 1),
+    featured_slots INT NOT NULL CHECK (featured_slots >= 0),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+INSERT INTO subscription_plans (id, name, daily_posts, active_listings, images_per_listing, featured_slots) VALUES
+    ('free', 'Free', 5, 20, 10, 0),
+    ('pro_seller', 'Pro Seller', 20, 100, 20, 3),
+    ('dealer', 'Dealer', 100, 1000, 30, 20)
+ON CONFLICT (id) DO NOTHING;
+
+-- A user's plan. Users without a row (or with an expired one) are on the free plan.
+CREATE TABLE IF NOT EXISTS user_subscriptions (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    plan_id VARCHAR(30) NOT NULL REFERENCES subscription_plans(id),
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP WITH TIME ZONE, -- NULL means no end date
+    assigned_by UUID REFERENCES users(id) ON DELETE SET NULL, -- Admin who set the plan, NULL for purchases
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_subscriptions_plan_id ON user_subscriptions(plan_id);
+
+-- Existing dealers keep posting at dealer volume. Only runs while the table is
+-- empty so later plan changes aren't undone on restart.
+INSERT INTO user_subscriptions (user_id, plan_id)
+SELECT id, 'dealer' FROM users
+WHERE role = 'dealer' AND deleted_at IS NULL
+  AND NOT EXISTS (SELECT 1 FROM user_subscriptions)
+ON CONFLICT (user_id) DO NOTHING;
+
+-- DOWN Migration (for rollback)
+-- DROP INDEX IF EXISTS idx_user_subscriptions_plan_id;
+-- DROP TABLE IF EXISTS user_subscriptions;
+-- DROP TABLE IF EXISTS subscription_plans;
@@ -0,0 +1,36 @@
+-- Migration: Featured listings boosted for a time window
+-- UP Migration
+
+-- End of the current featured window; cars stay featured while it is in the future
+ALTER TABLE cars ADD COLUMN IF NOT EXISTS featured_until TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_cars_featured_until ON cars(featured_until) WHERE is_featured = TRUE;
+
+-- Paid boosts. A boost features its car from starts_at to ends_at; the boost
+-- worker turns is_featured on and off as windows start and end.
+CREATE TABLE IF NOT EXISTS listing_boosts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    car_id UUID NOT NULL REFERENCES cars(id) ON DELETE CASCADE,
+    seller_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    starts_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    ends_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    amount DECIMAL(12, 2) NOT NULL,
+    currency VARCHAR(3) NOT NULL,
+    payment_reference VARCHAR(255) NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL DEFAULT 'pending', -- pending, scheduled, active, ended, failed
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CHECK (ends_at > starts_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_listing_boosts_car_id ON listing_boosts(car_id);
+CREATE INDEX IF NOT EXISTS idx_listing_boosts_seller_id ON listing_boosts(seller_id);
+CREATE INDEX IF NOT EXISTS idx_listing_boosts_due ON listing_boosts(status, starts_at) WHERE status IN ('scheduled', 'active');
+
+-- DOWN Migration (for rollback)
+-- DROP INDEX IF EXISTS idx_listing_boosts_due;
+-- DROP INDEX IF EXISTS idx_listing_boosts_seller_id;
+-- DROP INDEX IF EXISTS idx_listing_boosts_car_id;
+-- DROP TABLE IF EXISTS listing_boosts;
+-- DROP INDEX IF EXISTS idx_cars_featured_until;
+-- ALTER TABLE cars DROP COLUMN IF EXISTS featured_until;
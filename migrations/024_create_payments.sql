@@ -0,0 +1,99 @@
+-- Migration: Payments, provider webhooks and the double-entry ledger
+-- UP Migration
+
+-- One checkout per purchase. Amounts are in the currency's major unit.
+CREATE TABLE IF NOT EXISTS payments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id),
+    purpose VARCHAR(30) NOT NULL,                     -- What is being bought, e.g. boost, plan
+    reference_id VARCHAR(64) NOT NULL DEFAULT '',     -- ID of the thing being bought
+    description VARCHAR(255) NOT NULL DEFAULT '',
+    amount DECIMAL(12, 2) NOT NULL CHECK (amount > 0),
+    refunded_amount DECIMAL(12, 2) NOT NULL DEFAULT 0 CHECK (refunded_amount >= 0 AND refunded_amount <= amount),
+    currency VARCHAR(3) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',     -- pending, succeeded, failed, refunded, review
+    provider VARCHAR(20) NOT NULL,
+    checkout_id VARCHAR(255),                         -- Provider checkout session
+    provider_payment_id VARCHAR(255),                 -- Provider charge / payment intent, set once paid
+    paid_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_payments_user_id ON payments(user_id, created_at DESC);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_payments_checkout_id ON payments(provider, checkout_id) WHERE checkout_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_payments_provider_payment_id ON payments(provider, provider_payment_id) WHERE provider_payment_id IS NOT NULL;
+
+-- Webhook events already handled. Providers retry and may deliver an event
+-- more than once; a row here means it was processed.
+CREATE TABLE IF NOT EXISTS payment_webhook_events (
+    provider VARCHAR(20) NOT NULL,
+    event_id VARCHAR(255) NOT NULL,
+    event_type VARCHAR(64) NOT NULL,
+    received_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (provider, event_id)
+);
+
+-- Double-entry ledger. Every charge and refund is one transaction of entries
+-- whose debits and credits balance; entries are never changed afterwards.
+CREATE TABLE IF NOT EXISTS ledger_entries (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    transaction_id UUID NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id),
+    payment_id UUID NOT NULL REFERENCES payments(id),
+    kind VARCHAR(20) NOT NULL,    -- charge, refund
+    account VARCHAR(30) NOT NULL, -- provider_balance, revenue
+    debit DECIMAL(12, 2) NOT NULL DEFAULT 0,
+    credit DECIMAL(12, 2) NOT NULL DEFAULT 0,
+    currency VARCHAR(3) NOT NULL,
+    description VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CHECK (debit >= 0 AND credit >= 0 AND (debit = 0) <> (credit = 0))
+);
+
+CREATE INDEX IF NOT EXISTS idx_ledger_entries_transaction_id ON ledger_entries(transaction_id);
+CREATE INDEX IF NOT EXISTS idx_ledger_entries_user_id ON ledger_entries(user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_ledger_entries_payment_id ON ledger_entries(payment_id);
+
+-- Reject updates and deletes; corrections are new transactions
+CREATE OR REPLACE FUNCTION ledger_entries_append_only() RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'ledger_entries is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_ledger_entries_append_only ON ledger_entries;
+CREATE TRIGGER trg_ledger_entries_append_only
+    BEFORE UPDATE OR DELETE ON ledger_entries
+    FOR EACH ROW EXECUTE FUNCTION ledger_entries_append_only();
+
+DROP TRIGGER IF EXISTS trg_ledger_entries_no_truncate ON ledger_entries;
+CREATE TRIGGER trg_ledger_entries_no_truncate
+    BEFORE TRUNCATE ON ledger_entries
+    FOR EACH STATEMENT EXECUTE FUNCTION ledger_entries_append_only();
+
+-- Check at commit that each transaction's debits equal its credits
+CREATE OR REPLACE FUNCTION ledger_entries_balanced() RETURNS TRIGGER AS $$
+BEGIN
+    IF (SELECT SUM(debit) - SUM(credit) FROM ledger_entries WHERE transaction_id = NEW.transaction_id) <> 0 THEN
+        RAISE EXCEPTION 'ledger transaction % does not balance', NEW.transaction_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_ledger_entries_balanced ON ledger_entries;
+CREATE CONSTRAINT TRIGGER trg_ledger_entries_balanced
+    AFTER INSERT ON ledger_entries
+    DEFERRABLE INITIALLY DEFERRED
+    FOR EACH ROW EXECUTE FUNCTION ledger_entries_balanced();
+
+-- DOWN Migration (for rollback)
+-- DROP TRIGGER IF EXISTS trg_ledger_entries_balanced ON ledger_entries;
+-- DROP FUNCTION IF EXISTS ledger_entries_balanced();
+-- DROP TRIGGER IF EXISTS trg_ledger_entries_no_truncate ON ledger_entries;
+-- DROP TRIGGER IF EXISTS trg_ledger_entries_append_only ON ledger_entries;
+-- DROP FUNCTION IF EXISTS ledger_entries_append_only();
+-- DROP TABLE IF EXISTS ledger_entries;
+-- DROP TABLE IF EXISTS payment_webhook_events;
+-- DROP TABLE IF EXISTS payments;
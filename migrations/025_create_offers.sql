@@ -0,0 +1,55 @@
+-- Migration: Structured price offers on listings
+-- UP Migration
+
+-- Listings with an accepted offer are reserved until the seller marks them sold
+-- or re-activates them
+ALTER TYPE car_status ADD VALUE IF NOT EXISTS 'reserved';
+
+-- Structured data of offer and system chat messages
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS metadata JSONB NOT NULL DEFAULT '{}';
+
+-- A buyer's offer on a listing. Pending offers wait for the seller, countered
+-- offers wait for the buyer; both are closed by expires_at.
+CREATE TABLE IF NOT EXISTS offers (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    car_id UUID NOT NULL REFERENCES cars(id) ON DELETE CASCADE,
+    buyer_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    seller_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    amount DECIMAL(12, 2) NOT NULL CHECK (amount > 0),
+    counter_amount DECIMAL(12, 2) CHECK (counter_amount > 0),
+    message TEXT NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL DEFAULT 'pending', -- pending, countered, accepted, rejected, expired, withdrawn
+    decline_reason VARCHAR(50) NOT NULL DEFAULT '', -- Set when rejected by the system, e.g. another_offer_accepted
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    responded_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'offers_status_check') THEN
+        ALTER TABLE offers ADD CONSTRAINT offers_status_check
+            CHECK (status IN ('pending', 'countered', 'accepted', 'rejected', 'expired', 'withdrawn'));
+    END IF;
+END $$;
+
+-- One open offer per buyer and listing
+CREATE UNIQUE INDEX IF NOT EXISTS idx_offers_open_buyer
+    ON offers(car_id, buyer_id) WHERE status IN ('pending', 'countered');
+
+CREATE INDEX IF NOT EXISTS idx_offers_car_id ON offers(car_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_offers_buyer_id ON offers(buyer_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_offers_seller_id ON offers(seller_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_offers_due ON offers(expires_at) WHERE status IN ('pending', 'countered');
+
+-- DOWN Migration (for rollback)
+-- DROP INDEX IF EXISTS idx_offers_due;
+-- DROP INDEX IF EXISTS idx_offers_seller_id;
+-- DROP INDEX IF EXISTS idx_offers_buyer_id;
+-- DROP INDEX IF EXISTS idx_offers_car_id;
+-- DROP INDEX IF EXISTS idx_offers_open_buyer;
+-- DROP TABLE IF EXISTS offers;
+-- ALTER TABLE messages DROP COLUMN IF EXISTS metadata;
+-- Enum values cannot be dropped; move reserved listings back first:
+-- UPDATE cars SET status = 'active' WHERE status = 'reserved';
@@ -0,0 +1,66 @@
+-- Migration: Viewing and test-drive appointments
+-- UP Migration
+
+-- Times a seller is available for viewings, for all their listings or one
+CREATE TABLE IF NOT EXISTS seller_availability (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    seller_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    car_id UUID REFERENCES cars(id) ON DELETE CASCADE, -- NULL for all of the seller's listings
+    starts_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    ends_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CHECK (ends_at > starts_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_seller_availability_seller ON seller_availability(seller_id, ends_at);
+
+-- A viewing or test drive. Requested appointments wait for the side that did
+-- not propose the time; rescheduling makes it requested again.
+CREATE TABLE IF NOT EXISTS appointments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    car_id UUID NOT NULL REFERENCES cars(id) ON DELETE CASCADE,
+    buyer_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    seller_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type VARCHAR(20) NOT NULL DEFAULT 'viewing', -- viewing, test_drive
+    starts_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    ends_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'requested', -- requested, confirmed, declined, cancelled, expired
+    proposed_by UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    note TEXT NOT NULL DEFAULT '',
+    decline_reason TEXT NOT NULL DEFAULT '',
+    sequence INTEGER NOT NULL DEFAULT 0, -- iCalendar SEQUENCE, bumped on every reschedule
+    reminder_sent_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CHECK (ends_at > starts_at)
+);
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'appointments_status_check') THEN
+        ALTER TABLE appointments ADD CONSTRAINT appointments_status_check
+            CHECK (status IN ('requested', 'confirmed', 'declined', 'cancelled', 'expired'));
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'appointments_type_check') THEN
+        ALTER TABLE appointments ADD CONSTRAINT appointments_type_check
+            CHECK (type IN ('viewing', 'test_drive'));
+    END IF;
+END $$;
+
+-- Conflict checks look up a user's open appointments on either side
+CREATE INDEX IF NOT EXISTS idx_appointments_seller_open
+    ON appointments(seller_id, starts_at) WHERE status IN ('requested', 'confirmed');
+CREATE INDEX IF NOT EXISTS idx_appointments_buyer_open
+    ON appointments(buyer_id, starts_at) WHERE status IN ('requested', 'confirmed');
+CREATE INDEX IF NOT EXISTS idx_appointments_car_id ON appointments(car_id);
+CREATE INDEX IF NOT EXISTS idx_appointments_reminder_due
+    ON appointments(starts_at) WHERE status = 'confirmed' AND reminder_sent_at IS NULL;
+
+-- DOWN Migration (for rollback)
+-- DROP INDEX IF EXISTS idx_appointments_reminder_due;
+-- DROP INDEX IF EXISTS idx_appointments_car_id;
+-- DROP INDEX IF EXISTS idx_appointments_buyer_open;
+-- DROP INDEX IF EXISTS idx_appointments_seller_open;
+-- DROP TABLE IF EXISTS appointments;
+-- DROP INDEX IF EXISTS idx_seller_availability_seller;
+-- DROP TABLE IF EXISTS seller_availability;
@@ -0,0 +1,20 @@
+-- Migration: Prices for buying seller plans
+-- UP Migration
+
+-- Price of PlanPeriod (30 days) of a plan in the payment currency; 0 means the
+-- plan cannot be bought. Default prices are only set when the column is added,
+-- so admin changes aren't undone on restart.
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_name = 'subscription_plans' AND column_name = 'price'
+    ) THEN
+        ALTER TABLE subscription_plans ADD COLUMN price DECIMAL(12, 2) NOT NULL DEFAULT 0 CHECK (price >= 0);
+        UPDATE subscription_plans SET price = 29.00 WHERE id = 'pro_seller';
+        UPDATE subscription_plans SET price = 199.00 WHERE id = 'dealer';
+    END IF;
+END $$;
+
+-- DOWN Migration (for rollback)
+-- ALTER TABLE subscription_plans DROP COLUMN IF EXISTS price;
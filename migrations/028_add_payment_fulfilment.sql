@@ -0,0 +1,28 @@
+-- Migration: Track delivery of paid purchases
+-- UP Migration
+
+-- Set once what a payment bought has been delivered. Paid payments without it
+-- are retried by the fulfilment worker. Payments made before the column
+-- existed were delivered when their webhook arrived.
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_name = 'payments' AND column_name = 'fulfilled_at'
+    ) THEN
+        ALTER TABLE payments ADD COLUMN fulfilled_at TIMESTAMP WITH TIME ZONE;
+        UPDATE payments SET fulfilled_at = paid_at WHERE paid_at IS NOT NULL;
+    END IF;
+END $$;
+
+CREATE INDEX IF NOT EXISTS idx_payments_unfulfilled ON payments(paid_at)
+    WHERE status = 'succeeded' AND fulfilled_at IS NULL;
+
+-- Payment that last bought or extended a subscription, so a retried
+-- fulfilment is not applied twice
+ALTER TABLE user_subscriptions ADD COLUMN IF NOT EXISTS payment_id UUID REFERENCES payments(id);
+
+-- DOWN Migration (for rollback)
+-- ALTER TABLE user_subscriptions DROP COLUMN IF EXISTS payment_id;
+-- DROP INDEX IF EXISTS idx_payments_unfulfilled;
+-- ALTER TABLE payments DROP COLUMN IF EXISTS fulfilled_at;
@@ -0,0 +1,23 @@
+-- Migration: Cancelled offers
+-- UP Migration
+
+-- Either side can call off an accepted offer; a listing it reserved goes back
+-- on sale
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM pg_constraint
+        WHERE conname = 'offers_status_check'
+          AND pg_get_constraintdef(oid) LIKE '%cancelled%'
+    ) THEN
+        ALTER TABLE offers DROP CONSTRAINT IF EXISTS offers_status_check;
+        ALTER TABLE offers ADD CONSTRAINT offers_status_check
+            CHECK (status IN ('pending', 'countered', 'accepted', 'rejected', 'expired', 'withdrawn', 'cancelled'));
+    END IF;
+END $$;
+
+-- DOWN Migration (for rollback)
+-- UPDATE offers SET status = 'withdrawn' WHERE status = 'cancelled';
+-- ALTER TABLE offers DROP CONSTRAINT IF EXISTS offers_status_check;
+-- ALTER TABLE offers ADD CONSTRAINT offers_status_check
+--     CHECK (status IN ('pending', 'countered', 'accepted', 'rejected', 'expired', 'withdrawn'));
@@ -0,0 +1,15 @@
+-- Migration: One open report per user and listing
+-- UP Migration
+
+-- Reports stay after they are resolved, so a table-wide unique constraint kept
+-- users from reporting a restored listing again. Only pending reports need to
+-- be unique for the flag threshold to count distinct reporters.
+ALTER TABLE listing_reports DROP CONSTRAINT IF EXISTS listing_reports_car_id_reporter_id_key;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_listing_reports_pending_reporter
+    ON listing_reports(car_id, reporter_id) WHERE status = 'pending';
+
+-- DOWN Migration (for rollback)
+-- DROP INDEX IF EXISTS idx_listing_reports_pending_reporter;
+-- Only possible while each user has at most one report per listing:
+-- ALTER TABLE listing_reports ADD CONSTRAINT listing_reports_car_id_reporter_id_key UNIQUE (car_id, reporter_id);
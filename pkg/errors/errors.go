@@ -16,14 +16,25 @@ var (
 	ErrOTPExpired               = errors.New("OTP expired")
 	ErrOTPInvalid               = errors.New("invalid OTP")
 	ErrTooManyAttempts          = errors.New("too many attempts")
+	ErrOTPDeliveryFailed        = errors.New("could not send the verification code, please try again later")
+	ErrEmailDeliveryFailed      = errors.New("could not send the email, please try again later")
+	ErrEmailAlreadyVerified     = errors.New("email is already verified")
+	ErrAccountLocked            = errors.New("too many failed login attempts, please try again later")
+	ErrInvalidTwoFactorCode     = errors.New("invalid two-factor authentication code")
+	ErrTwoFactorAlreadyEnabled  = errors.New("two-factor authentication is already enabled")
+	ErrTwoFactorNotEnabled      = errors.New("two-factor authentication is not enabled")
+	ErrTwoFactorSetupExpired    = errors.New("two-factor setup expired, please start again")
 	ErrNotFound                 = errors.New("resource not found")
 	ErrUnauthorized             = errors.New("unauthorized")
 	ErrForbidden                = errors.New("forbidden")
 	ErrInvalidEmail             = errors.New("invalid email format")
 	ErrInvalidPhone             = errors.New("invalid phone number format - must start with + and country code")
+	ErrInvalidEmailOrPhone      = errors.New("must be a valid email or phone number")
 	ErrCurrentPasswordIncorrect = errors.New("current password is incorrect")
 	ErrPasswordSameAsCurrent    = errors.New("new password must be different from current password")
 	ErrWeakPassword             = errors.New("password must be at least 8 characters with uppercase, lowercase, number, and special character")
+	ErrInvalidRole              = errors.New("invalid role - must be user, dealer, moderator or admin")
+	ErrCannotModifySelf         = errors.New("you cannot change your own role or status")
 )
 
 // ErrorResponse represents an error response
@@ -49,16 +60,24 @@ func HandleError(c *gin.Context, err error) {
 		statusCode = http.StatusUnauthorized
 	case ErrForbidden:
 		statusCode = http.StatusForbidden
-	case ErrOTPExpired, ErrOTPInvalid:
+	case ErrOTPExpired, ErrOTPInvalid, ErrTwoFactorSetupExpired:
 		statusCode = http.StatusBadRequest
-	case ErrTooManyAttempts:
+	case ErrTooManyAttempts, ErrAccountLocked:
 		statusCode = http.StatusTooManyRequests
+	case ErrOTPDeliveryFailed, ErrEmailDeliveryFailed:
+		statusCode = http.StatusBadGateway
+	case ErrEmailAlreadyVerified, ErrTwoFactorAlreadyEnabled, ErrTwoFactorNotEnabled:
+		statusCode = http.StatusConflict
+	case ErrInvalidTwoFactorCode:
+		statusCode = http.StatusUnauthorized
 	case ErrNotFound:
 		statusCode = http.StatusNotFound
-	case ErrInvalidEmail, ErrInvalidPhone:
+	case ErrInvalidEmail, ErrInvalidPhone, ErrInvalidEmailOrPhone:
 		statusCode = http.StatusBadRequest
 	case ErrCurrentPasswordIncorrect, ErrPasswordSameAsCurrent, ErrWeakPassword:
 		statusCode = http.StatusBadRequest
+	case ErrInvalidRole, ErrCannotModifySelf:
+		statusCode = http.StatusBadRequest
 	}
 
 	c.JSON(statusCode, ErrorResponse{
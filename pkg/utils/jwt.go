@@ -12,22 +12,46 @@ var (
 	ErrExpiredToken = errors.New("token has expired")
 )
 
-// JWTClaims represents the JWT claims structure
+// Token lifetimes
+const (
+	AccessTokenTTL  = 15 * time.Minute
+	RefreshTokenTTL = 30 * 24 * time.Hour
+
+	EmailVerificationTTL = 48 * time.Hour
+)
+
+// Token audiences keep each kind of token from being accepted as another,
+// even if two of them were signed with the same secret
+const (
+	accessAudience            = "access"
+	refreshAudience           = "refresh"
+	emailVerificationAudience = "email_verification"
+)
+
+// JWTClaims represents the JWT claims structure.
+// RegisteredClaims.ID is the token's unique jti.
 type JWTClaims struct {
-	UserID string `json:"user_id"`
-	Email  string `json:"email"`
+	UserID    string `json:"user_id"`
+	Email     string `json:"email"`
+	Role      string `json:"role,omitempty"`
+	SessionID string `json:"sid,omitempty"`
 	jwt.RegisteredClaims
 }
 
-// GenerateAccessToken generates a JWT access token with 5 years expiry
-func GenerateAccessToken(userID, email, secret string) (string, error) {
+// GenerateAccessToken generates a short-lived JWT access token for a session
+func GenerateAccessToken(userID, email, role, sessionID, tokenID, secret string) (string, error) {
+	now := time.Now()
 	claims := JWTClaims{
-		UserID: userID,
-		Email:  email,
+		UserID:    userID,
+		Email:     email,
+		Role:      role,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(5 * 365 * 24 * time.Hour)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
+			ID:        tokenID,
+			Audience:  jwt.ClaimStrings{accessAudience},
+			ExpiresAt: jwt.NewNumericDate(now.Add(AccessTokenTTL)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
 		},
 	}
 
@@ -35,14 +59,45 @@ func GenerateAccessToken(userID, email, secret string) (string, error) {
 	return token.SignedString([]byte(secret))
 }
 
-// GenerateRefreshToken generates a JWT refresh token with 5 years expiry
-func GenerateRefreshToken(userID, secret string) (string, error) {
+// GenerateRefreshToken generates a JWT refresh token for a session. Each
+// refresh token is single-use: its tokenID is rotated on every refresh.
+func GenerateRefreshToken(userID, sessionID, tokenID, secret string) (string, error) {
+	now := time.Now()
 	claims := JWTClaims{
+		UserID:    userID,
+		SessionID: sessionID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        tokenID,
+			Audience:  jwt.ClaimStrings{refreshAudience},
+			ExpiresAt: jwt.NewNumericDate(now.Add(RefreshTokenTTL)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(secret))
+}
+
+// EmailVerificationClaims are the claims of an email verification link token.
+// The email is included so a link stops working if the address changes.
+type EmailVerificationClaims struct {
+	UserID string `json:"user_id"`
+	Email  string `json:"email"`
+	jwt.RegisteredClaims
+}
+
+// GenerateEmailVerificationToken generates a signed token for an email verification link
+func GenerateEmailVerificationToken(userID, email, secret string) (string, error) {
+	now := time.Now()
+	claims := EmailVerificationClaims{
 		UserID: userID,
+		Email:  email,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(5 * 365 * 24 * time.Hour)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
+			Audience:  jwt.ClaimStrings{emailVerificationAudience},
+			ExpiresAt: jwt.NewNumericDate(now.Add(EmailVerificationTTL)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
 		},
 	}
 
@@ -50,14 +105,55 @@ func GenerateRefreshToken(userID, secret string) (string, error) {
 	return token.SignedString([]byte(secret))
 }
 
-// ValidateToken validates a JWT token and returns the claims
+// ValidateEmailVerificationToken validates an email verification token and returns the claims
+func ValidateEmailVerificationToken(tokenString, secret string) (*EmailVerificationClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &EmailVerificationClaims{}, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, ErrInvalidToken
+		}
+		return []byte(secret), nil
+	}, jwt.WithAudience(emailVerificationAudience))
+
+	if err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return nil, ErrExpiredToken
+		}
+		return nil, ErrInvalidToken
+	}
+
+	if claims, ok := token.Claims.(*EmailVerificationClaims); ok && token.Valid && claims.UserID != "" {
+		return claims, nil
+	}
+
+	return nil, ErrInvalidToken
+}
+
+// ValidateToken validates an access token and returns the claims
 func ValidateToken(tokenString, secret string) (*JWTClaims, error) {
+	return parseToken(tokenString, secret, jwt.WithAudience(accessAudience))
+}
+
+// ValidateRefreshToken validates a refresh token and returns the claims.
+// Refresh tokens issued before audiences were added have none and are accepted.
+func ValidateRefreshToken(tokenString, secret string) (*JWTClaims, error) {
+	claims, err := parseToken(tokenString, secret)
+	if err != nil {
+		return nil, err
+	}
+	if len(claims.Audience) > 0 && (len(claims.Audience) != 1 || claims.Audience[0] != refreshAudience) {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+// parseToken verifies a session token's signature and registered claims
+func parseToken(tokenString, secret string, opts ...jwt.ParserOption) (*JWTClaims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, ErrInvalidToken
 		}
 		return []byte(secret), nil
-	})
+	}, opts...)
 
 	if err != nil {
 		if errors.Is(err, jwt.ErrTokenExpired) {
@@ -4,9 +4,6 @@ import (
 	"crypto/rand"
 	"fmt"
 	"math/big"
-
-	"github.com/twilio/twilio-go"
-	twilioApi "github.com/twilio/twilio-go/rest/api/v2010"
 )
 
 // GenerateOTP generates a random 6-digit OTP code
@@ -18,24 +15,3 @@ func GenerateOTP() string {
 	}
 	return code
 }
-
-// SendOTP sends an OTP code via Twilio SMS
-func SendOTP(phone, code, accountSID, authToken, twilioPhoneNumber string) error {
-	client := twilio.NewRestClientWithParams(twilio.ClientParams{
-		Username: accountSID,
-		Password: authToken,
-	})
-
-	params := &twilioApi.CreateMessageParams{}
-	params.SetTo(phone)
-	params.SetFrom(twilioPhoneNumber)
-	params.SetBody(fmt.Sprintf("Your verification code is: %s. Valid for 10 minutes.", code))
-
-	_, err := client.Api.CreateMessage(params)
-	if err != nil {
-		return fmt.Errorf("failed to send OTP: %w", err)
-	}
-
-	return nil
-}
-